@@ -1,7 +1,14 @@
+// flora-apiserver is the API server of Flora.
 package main
 
-import "fmt"
+import (
+	"os"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver"
+)
 
 func main() {
-	fmt.Println("flora")
+	if err := apiserver.NewAPIServerCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
 }
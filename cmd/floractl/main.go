@@ -5,11 +5,12 @@ import (
 	"os"
 
 	"github.com/hanzhuoxian/flora/internal/floractl/cmd"
+	"github.com/hanzhuoxian/flora/internal/floractl/util/cmdutil"
 )
 
 func main() {
 	command := cmd.NewDefaultCommand()
 	if err := command.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cmdutil.ExitCode(err))
 	}
 }
@@ -0,0 +1,64 @@
+// Package apiserver implements flora-apiserver, the server that stores and
+// serves the flora resources.
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/options"
+	cliflag "github.com/hanzhuoxian/flora/pkg/cli/flag"
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/version"
+)
+
+// NewAPIServerCommand creates the flora-apiserver command.
+func NewAPIServerCommand() *cobra.Command {
+	opts := options.NewOptions()
+
+	cmd := &cobra.Command{
+		Use:          "flora-apiserver",
+		Short:        "The flora API server stores and serves the flora resources",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if errs := opts.Validate(); len(errs) != 0 {
+				return errors.Join(errs...)
+			}
+
+			log.Init(opts.Log)
+			defer log.Flush()
+
+			// Send the records of the libraries using log/slog to our outputs.
+			slog.SetDefault(slog.New(log.NewSlogHandler(log.WithName("slog"))))
+
+			log.Infof("Starting flora-apiserver, version: %s", version.Get().GitVersion)
+			cliflag.PrintFlags(cmd.Flags())
+
+			return run(opts)
+		},
+		Args: cobra.NoArgs,
+	}
+
+	fs := cmd.Flags()
+	fs.SetNormalizeFunc(cliflag.WordSepNormalizeFunc)
+	opts.AddFlags(fs)
+
+	return cmd
+}
+
+func run(opts *options.Options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server, err := createAPIServer(opts)
+	if err != nil {
+		return err
+	}
+
+	return server.Run(ctx)
+}
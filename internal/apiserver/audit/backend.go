@@ -0,0 +1,36 @@
+package audit
+
+// Backend writes audit events.
+type Backend interface {
+	// ProcessEvents writes events. It must not block the request for long,
+	// slow backends buffer the events.
+	ProcessEvents(events ...*Event)
+
+	// Shutdown writes the pending events and releases the backend.
+	Shutdown()
+}
+
+type union struct {
+	backends []Backend
+}
+
+// Union returns a Backend that sends every event to all backends.
+func Union(backends ...Backend) Backend {
+	if len(backends) == 1 {
+		return backends[0]
+	}
+
+	return &union{backends: backends}
+}
+
+func (u *union) ProcessEvents(events ...*Event) {
+	for _, b := range u.backends {
+		b.ProcessEvents(events...)
+	}
+}
+
+func (u *union) Shutdown() {
+	for _, b := range u.backends {
+		b.Shutdown()
+	}
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// BatchConfig configures the batching of events sent to a slow backend.
+type BatchConfig struct {
+	// BufferSize is the number of events buffered before they are sent.
+	// Events are dropped when the buffer is full.
+	BufferSize int
+	// MaxBatchSize is the maximum number of events sent at once.
+	MaxBatchSize int
+	// MaxBatchWait is the maximum time an event waits for its batch to fill.
+	MaxBatchWait time.Duration
+}
+
+// NewDefaultBatchConfig returns the default BatchConfig.
+func NewDefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		BufferSize:   10000,
+		MaxBatchSize: 400,
+		MaxBatchWait: 30 * time.Second,
+	}
+}
+
+type bufferedBackend struct {
+	delegate Backend
+	config   BatchConfig
+
+	// lock guards closing buffer against concurrent sends.
+	lock     sync.RWMutex
+	closed   bool
+	buffer   chan *Event
+	finished chan struct{}
+}
+
+// NewBufferedBackend returns a Backend that collects events in a buffer and
+// sends them to delegate in batches from a background goroutine.
+func NewBufferedBackend(delegate Backend, config BatchConfig) Backend {
+	b := &bufferedBackend{
+		delegate: delegate,
+		config:   config,
+		buffer:   make(chan *Event, config.BufferSize),
+		finished: make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+func (b *bufferedBackend) ProcessEvents(events ...*Event) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, e := range events {
+		select {
+		case b.buffer <- e:
+		default:
+			log.Warnf("Audit buffer is full, dropping event %s", e.AuditID)
+		}
+	}
+}
+
+// Shutdown sends the buffered events and waits for the delegate to process them.
+func (b *bufferedBackend) Shutdown() {
+	b.lock.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.buffer)
+	}
+	b.lock.Unlock()
+
+	<-b.finished
+	b.delegate.Shutdown()
+}
+
+func (b *bufferedBackend) run() {
+	defer close(b.finished)
+
+	for {
+		batch, open := b.collectBatch()
+		if len(batch) != 0 {
+			b.delegate.ProcessEvents(batch...)
+		}
+
+		if !open {
+			return
+		}
+	}
+}
+
+// collectBatch waits for the first event, then collects events until the
+// batch is full or MaxBatchWait has passed. It returns false once the buffer
+// is closed and drained.
+func (b *bufferedBackend) collectBatch() ([]*Event, bool) {
+	e, ok := <-b.buffer
+	if !ok {
+		return nil, false
+	}
+
+	batch := []*Event{e}
+
+	timer := time.NewTimer(b.config.MaxBatchWait)
+	defer timer.Stop()
+
+	for len(batch) < b.config.MaxBatchSize {
+		select {
+		case e, ok := <-b.buffer:
+			if !ok {
+				return batch, false
+			}
+
+			batch = append(batch, e)
+		case <-timer.C:
+			return batch, true
+		}
+	}
+
+	return batch, true
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+type logBackend struct {
+	logger log.Logger
+	close  func()
+}
+
+// NewLogBackend returns a Backend writing one entry per event to logger.
+func NewLogBackend(logger log.Logger) Backend {
+	return &logBackend{logger: logger, close: func() {}}
+}
+
+// NewFileBackend returns a Backend writing the events as JSON lines to path
+// through a dedicated logger. "-" means standard output.
+func NewFileBackend(path string) (Backend, error) {
+	if path == "-" {
+		path = "stdout"
+	}
+
+	opts := log.NewOptions()
+	opts.Format = "json"
+	opts.OutputPaths = []string{path}
+
+	logger, closeOutputs, err := log.New(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logBackend{logger: logger, close: closeOutputs}, nil
+}
+
+func (b *logBackend) ProcessEvents(events ...*Event) {
+	for _, e := range events {
+		b.logger.Info("Audit event", "event", e)
+	}
+}
+
+func (b *logBackend) Shutdown() {
+	b.logger.Flush()
+	b.close()
+}
@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+)
+
+// Policy defines the audit level of the requests. The first rule matching a
+// request decides its level; requests matching no rule are not audited.
+//
+// A policy file looks like:
+//
+//	rules:
+//	  # Don't record the frequent lease renewals.
+//	  - level: None
+//	    resources: ["leases"]
+//	    verbs: ["get", "update"]
+//	  - level: RequestResponse
+//	    verbs: ["create", "update", "delete"]
+//	  - level: Metadata
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule maps requests to an audit level. Empty lists match everything.
+type PolicyRule struct {
+	Level Level `yaml:"level"`
+
+	// Users is the list of user names the rule applies to.
+	Users []string `yaml:"users"`
+	// UserGroups is the list of groups the rule applies to. A user matches if
+	// it is a member of any of them.
+	UserGroups []string `yaml:"userGroups"`
+	// Verbs is the list of verbs the rule applies to, e.g. get, list, create.
+	Verbs []string `yaml:"verbs"`
+	// Resources is the list of resources the rule applies to.
+	Resources []string `yaml:"resources"`
+	// NonResourceURLs is the list of non-resource paths the rule applies to.
+	// A trailing * matches any suffix, e.g. /debug/*. Rules setting Resources
+	// or NonResourceURLs only apply to that kind of request.
+	NonResourceURLs []string `yaml:"nonResourceURLs"`
+}
+
+// DefaultPolicy records the metadata of every request.
+func DefaultPolicy() *Policy {
+	return &Policy{Rules: []PolicyRule{{Level: LevelMetadata}}}
+}
+
+// LoadPolicy reads and validates the policy file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read audit policy: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	policy := &Policy{}
+	if err := dec.Decode(policy); err != nil {
+		return nil, fmt.Errorf("decode audit policy %s: %w", path, err)
+	}
+
+	for i, rule := range policy.Rules {
+		if err := rule.Level.Validate(); err != nil {
+			return nil, fmt.Errorf("audit policy %s: rules[%d]: %w", path, i, err)
+		}
+
+		if len(rule.Resources) != 0 && len(rule.NonResourceURLs) != 0 {
+			return nil, fmt.Errorf("audit policy %s: rules[%d]: resources and nonResourceURLs are mutually exclusive",
+				path, i)
+		}
+	}
+
+	return policy, nil
+}
+
+// LevelFor returns the audit level of a request made by user.
+func (p *Policy) LevelFor(user *request.UserInfo, info *request.RequestInfo) Level {
+	for i := range p.Rules {
+		if p.Rules[i].matches(user, info) {
+			return p.Rules[i].Level
+		}
+	}
+
+	return LevelNone
+}
+
+func (r *PolicyRule) matches(user *request.UserInfo, info *request.RequestInfo) bool {
+	if len(r.Users) != 0 && !contains(r.Users, user.Name) {
+		return false
+	}
+
+	if len(r.UserGroups) != 0 && !containsAny(r.UserGroups, user.Groups) {
+		return false
+	}
+
+	if len(r.Verbs) != 0 && !contains(r.Verbs, info.Verb) {
+		return false
+	}
+
+	if info.IsResourceRequest {
+		if len(r.NonResourceURLs) != 0 {
+			return false
+		}
+
+		return len(r.Resources) == 0 || contains(r.Resources, info.Resource)
+	}
+
+	if len(r.Resources) != 0 {
+		return false
+	}
+
+	return len(r.NonResourceURLs) == 0 || matchesPath(r.NonResourceURLs, info.Path)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		if contains(list, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if pattern == path {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+)
+
+const testPolicy = `
+rules:
+  - level: None
+    resources: ["leases"]
+    verbs: ["get", "update"]
+  - level: None
+    nonResourceURLs: ["/healthz*"]
+  - level: RequestResponse
+    userGroups: ["admins"]
+  - level: Request
+    verbs: ["create", "update", "delete"]
+  - level: Metadata
+`
+
+func TestPolicyLevelFor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+
+	user := &request.UserInfo{Name: "alice"}
+	admin := &request.UserInfo{Name: "bob", Groups: []string{"admins"}}
+
+	tests := []struct {
+		name string
+		user *request.UserInfo
+		info *request.RequestInfo
+		want Level
+	}{
+		{
+			name: "lease renewal",
+			user: user,
+			info: &request.RequestInfo{IsResourceRequest: true, Verb: "update", Resource: "leases"},
+			want: LevelNone,
+		},
+		{
+			name: "health check",
+			user: user,
+			info: &request.RequestInfo{Verb: "get", Path: "/healthz/ping"},
+			want: LevelNone,
+		},
+		{
+			name: "admin",
+			user: admin,
+			info: &request.RequestInfo{IsResourceRequest: true, Verb: "list", Resource: "users"},
+			want: LevelRequestResponse,
+		},
+		{
+			name: "write",
+			user: user,
+			info: &request.RequestInfo{IsResourceRequest: true, Verb: "create", Resource: "leases"},
+			want: LevelRequest,
+		},
+		{
+			name: "read",
+			user: user,
+			info: &request.RequestInfo{Verb: "get", Path: "/version"},
+			want: LevelMetadata,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.LevelFor(tt.user, tt.info); got != tt.want {
+				t.Errorf("LevelFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	tests := []string{
+		"rules:\n  - level: Everything\n",
+		"rules:\n  - level: Metadata\n    resources: [leases]\n    nonResourceURLs: [/healthz]\n",
+		"rules:\n  - level: Metadata\n    unknown: true\n",
+	}
+
+	for _, policy := range tests {
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("LoadPolicy(%q) succeeded, want an error", policy)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package audit records the requests served by the apiserver: who did what,
+// when and with which outcome. Which requests are recorded and how much of
+// them is decided by a Policy, the events are written to one or more
+// Backends.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// Level defines the amount of information recorded for a request.
+type Level string
+
+// Valid audit levels.
+const (
+	// LevelNone disables auditing.
+	LevelNone Level = "None"
+	// LevelMetadata records the request metadata (user, verb, resource,
+	// status, latency...) but not the request or response bodies.
+	LevelMetadata Level = "Metadata"
+	// LevelRequest records the metadata and the request body.
+	LevelRequest Level = "Request"
+	// LevelRequestResponse records the metadata, the request body and the
+	// response body.
+	LevelRequestResponse Level = "RequestResponse"
+)
+
+var levelOrder = map[Level]int{
+	LevelNone:            0,
+	LevelMetadata:        1,
+	LevelRequest:         2,
+	LevelRequestResponse: 3,
+}
+
+// Less returns true if l records less information than other.
+func (l Level) Less(other Level) bool {
+	return levelOrder[l] < levelOrder[other]
+}
+
+// GreaterOrEqual returns true if l records at least as much information as other.
+func (l Level) GreaterOrEqual(other Level) bool {
+	return levelOrder[l] >= levelOrder[other]
+}
+
+// Validate returns an error if l is not a known level.
+func (l Level) Validate() error {
+	if _, ok := levelOrder[l]; !ok {
+		return fmt.Errorf("unknown audit level %q", l)
+	}
+
+	return nil
+}
+
+// Event captures all the information recorded for a request.
+type Event struct {
+	Level Level `json:"level"`
+
+	// AuditID is the unique ID generated for the request, also sent back
+	// to the client in the Audit-ID header.
+	AuditID string `json:"auditID"`
+	// RequestID is the X-Request-ID of the request.
+	RequestID string `json:"requestID,omitempty"`
+
+	RequestURI  string            `json:"requestURI"`
+	Verb        string            `json:"verb"`
+	User        request.UserInfo  `json:"user"`
+	SourceIPs   []string          `json:"sourceIPs,omitempty"`
+	UserAgent   string            `json:"userAgent,omitempty"`
+	ObjectRef   *ObjectReference  `json:"objectRef,omitempty"`
+	Code        int               `json:"code"`
+	Latency     string            `json:"latency"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// RequestObject is the request body, recorded at level Request and above.
+	RequestObject json.RawMessage `json:"requestObject,omitempty"`
+	// ResponseObject is the response body, recorded at level RequestResponse.
+	ResponseObject json.RawMessage `json:"responseObject,omitempty"`
+
+	RequestReceivedTimestamp time.Time `json:"requestReceivedTimestamp"`
+}
+
+// ObjectReference identifies the object a resource request is about.
+type ObjectReference struct {
+	Resource   string `json:"resource,omitempty"`
+	Name       string `json:"name,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+// EventList is the wire format of a batch of events sent to a webhook.
+type EventList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []*Event `json:"items"`
+}
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// webhookRetries is the number of times a failed batch is resent.
+const webhookRetries = 3
+
+type webhookBackend struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookBackend returns a Backend posting batches of events as an
+// EventList to url. Events are buffered and batched according to config.
+func NewWebhookBackend(url string, timeout time.Duration, config BatchConfig) Backend {
+	return NewBufferedBackend(&webhookBackend{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}, config)
+}
+
+func (b *webhookBackend) ProcessEvents(events ...*Event) {
+	list := &EventList{
+		TypeMeta: metav1.TypeMeta{Kind: "EventList", APIVersion: "audit/v1"},
+		Items:    events,
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		log.Errorf("Unable to encode %d audit events: %v", len(events), err)
+		return
+	}
+
+	backoff := time.Second
+
+	for attempt := 0; ; attempt++ {
+		err = b.send(data)
+		if err == nil {
+			return
+		}
+
+		if attempt == webhookRetries {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	log.Errorf("Unable to send %d audit events to the webhook: %v", len(events), err)
+}
+
+func (b *webhookBackend) send(data []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, b.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (b *webhookBackend) Shutdown() {}
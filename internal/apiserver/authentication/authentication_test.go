@@ -0,0 +1,239 @@
+package authentication
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/filters"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/auth"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Init(log.NewOptions())
+	os.Exit(m.Run())
+}
+
+var (
+	key1 = []byte("0123456789abcdef0123456789abcdef")
+	key2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func writePasswordFile(t *testing.T, lines string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "passwords")
+	if err := os.WriteFile(path, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadPasswordFile(t *testing.T) {
+	hash, err := auth.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writePasswordFile(t, "# users\n\nadmin:"+hash+":admins,dev\nbob:"+hash+"\n")
+
+	passwords, err := LoadPasswordFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		username, password string
+		want               *request.UserInfo
+	}{
+		{"admin", "secret", &request.UserInfo{Name: "admin", Groups: []string{"admins", "dev"}}},
+		{"bob", "secret", &request.UserInfo{Name: "bob"}},
+		{"bob", "wrong", nil},
+		{"unknown", "secret", nil},
+	}
+
+	for _, tt := range tests {
+		user, err := passwords.Authenticate(tt.username, tt.password)
+		if tt.want == nil && err != ErrInvalidCredentials || tt.want != nil && !reflect.DeepEqual(user, tt.want) {
+			t.Errorf("Authenticate(%q, %q) = %v, %v, want %v", tt.username, tt.password, user, err, tt.want)
+		}
+	}
+
+	for _, lines := range []string{"admin\n", ":hash\n", "admin:" + hash + "\nadmin:" + hash + "\n"} {
+		if _, err := LoadPasswordFile(writePasswordFile(t, lines)); err == nil {
+			t.Errorf("LoadPasswordFile(%q) succeeded, want an error", lines)
+		}
+	}
+}
+
+func TestTokenIssuer(t *testing.T) {
+	issuer, err := NewTokenIssuer([][]byte{key1}, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &request.UserInfo{Name: "admin", Groups: []string{"admins"}}
+
+	token, err := issuer.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := issuer.AuthenticateToken(token.AccessToken); err != nil || !reflect.DeepEqual(got, user) {
+		t.Errorf("AuthenticateToken() = %v, %v, want %v", got, err, user)
+	}
+
+	if got, err := issuer.AuthenticateRefreshToken(token.RefreshToken); err != nil || !reflect.DeepEqual(got, user) {
+		t.Errorf("AuthenticateRefreshToken() = %v, %v, want %v", got, err, user)
+	}
+
+	// The tokens are only accepted for their use.
+	if _, err := issuer.AuthenticateToken(token.RefreshToken); err != ErrInvalidToken {
+		t.Errorf("AuthenticateToken(refresh token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if _, err := issuer.AuthenticateRefreshToken(token.AccessToken); err != ErrInvalidToken {
+		t.Errorf("AuthenticateRefreshToken(access token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	// A new key signs the new tokens, the previous one still verifies its
+	// tokens.
+	rotated, err := NewTokenIssuer([][]byte{key2, key1}, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rotated.AuthenticateToken(token.AccessToken); err != nil {
+		t.Errorf("AuthenticateToken() after rotation error = %v", err)
+	}
+
+	newToken, err := rotated.Issue(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := issuer.AuthenticateToken(newToken.AccessToken); err != ErrInvalidToken {
+		t.Errorf("AuthenticateToken(token of an unknown key) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	issuer.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	if _, err := issuer.AuthenticateToken(token.AccessToken); err != ErrInvalidToken {
+		t.Errorf("AuthenticateToken(expired token) error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if err := issuer.Check(); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	if _, err := NewTokenIssuer([][]byte{[]byte("short")}, time.Hour, time.Hour); err == nil {
+		t.Errorf("NewTokenIssuer() with a short key succeeded, want an error")
+	}
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := auth.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwords, err := LoadPasswordFile(writePasswordFile(t, "admin:"+hash+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer, err := NewTokenIssuer([][]byte{key1}, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	InstallLogin(mux, passwords, issuer)
+	mux.HandleFunc("GET /whoami", func(w http.ResponseWriter, r *http.Request) {
+		user, _ := request.UserFrom(r.Context())
+		_, _ = w.Write([]byte(user.Name))
+	})
+
+	server := httptest.NewServer(filters.WithAuthentication(mux, issuer, nil, false, []string{LoginPath, RefreshPath}))
+	defer server.Close()
+
+	post := func(path string, body interface{}) (*v1.Token, int) {
+		data, _ := json.Marshal(body)
+
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		token := &v1.Token{}
+		_ = json.NewDecoder(resp.Body).Decode(token)
+
+		return token, resp.StatusCode
+	}
+
+	whoami := func(token string) (string, int) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/whoami", nil)
+		if len(token) != 0 {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(resp.Body)
+
+		return buf.String(), resp.StatusCode
+	}
+
+	if _, code := post(LoginPath, v1.LoginRequest{Username: "admin", Password: "wrong"}); code != http.StatusUnauthorized {
+		t.Errorf("login with a wrong password code = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	token, code := post(LoginPath, v1.LoginRequest{Username: "admin", Password: "secret"})
+	if code != http.StatusOK || len(token.AccessToken) == 0 || len(token.RefreshToken) == 0 {
+		t.Fatalf("login = %+v, %d, want tokens", token, code)
+	}
+
+	if name, code := whoami(token.AccessToken); code != http.StatusOK || name != "admin" {
+		t.Errorf("request with the token = %q, %d, want admin", name, code)
+	}
+
+	if _, code := whoami(""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous request code = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	if _, code := whoami(token.RefreshToken); code != http.StatusUnauthorized {
+		t.Errorf("request with the refresh token code = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	refreshed, code := post(RefreshPath, v1.RefreshRequest{RefreshToken: token.RefreshToken})
+	if code != http.StatusOK || len(refreshed.AccessToken) == 0 {
+		t.Fatalf("refresh = %+v, %d, want tokens", refreshed, code)
+	}
+
+	if name, code := whoami(refreshed.AccessToken); code != http.StatusOK || name != "admin" {
+		t.Errorf("request with the refreshed token = %q, %d, want admin", name, code)
+	}
+
+	// The removed users can't refresh their tokens.
+	delete(passwords.users, "admin")
+
+	if _, code := post(RefreshPath, v1.RefreshRequest{RefreshToken: token.RefreshToken}); code != http.StatusUnauthorized {
+		t.Errorf("refresh of a removed user code = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
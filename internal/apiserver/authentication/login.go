@@ -0,0 +1,95 @@
+package authentication
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/endpoints"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// Paths of the login endpoints, they are served to anonymous users.
+const (
+	LoginPath   = "/login"
+	RefreshPath = "/login/refresh"
+)
+
+// maxLoginBodyBytes is the limit on the size of a login request body.
+const maxLoginBodyBytes = 64 * 1024
+
+// InstallLogin registers POST /login, exchanging the username and the
+// password of a user for a v1.Token, and POST /login/refresh, exchanging a
+// refresh token for a new v1.Token. A refresh fails once the user is removed
+// from passwords.
+func InstallLogin(mux *http.ServeMux, passwords *Passwords, issuer *TokenIssuer) {
+	mux.HandleFunc("POST "+LoginPath, func(w http.ResponseWriter, r *http.Request) {
+		var req v1.LoginRequest
+		if err := decodeLoginBody(r, &req); err != nil {
+			endpoints.WriteError(w, r, err)
+			return
+		}
+
+		user, err := passwords.Authenticate(req.Username, req.Password)
+		if err != nil {
+			log.FromContext(r.Context()).Info("Login failed", "username", req.Username)
+			endpoints.WriteError(w, r, apierrors.NewUnauthorized(err.Error()))
+
+			return
+		}
+
+		writeToken(w, r, issuer, user)
+	})
+
+	mux.HandleFunc("POST "+RefreshPath, func(w http.ResponseWriter, r *http.Request) {
+		var req v1.RefreshRequest
+		if err := decodeLoginBody(r, &req); err != nil {
+			endpoints.WriteError(w, r, err)
+			return
+		}
+
+		user, err := issuer.AuthenticateRefreshToken(req.RefreshToken)
+		if err != nil {
+			endpoints.WriteError(w, r, apierrors.NewUnauthorized(err.Error()))
+			return
+		}
+
+		// The groups may have changed since the login.
+		if user, ok := passwords.Lookup(user.Name); ok {
+			writeToken(w, r, issuer, user)
+			return
+		}
+
+		endpoints.WriteError(w, r, apierrors.NewUnauthorized(fmt.Sprintf("user %q no longer exists", user.Name)))
+	})
+}
+
+// decodeLoginBody decodes the JSON body of r into v.
+func decodeLoginBody(r *http.Request, v interface{}) error {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxLoginBodyBytes))
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("unable to decode request body: %v", err))
+	}
+
+	return nil
+}
+
+// writeToken issues and writes the tokens of user.
+func writeToken(w http.ResponseWriter, r *http.Request, issuer *TokenIssuer, user *request.UserInfo) {
+	token, err := issuer.Issue(user)
+	if err != nil {
+		endpoints.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	endpoints.WriteObject(w, http.StatusOK, token)
+}
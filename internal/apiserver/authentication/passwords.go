@@ -0,0 +1,103 @@
+// Package authentication authenticates the users of the apiserver: it
+// checks their passwords, issues the tokens they log in with and verifies
+// the tokens of their requests.
+package authentication
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	"github.com/hanzhuoxian/flora/pkg/auth"
+)
+
+// ErrInvalidCredentials is returned for an unknown user or a wrong password.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// dummyHash is compared with the passwords of unknown users, so that they
+// can't be told from known ones by the response time.
+const dummyHash = "$2a$10$XuD/qyTXddwdI.G97e1NN.CkEShw/16WDwobslUHW9.Fl8WNxJ9m."
+
+// passwordEntry is a user of a password file.
+type passwordEntry struct {
+	hash   string
+	groups []string
+}
+
+// Passwords holds the users allowed to log in and the bcrypt hashes of
+// their passwords.
+type Passwords struct {
+	users map[string]passwordEntry
+}
+
+// LoadPasswordFile reads a password file. Each line holds a user as
+// username:bcrypt-hash[:group1,group2], as written by htpasswd -B with the
+// groups appended. Empty lines and lines starting with '#' are ignored.
+func LoadPasswordFile(path string) (*Passwords, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Passwords{users: map[string]passwordEntry{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) < 2 || len(fields[0]) == 0 || len(fields[1]) == 0 {
+			return nil, fmt.Errorf("%s:%d: want username:bcrypt-hash[:groups]", path, n)
+		}
+
+		if _, ok := p.users[fields[0]]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate user %q", path, n, fields[0])
+		}
+
+		entry := passwordEntry{hash: fields[1]}
+		if len(fields) == 3 && len(fields[2]) != 0 {
+			entry.groups = strings.Split(fields[2], ",")
+		}
+
+		p.users[fields[0]] = entry
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Authenticate returns the user whose password is password.
+func (p *Passwords) Authenticate(username, password string) (*request.UserInfo, error) {
+	entry, ok := p.users[username]
+	if !ok {
+		_ = auth.Compare(dummyHash, password)
+
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := auth.Compare(entry.hash, password); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &request.UserInfo{Name: username, Groups: entry.groups}, nil
+}
+
+// Lookup returns the user named username, if it still exists.
+func (p *Passwords) Lookup(username string) (*request.UserInfo, bool) {
+	entry, ok := p.users[username]
+	if !ok {
+		return nil, false
+	}
+
+	return &request.UserInfo{Name: username, Groups: entry.groups}, true
+}
@@ -0,0 +1,223 @@
+package authentication
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// Issuer is the issuer of the tokens, checked when they are verified.
+const Issuer = "flora-apiserver"
+
+// The types of tokens. A refresh token is only accepted to get new tokens,
+// and an access token only to authenticate requests.
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// MinSigningKeyBytes is the minimum length of a signing key.
+const MinSigningKeyBytes = 32
+
+// ErrInvalidToken is returned for a token that is malformed, expired, of
+// the wrong type or not signed by a known key.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// claims are the claims of the tokens.
+type claims struct {
+	jwt.StandardClaims
+
+	Groups []string `json:"groups,omitempty"`
+	Type   string   `json:"typ"`
+}
+
+// signingKey is an HMAC key identified by the kid header of the tokens it
+// signed.
+type signingKey struct {
+	id  string
+	key []byte
+}
+
+// TokenIssuer issues the tokens of the users and verifies them.
+type TokenIssuer struct {
+	// keys verify the tokens, the first one signs the new tokens. Keeping
+	// the previous keys after the new one lets the tokens they signed be
+	// used until they expire.
+	keys []signingKey
+
+	tokenTTL        time.Duration
+	refreshTokenTTL time.Duration
+
+	// now returns the current time, it is replaced by the tests.
+	now func() time.Time
+}
+
+// NewTokenIssuer returns a TokenIssuer signing with the first of keys the
+// access tokens valid for tokenTTL and the refresh tokens valid for
+// refreshTokenTTL.
+func NewTokenIssuer(keys [][]byte, tokenTTL, refreshTokenTTL time.Duration) (*TokenIssuer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+
+	issuer := &TokenIssuer{tokenTTL: tokenTTL, refreshTokenTTL: refreshTokenTTL, now: time.Now}
+
+	for _, key := range keys {
+		if len(key) < MinSigningKeyBytes {
+			return nil, fmt.Errorf("signing keys must be at least %d bytes long", MinSigningKeyBytes)
+		}
+
+		sum := sha256.Sum256(key)
+		issuer.keys = append(issuer.keys, signingKey{id: hex.EncodeToString(sum[:8]), key: key})
+	}
+
+	return issuer, nil
+}
+
+// LoadSigningKeys reads the signing keys of a file, one per line. The first
+// key signs the tokens, the others only verify them. Empty lines and lines
+// starting with '#' are ignored.
+func LoadSigningKeys(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]byte
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) != 0 && !strings.HasPrefix(line, "#") {
+			keys = append(keys, []byte(line))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing key found in %s", path)
+	}
+
+	return keys, nil
+}
+
+// GenerateSigningKey returns a random signing key.
+func GenerateSigningKey() ([]byte, error) {
+	key := make([]byte, MinSigningKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(key)), nil
+}
+
+// Issue returns a new access token and refresh token for user.
+func (i *TokenIssuer) Issue(user *request.UserInfo) (*v1.Token, error) {
+	now := i.now()
+
+	token := &v1.Token{
+		TypeMeta:         metav1.TypeMeta{Kind: "Token", APIVersion: v1.SchemeGroupVersion.String()},
+		ExpiresAt:        now.Add(i.tokenTTL),
+		RefreshExpiresAt: now.Add(i.refreshTokenTTL),
+	}
+
+	var err error
+
+	if token.AccessToken, err = i.sign(user, tokenTypeAccess, now, token.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if token.RefreshToken, err = i.sign(user, tokenTypeRefresh, now, token.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// AuthenticateToken returns the user of an access token.
+func (i *TokenIssuer) AuthenticateToken(token string) (*request.UserInfo, error) {
+	return i.verify(token, tokenTypeAccess)
+}
+
+// AuthenticateRefreshToken returns the user of a refresh token.
+func (i *TokenIssuer) AuthenticateRefreshToken(token string) (*request.UserInfo, error) {
+	return i.verify(token, tokenTypeRefresh)
+}
+
+// Check signs and verifies a token, it tells whether the issuer can serve
+// the logins.
+func (i *TokenIssuer) Check() error {
+	now := i.now()
+
+	token, err := i.sign(&request.UserInfo{Name: "system:readyz"}, tokenTypeAccess, now, now.Add(time.Minute))
+	if err != nil {
+		return fmt.Errorf("unable to sign a token: %w", err)
+	}
+
+	if _, err := i.verify(token, tokenTypeAccess); err != nil {
+		return fmt.Errorf("unable to verify a token: %w", err)
+	}
+
+	return nil
+}
+
+func (i *TokenIssuer) sign(user *request.UserInfo, tokenType string, now, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   user.Name,
+			Issuer:    Issuer,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+		Groups: user.Groups,
+		Type:   tokenType,
+	})
+	token.Header["kid"] = i.keys[0].id
+
+	return token.SignedString(i.keys[0].key)
+}
+
+func (i *TokenIssuer) verify(token, tokenType string) (*request.UserInfo, error) {
+	c := &claims{}
+
+	parser := &jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}, SkipClaimsValidation: true}
+
+	_, err := parser.ParseWithClaims(token, c, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		for _, key := range i.keys {
+			if key.id == kid {
+				return key.key, nil
+			}
+		}
+
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	})
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := i.now().Unix()
+	if !c.VerifyExpiresAt(now, true) || !c.VerifyNotBefore(now, false) || !c.VerifyIssuer(Issuer, true) ||
+		c.Type != tokenType || len(c.Subject) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return &request.UserInfo{Name: c.Subject, Groups: c.Groups}, nil
+}
@@ -0,0 +1,36 @@
+package endpoints
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/generic"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// verbs are the verbs InstallREST serves for every resource.
+var verbs = []string{"get", "list", "watch", "create", "update", "delete"}
+
+// InstallDiscovery registers prefix, e.g. /v1, to list the resources of
+// stores so that clients can work with resources they were not built with.
+func InstallDiscovery(mux *http.ServeMux, prefix string, groupVersion string, stores ...*generic.Store) {
+	list := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: groupVersion,
+		APIResources: make([]metav1.APIResource, 0, len(stores)),
+	}
+
+	for _, store := range stores {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:         store.QualifiedResource.Resource,
+			SingularName: strings.ToLower(store.Kind()),
+			Kind:         store.Kind(),
+			ShortNames:   store.ShortNames,
+			Verbs:        verbs,
+		})
+	}
+
+	mux.HandleFunc("GET "+prefix, func(w http.ResponseWriter, _ *http.Request) {
+		WriteObject(w, http.StatusOK, list)
+	})
+}
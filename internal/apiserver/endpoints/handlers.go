@@ -0,0 +1,324 @@
+// Package endpoints exposes the registered resources over HTTP.
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/generic"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/labels"
+	"github.com/hanzhuoxian/flora/pkg/log"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// maxRequestBodyBytes is the limit on the size of a request body.
+const maxRequestBodyBytes = 3 * 1024 * 1024
+
+// listResponse is the wire format of a list of objects.
+type listResponse struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []metav1.Object `json:"items"`
+}
+
+// InstallREST registers the list, get, create, update and delete endpoints of
+// store under prefix, e.g. /v1/leases and /v1/leases/{name}. Clients that
+// accept metav1.TableContentType get the objects of list, get and watch as a
+// metav1.Table.
+func InstallREST(mux *http.ServeMux, prefix string, store *generic.Store) {
+	collection := path.Join(prefix, store.QualifiedResource.Resource)
+	item := collection + "/{name}"
+
+	mux.HandleFunc("GET "+collection, func(w http.ResponseWriter, r *http.Request) {
+		opts, err := parseListOptions(r.URL.Query())
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		if opts.Watch {
+			serveWatch(w, r, store, opts)
+			return
+		}
+
+		objs, listMeta, err := store.List(r.Context(), opts)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		if wantsTable(r) {
+			writeTable(w, r, store, objs, listMeta)
+			return
+		}
+
+		list := &listResponse{ListMeta: listMeta, Items: objs}
+		list.SetGroupVersionKind(store.GroupVersionKind.GroupVersion().WithKind(store.Kind() + "List"))
+
+		WriteObject(w, http.StatusOK, list)
+	})
+
+	mux.HandleFunc("GET "+item, func(w http.ResponseWriter, r *http.Request) {
+		obj, err := store.Get(r.Context(), r.PathValue("name"))
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		if wantsTable(r) {
+			writeTable(w, r, store, []metav1.Object{obj}, metav1.ListMeta{})
+			return
+		}
+
+		WriteObject(w, http.StatusOK, obj)
+	})
+
+	mux.HandleFunc("POST "+collection, func(w http.ResponseWriter, r *http.Request) {
+		dryRun, err := parseDryRun(r.URL.Query())
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		obj := store.NewFunc()
+		if err := decodeBody(r, obj, store.GroupVersionKind); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		created, err := store.Create(r.Context(), obj, metav1.CreateOptions{DryRun: dryRun})
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		WriteObject(w, http.StatusCreated, created)
+	})
+
+	mux.HandleFunc("PUT "+item, func(w http.ResponseWriter, r *http.Request) {
+		dryRun, err := parseDryRun(r.URL.Query())
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		obj := store.NewFunc()
+		if err := decodeBody(r, obj, store.GroupVersionKind); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		updated, err := store.Update(r.Context(), r.PathValue("name"), obj, metav1.UpdateOptions{DryRun: dryRun})
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		WriteObject(w, http.StatusOK, updated)
+	})
+
+	mux.HandleFunc("DELETE "+item, func(w http.ResponseWriter, r *http.Request) {
+		dryRun, err := parseDryRun(r.URL.Query())
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		obj, err := store.Delete(r.Context(), r.PathValue("name"), metav1.DeleteOptions{DryRun: dryRun})
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		WriteObject(w, http.StatusOK, obj)
+	})
+}
+
+// parseListOptions reads the list options from the query parameters.
+func parseListOptions(query url.Values) (metav1.ListOptions, error) {
+	opts := metav1.ListOptions{
+		Continue:        query.Get("continue"),
+		ResourceVersion: query.Get("resourceVersion"),
+	}
+
+	if selector := query.Get("labelSelector"); len(selector) != 0 {
+		if _, err := labels.Parse(selector); err != nil {
+			return opts, apierrors.NewBadRequest(err.Error())
+		}
+
+		opts.LabelSelector = selector
+	}
+
+	if limit := query.Get("limit"); len(limit) != 0 {
+		v, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || v < 0 {
+			return opts, apierrors.NewBadRequest(fmt.Sprintf("invalid limit %q", limit))
+		}
+
+		opts.Limit = v
+	}
+
+	if watch := query.Get("watch"); len(watch) != 0 {
+		v, err := strconv.ParseBool(watch)
+		if err != nil {
+			return opts, apierrors.NewBadRequest(fmt.Sprintf("invalid watch %q", watch))
+		}
+
+		opts.Watch = v
+	}
+
+	return opts, nil
+}
+
+// parseDryRun reads the dryRun query parameter. All is the only supported
+// value.
+func parseDryRun(query url.Values) ([]string, error) {
+	dryRun := query["dryRun"]
+	for _, v := range dryRun {
+		if v != metav1.DryRunAll {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid dryRun %q, the only supported value is %q", v, metav1.DryRunAll))
+		}
+	}
+
+	return dryRun, nil
+}
+
+// serveWatch streams the changes of the resource as newline separated
+// metav1.WatchEvent objects until the client goes away. Tables sent to watch
+// clients hold a single row.
+func serveWatch(w http.ResponseWriter, r *http.Request, store *generic.Store, opts metav1.ListOptions) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, r, apierrors.NewInternalError(errors.New("streaming is not supported")))
+		return
+	}
+
+	events, err := store.Watch(r.Context(), opts)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	table := wantsTable(r)
+
+	for event := range events {
+		var obj interface{} = event.Object
+		if table {
+			var err error
+			if obj, err = store.ConvertToTable([]metav1.Object{event.Object}, metav1.ListMeta{}); err != nil {
+				log.FromContext(r.Context()).Error(err, "Unable to convert watch event to a table")
+				return
+			}
+		}
+
+		data, err := json.Marshal(obj)
+		if err != nil {
+			log.FromContext(r.Context()).Error(err, "Unable to encode watch event")
+			return
+		}
+
+		if err := enc.Encode(metav1.WatchEvent{Type: event.Type, Object: data}); err != nil {
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// wantsTable returns true if the client accepts the objects as a table.
+func wantsTable(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accept)
+		if err == nil && mediaType == "application/json" && params["as"] == "Table" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeTable writes objs as a metav1.Table.
+func writeTable(w http.ResponseWriter, r *http.Request, store *generic.Store, objs []metav1.Object,
+	listMeta metav1.ListMeta,
+) {
+	table, err := store.ConvertToTable(objs, listMeta)
+	if err != nil {
+		WriteError(w, r, apierrors.NewInternalError(err))
+		return
+	}
+
+	WriteObject(w, http.StatusOK, table)
+}
+
+// decodeBody decodes the request body into obj and checks that the kind, if
+// set, matches the kind served by the endpoint.
+func decodeBody(r *http.Request, obj metav1.Object, gvk scheme.GroupVersionKind) error {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodyBytes))
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+
+	if err := json.Unmarshal(data, obj); err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("unable to decode request body: %v", err))
+	}
+
+	if kind := obj.GetObjectKind().GroupVersionKind(); len(kind.Kind) != 0 && kind.Kind != gvk.Kind {
+		return apierrors.NewBadRequest(fmt.Sprintf("kind %q does not match the expected kind %q", kind.Kind, gvk.Kind))
+	}
+
+	return nil
+}
+
+// WriteObject writes obj as JSON with the given status code.
+func WriteObject(w http.ResponseWriter, code int, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		log.Errorf("Unable to encode response: %v", err)
+		code = http.StatusInternalServerError
+		data, _ = json.Marshal(statusFor(apierrors.NewInternalError(err)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+// WriteError writes err as a metav1.Status object. Errors that do not carry
+// a status are reported as internal errors.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusFor(err)
+	if status.Code == http.StatusInternalServerError {
+		log.FromContext(r.Context()).Error(err, "Request failed", "method", r.Method, "path", r.URL.Path)
+	}
+
+	WriteObject(w, int(status.Code), status)
+}
+
+func statusFor(err error) *metav1.Status {
+	var apiStatus apierrors.APIStatus
+	if !errors.As(err, &apiStatus) {
+		apiStatus = apierrors.NewInternalError(err)
+	}
+
+	status := apiStatus.Status()
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+
+	return &status
+}
@@ -0,0 +1,210 @@
+package filters
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/audit"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+)
+
+// HeaderAuditID is the response header carrying the ID of the audit event.
+const HeaderAuditID = "Audit-ID"
+
+// maxAuditBodyBytes is the largest request or response body recorded in an
+// audit event. Larger bodies are omitted.
+const maxAuditBodyBytes = 1024 * 1024
+
+// WithAudit records an audit event for every request according to policy.
+// It must run after WithRequestInfo and the authentication filter.
+func WithAudit(handler http.Handler, policy *audit.Policy, backend audit.Backend) http.Handler {
+	if backend == nil {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := request.RequestInfoFrom(r.Context())
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		user, ok := request.UserFrom(r.Context())
+		if !ok {
+			user = &request.UserInfo{Name: request.Anonymous}
+		}
+
+		level := policy.LevelFor(user, info)
+		if level == audit.LevelNone {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		event := newEvent(r, level, info, user)
+
+		// Only the objects of the resource requests are recorded, the other
+		// bodies may hold credentials, e.g. the ones of /login.
+		if level.GreaterOrEqual(audit.LevelRequest) && info.IsResourceRequest && r.Body != nil {
+			data, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodyBytes+1))
+			if err == nil {
+				event.RequestObject = auditBody(data)
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+			}
+		}
+
+		rw := &auditResponseWriter{
+			statusRecorder: statusRecorder{ResponseWriter: w},
+			captureBody: level.GreaterOrEqual(audit.LevelRequestResponse) && info.IsResourceRequest &&
+				info.Verb != "watch",
+		}
+		rw.Header().Set(HeaderAuditID, event.AuditID)
+
+		defer func() {
+			event.Code = rw.statusCode()
+			event.Latency = time.Since(event.RequestReceivedTimestamp).String()
+
+			if rw.captureBody && !rw.truncated {
+				event.ResponseObject = auditBody(rw.body.Bytes())
+			}
+
+			backend.ProcessEvents(event)
+		}()
+
+		handler.ServeHTTP(rw, r)
+	})
+}
+
+// WithFailedAuthenticationAudit records an audit event for the requests
+// rejected by WithAuthentication, which are served by failedHandler. Their
+// user is unknown, so only the policy rules without users or groups match.
+// It must run after WithRequestInfo.
+func WithFailedAuthenticationAudit(failedHandler http.Handler, policy *audit.Policy, backend audit.Backend) http.Handler {
+	if backend == nil {
+		return failedHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := request.RequestInfoFrom(r.Context())
+		if !ok {
+			failedHandler.ServeHTTP(w, r)
+			return
+		}
+
+		user := &request.UserInfo{}
+
+		level := policy.LevelFor(user, info)
+		if level == audit.LevelNone {
+			failedHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// The bodies of the rejected requests are never recorded.
+		event := newEvent(r, level, info, user)
+		event.Annotations = map[string]string{"authentication.reason": authenticationError(r)}
+
+		rw := &statusRecorder{ResponseWriter: w}
+		rw.Header().Set(HeaderAuditID, event.AuditID)
+
+		failedHandler.ServeHTTP(rw, r)
+
+		event.Code = rw.statusCode()
+		event.Latency = time.Since(event.RequestReceivedTimestamp).String()
+		backend.ProcessEvents(event)
+	})
+}
+
+// newEvent returns the audit event of r recorded at level.
+func newEvent(r *http.Request, level audit.Level, info *request.RequestInfo, user *request.UserInfo) *audit.Event {
+	event := &audit.Event{
+		Level:                    level,
+		AuditID:                  requestid.New(),
+		RequestURI:               r.RequestURI,
+		Verb:                     info.Verb,
+		User:                     *user,
+		SourceIPs:                sourceIPs(r),
+		UserAgent:                r.UserAgent(),
+		RequestReceivedTimestamp: time.Now(),
+	}
+
+	if id, ok := requestid.FromContext(r.Context()); ok {
+		event.RequestID = id
+	}
+
+	if info.IsResourceRequest {
+		event.ObjectRef = &audit.ObjectReference{
+			Resource:   info.Resource,
+			Name:       info.Name,
+			APIVersion: info.APIVersion,
+		}
+	}
+
+	return event
+}
+
+// auditBody returns data if it is a JSON document small enough to be recorded.
+func auditBody(data []byte) json.RawMessage {
+	if len(data) == 0 || len(data) > maxAuditBodyBytes || !json.Valid(data) {
+		return nil
+	}
+
+	return data
+}
+
+// auditResponseWriter records the status code and, if requested, the body
+// of a response.
+type auditResponseWriter struct {
+	statusRecorder
+
+	captureBody bool
+	body        bytes.Buffer
+	truncated   bool
+}
+
+func (w *auditResponseWriter) Write(p []byte) (int, error) {
+	if w.captureBody && !w.truncated {
+		if w.body.Len()+len(p) > maxAuditBodyBytes {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(p)
+		}
+	}
+
+	return w.statusRecorder.Write(p)
+}
+
+// sourceIPs returns the client IPs of r: the X-Forwarded-For chain, then
+// X-Real-Ip and finally the address of the peer.
+func sourceIPs(r *http.Request) []string {
+	var ips []string
+
+	for _, ip := range strings.Split(r.Header.Get("X-Forwarded-For"), ",") {
+		if ip = strings.TrimSpace(ip); len(ip) != 0 {
+			ips = append(ips, ip)
+		}
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-Ip")); len(ip) != 0 {
+		ips = append(ips, ip)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if len(host) != 0 && (len(ips) == 0 || ips[len(ips)-1] != host) {
+		ips = append(ips, host)
+	}
+
+	return ips
+}
@@ -0,0 +1,75 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/audit"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+)
+
+// fakeBackend keeps the events in memory.
+type fakeBackend struct {
+	events []*audit.Event
+}
+
+func (b *fakeBackend) ProcessEvents(events ...*audit.Event) {
+	b.events = append(b.events, events...)
+}
+
+func (b *fakeBackend) Shutdown() {}
+
+func TestAuditAuthentication(t *testing.T) {
+	authenticator := fakeAuthenticator{"admin": {Name: "admin", Groups: []string{"admins"}}}
+
+	tests := []struct {
+		name   string
+		token  string
+		code   int
+		user   string
+		reason string
+	}{
+		{name: "authenticated", token: "admin", code: http.StatusOK, user: "admin"},
+		{name: "invalid token", token: "expired", code: http.StatusUnauthorized, reason: "invalid token"},
+		{name: "no token", code: http.StatusUnauthorized, reason: "authentication required"},
+	}
+
+	for _, tt := range tests {
+		backend := &fakeBackend{}
+		policy := audit.DefaultPolicy()
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		chain := WithRequestInfo(
+			WithAuthentication(WithAudit(handler, policy, backend), authenticator,
+				WithFailedAuthenticationAudit(Unauthorized(), policy, backend), false, nil),
+			&request.RequestInfoFactory{APIVersions: []string{"v1"}})
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/leases", nil)
+		if len(tt.token) != 0 {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		w := httptest.NewRecorder()
+		chain.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.name, w.Code, tt.code)
+		}
+
+		if len(backend.events) != 1 {
+			t.Errorf("%s: %d audit events, want 1", tt.name, len(backend.events))
+			continue
+		}
+
+		event := backend.events[0]
+		if event.Code != tt.code || event.User.Name != tt.user || event.Verb != "list" ||
+			event.Annotations["authentication.reason"] != tt.reason {
+			t.Errorf("%s: audit event = %+v, want code %d of user %q rejected for %q", tt.name, event, tt.code, tt.user, tt.reason)
+		}
+
+		if w.Header().Get(HeaderAuditID) != event.AuditID {
+			t.Errorf("%s: %s = %q, want %q", tt.name, HeaderAuditID, w.Header().Get(HeaderAuditID), event.AuditID)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package filters
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/endpoints"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+)
+
+// TokenAuthenticator returns the user of a bearer token.
+type TokenAuthenticator interface {
+	AuthenticateToken(token string) (*request.UserInfo, error)
+}
+
+// WithAuthentication attaches the user of the bearer token of the request
+// to its context. Requests with an invalid token are rejected. Requests
+// without a token are served as the anonymous user when anonymous is true,
+// or for the public paths only. The rejected requests are served by failed,
+// which defaults to Unauthorized when nil.
+func WithAuthentication(handler http.Handler, authenticator TokenAuthenticator, failed http.Handler, anonymous bool,
+	publicPaths []string,
+) http.Handler {
+	if failed == nil {
+		failed = Unauthorized()
+	}
+
+	public := make(map[string]bool, len(publicPaths))
+	for _, path := range publicPaths {
+		public[path] = true
+	}
+
+	reject := func(w http.ResponseWriter, r *http.Request, reason string) {
+		failed.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authenticationErrorKey{}, reason)))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			if !anonymous && !public[r.URL.Path] {
+				reject(w, r, "authentication required")
+				return
+			}
+
+			handler.ServeHTTP(w, r.WithContext(request.WithUser(r.Context(), &request.UserInfo{Name: request.Anonymous})))
+
+			return
+		}
+
+		user, err := authenticator.AuthenticateToken(token)
+		if err != nil {
+			reject(w, r, err.Error())
+			return
+		}
+
+		// The token must not reach the handlers.
+		r = r.Clone(request.WithUser(r.Context(), user))
+		r.Header.Del("Authorization")
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token of the Authorization header of r.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, len(token) != 0
+}
+
+// authenticationErrorKey is the context key of the reason why
+// WithAuthentication rejected a request.
+type authenticationErrorKey struct{}
+
+// authenticationError returns the reason why WithAuthentication rejected r.
+func authenticationError(r *http.Request) string {
+	if reason, ok := r.Context().Value(authenticationErrorKey{}).(string); ok {
+		return reason
+	}
+
+	return "authentication required"
+}
+
+// Unauthorized rejects the requests with an Unauthorized status giving the
+// reason of WithAuthentication.
+func Unauthorized() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unauthorized(w, r, authenticationError(r))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, reason string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="flora"`)
+	endpoints.WriteError(w, r, apierrors.NewUnauthorized(reason))
+}
@@ -0,0 +1,34 @@
+package filters
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/endpoints"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+)
+
+// WithGroup serves the requests of the users of group only, e.g. for the
+// debug endpoints. The anonymous requests are rejected as unauthorized and
+// the ones of the other users as forbidden. It must run after
+// WithAuthentication.
+func WithGroup(handler http.Handler, group string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := request.UserFrom(r.Context())
+		if !ok || user.Name == request.Anonymous {
+			unauthorized(w, r, "authentication required")
+			return
+		}
+
+		for _, g := range user.Groups {
+			if g == group {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		endpoints.WriteError(w, r, apierrors.NewForbidden(
+			fmt.Sprintf("user %q is not in the group %q allowed to access %s", user.Name, group, r.URL.Path)))
+	})
+}
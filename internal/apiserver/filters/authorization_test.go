@@ -0,0 +1,57 @@
+package filters
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// fakeAuthenticator authenticates the tokens named after their users.
+type fakeAuthenticator map[string]*request.UserInfo
+
+func (a fakeAuthenticator) AuthenticateToken(token string) (*request.UserInfo, error) {
+	if user, ok := a[token]; ok {
+		return user, nil
+	}
+
+	return nil, apierrors.NewUnauthorized("invalid token")
+}
+
+func TestWithGroup(t *testing.T) {
+	defer log.SetLevel(log.GetLevel())
+
+	authenticator := fakeAuthenticator{
+		"admin": {Name: "admin", Groups: []string{"admins"}},
+		"bob":   {Name: "bob", Groups: []string{"dev"}},
+	}
+	handler := WithAuthentication(WithGroup(log.LevelHandler(), "admins"), authenticator, nil, true, nil)
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "anonymous", want: http.StatusUnauthorized},
+		{name: "other group", token: "bob", want: http.StatusForbidden},
+		{name: "admin", token: "admin", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/debug/loglevel", bytes.NewBufferString(`{"level":"debug"}`))
+		if len(tt.token) != 0 {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: PUT /debug/loglevel code = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
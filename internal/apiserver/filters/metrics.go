@@ -0,0 +1,93 @@
+package filters
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/metrics"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+)
+
+// WithMetrics records the count, latency and in-flight number of requests.
+// It must run after WithRequestInfo. Only the served resources are used as
+// resource labels, the requests to other resources are labeled "unknown".
+func WithMetrics(handler http.Handler, resources []string) http.Handler {
+	served := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		served[resource] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := request.RequestInfoFrom(r.Context())
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		// Non-resource requests get an empty resource label, their path is
+		// not used as a label to keep the cardinality bounded.
+		verb, resource := metrics.CleanVerb(info.Verb), info.Resource
+		if info.IsResourceRequest && !served[resource] {
+			resource = "unknown"
+		}
+
+		done := metrics.RequestStarted(verb, resource)
+		defer done()
+
+		start := time.Now()
+		rw := &statusRecorder{ResponseWriter: w}
+
+		defer func() {
+			metrics.MonitorRequest(verb, resource, rw.statusCode(), time.Since(start))
+		}()
+
+		handler.ServeHTTP(rw, r)
+	})
+}
+
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush lets streaming handlers such as watch flush through the writer.
+func (w *statusRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusRecorder) statusCode() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
@@ -0,0 +1,46 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/metrics"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+)
+
+func TestMetricsLabels(t *testing.T) {
+	handler := WithRequestInfo(WithMetrics(http.NotFoundHandler(), []string{"leases"}),
+		&request.RequestInfoFactory{APIVersions: []string{"v1"}})
+
+	for _, r := range []struct{ method, path string }{
+		{http.MethodGet, "/v1/leases"},
+		{http.MethodGet, "/v1/aaa"},
+		{"FOOBAR", "/v1/leases/a"},
+		{"FOOBAR", "/healthz"},
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`apiserver_request_total{code="404",resource="leases",verb="list"} 1`,
+		`apiserver_request_total{code="404",resource="unknown",verb="list"} 1`,
+		`apiserver_request_total{code="404",resource="leases",verb="other"} 1`,
+		`apiserver_request_total{code="404",resource="",verb="other"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+
+	for _, label := range []string{`resource="aaa"`, `verb="foobar"`} {
+		if strings.Contains(body, label) {
+			t.Errorf("metrics contain the label %s of the request", label)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package filters
+
+import (
+	"net/http"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+)
+
+// WithRequestID attaches a request ID to the request context and echoes it
+// in the X-Request-ID response header. The ID sent by the client is kept if
+// it is valid, otherwise a new one is generated. The context also holds the
+// logger of the request, logging its ID.
+func WithRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		ctx := requestid.WithRequestID(r.Context(), id)
+		ctx = log.NewContext(ctx, log.GetLogger())
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
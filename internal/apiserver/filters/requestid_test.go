@@ -0,0 +1,45 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/log/logtest"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+)
+
+func TestRequestLogger(t *testing.T) {
+	logs := logtest.Install(t, log.InfoLevel)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Info("handled")
+	})
+
+	chain := WithRequestID(WithRequestInfo(WithTracing(handler),
+		&request.RequestInfoFactory{APIVersions: []string{"v1"}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/leases", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	chain.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.FilterMessage("handled").All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v, want one", logs.Messages())
+	}
+
+	fields := map[string]int{}
+	for _, f := range entries[0].Context {
+		fields[f.Key]++
+	}
+
+	if len(fields) != 3 || fields["request_id"] != 1 || fields["trace_id"] != 1 || fields["span_id"] != 1 {
+		t.Errorf("fields = %v, want request_id, trace_id and span_id once", entries[0].Context)
+	}
+
+	logs.AssertHasField(t, "request_id", "req-1")
+	logs.AssertHasField(t, "trace_id", "0af7651916cd43dd8448eb211c80319c")
+}
@@ -0,0 +1,17 @@
+// Package filters contains the HTTP middlewares wrapped around the apiserver
+// handlers.
+package filters
+
+import (
+	"net/http"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+)
+
+// WithRequestInfo attaches the RequestInfo of the request to its context.
+func WithRequestInfo(handler http.Handler, factory *request.RequestInfoFactory) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := factory.NewRequestInfo(r)
+		handler.ServeHTTP(w, r.WithContext(request.WithRequestInfo(r.Context(), info)))
+	})
+}
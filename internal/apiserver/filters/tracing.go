@@ -0,0 +1,43 @@
+package filters
+
+import (
+	"net/http"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/trace"
+)
+
+// WithTracing starts a server span for every request, as a child of the
+// span of the traceparent header if the client sent one, and adds its IDs to
+// the logger of the request. It must run after WithRequestID and
+// WithRequestInfo.
+func WithTracing(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Method + " " + r.URL.Path
+		if info, ok := request.RequestInfoFrom(r.Context()); ok && info.IsResourceRequest {
+			name = info.Verb + " " + info.Resource
+		}
+
+		ctx := trace.Extract(r.Context(), r.Header)
+		ctx, span := trace.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				trace.String("http.method", r.Method),
+				trace.String("http.target", r.URL.RequestURI()),
+				trace.String("http.user_agent", r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		// The logger logs the IDs of the span without adding them on every
+		// FromContext.
+		ctx = log.NewContext(ctx, log.GetLogger())
+
+		rw := &statusRecorder{ResponseWriter: w}
+
+		handler.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttributes(trace.Int("http.status_code", rw.statusCode()))
+	})
+}
@@ -0,0 +1,166 @@
+// Package healthz serves the /healthz, /livez and /readyz endpoints from a
+// set of named checks. Every check is also served on its own sub path, e.g.
+// /readyz/storage.
+//
+// The aggregated endpoints accept two query parameters:
+//
+//	verbose        list the result of every check, even on success
+//	exclude=<name> skip a check, may be repeated
+package healthz
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// HealthChecker is a named health check.
+type HealthChecker interface {
+	Name() string
+	Check(req *http.Request) error
+}
+
+// PingHealthz returns true automatically when checked.
+var PingHealthz HealthChecker = ping{}
+
+type ping struct{}
+
+func (ping) Name() string { return "ping" }
+
+func (ping) Check(_ *http.Request) error { return nil }
+
+type healthzCheck struct {
+	name  string
+	check func(r *http.Request) error
+}
+
+// NamedCheck returns a health checker for the given name and function.
+func NamedCheck(name string, check func(r *http.Request) error) HealthChecker {
+	return &healthzCheck{name: name, check: check}
+}
+
+func (c *healthzCheck) Name() string { return c.name }
+
+func (c *healthzCheck) Check(r *http.Request) error { return c.check(r) }
+
+// InstallPathHandler registers the aggregated handler of checks on path and
+// the handler of each check on path/<name>.
+func InstallPathHandler(mux *http.ServeMux, path string, checks ...HealthChecker) {
+	log.Infof("Installing health checkers for (%v): %v", path, formatNames(checks))
+
+	mux.Handle("GET "+path, handleRootHealth(path, checks))
+	mux.Handle("GET "+path+"/{name}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, check := range checks {
+			if check.Name() != r.PathValue("name") {
+				continue
+			}
+
+			if err := check.Check(r); err != nil {
+				// As for the aggregated handler, the error is only logged.
+				log.Infof("%s check failed: %s/%s: %v", strings.TrimPrefix(path, "/"), path, check.Name(), err)
+				http.Error(w, "internal server error: reason withheld", http.StatusInternalServerError)
+
+				return
+			}
+
+			writePlain(w, http.StatusOK, "ok")
+
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+}
+
+// handleRootHealth returns the handler running all checks but the excluded ones.
+func handleRootHealth(path string, checks []HealthChecker) http.Handler {
+	name := strings.TrimPrefix(path, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		excluded := map[string]bool{}
+		for _, e := range r.URL.Query()["exclude"] {
+			excluded[strings.TrimSpace(e)] = true
+		}
+
+		var (
+			output      bytes.Buffer
+			failedNames []string
+		)
+
+		for _, check := range checks {
+			if excluded[check.Name()] {
+				delete(excluded, check.Name())
+				fmt.Fprintf(&output, "[+]%s excluded: ok\n", check.Name())
+
+				continue
+			}
+
+			if err := check.Check(r); err != nil {
+				// Don't include the error in the response, it may leak
+				// details of the server to unauthenticated clients.
+				fmt.Fprintf(&output, "[-]%s failed: reason withheld\n", check.Name())
+				failedNames = append(failedNames, check.Name())
+				log.Infof("%s check %q failed: %v", name, check.Name(), err)
+
+				continue
+			}
+
+			fmt.Fprintf(&output, "[+]%s ok\n", check.Name())
+		}
+
+		if len(excluded) != 0 {
+			// A typo in the exclusions of a probe would otherwise go
+			// unnoticed.
+			log.Warnf("cannot exclude some health checks, no health checks are installed matching %s",
+				formatQuoted(excluded))
+			fmt.Fprintf(&output, "warn: some health checks cannot be excluded: no matches for %s\n",
+				formatQuoted(excluded))
+		}
+
+		if len(failedNames) != 0 {
+			log.Infof("%s check failed: %v", name, failedNames)
+			writePlain(w, http.StatusInternalServerError, output.String()+name+" check failed")
+
+			return
+		}
+
+		if _, verbose := r.URL.Query()["verbose"]; verbose {
+			writePlain(w, http.StatusOK, output.String()+name+" check passed")
+
+			return
+		}
+
+		writePlain(w, http.StatusOK, "ok")
+	})
+}
+
+func writePlain(w http.ResponseWriter, code int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	fmt.Fprintln(w, body)
+}
+
+func formatNames(checks []HealthChecker) []string {
+	names := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, check.Name())
+	}
+
+	return names
+}
+
+func formatQuoted(names map[string]bool) string {
+	quoted := make([]string, 0, len(names))
+	for name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+
+	sort.Strings(quoted)
+
+	return strings.Join(quoted, ",")
+}
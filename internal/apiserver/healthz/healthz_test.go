@@ -0,0 +1,83 @@
+package healthz
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/log/logtest"
+)
+
+func TestMain(m *testing.M) {
+	log.Init(log.NewOptions())
+	os.Exit(m.Run())
+}
+
+func TestInstallPathHandler(t *testing.T) {
+	logs := logtest.Install(t, log.InfoLevel)
+
+	mux := http.NewServeMux()
+	InstallPathHandler(mux, "/readyz",
+		PingHealthz,
+		NamedCheck("storage", func(_ *http.Request) error { return errors.New("unreachable") }),
+	)
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{path: "/readyz", wantCode: http.StatusInternalServerError,
+			wantBody: "[+]ping ok\n[-]storage failed: reason withheld\nreadyz check failed\n"},
+		{path: "/readyz?exclude=storage", wantCode: http.StatusOK, wantBody: "ok\n"},
+		{path: "/readyz?exclude=storage&exclude=bogus&verbose", wantCode: http.StatusOK,
+			wantBody: "[+]ping ok\n[+]storage excluded: ok\n" +
+				"warn: some health checks cannot be excluded: no matches for \"bogus\"\nreadyz check passed\n"},
+		{path: "/readyz/ping", wantCode: http.StatusOK, wantBody: "ok\n"},
+		{path: "/readyz/storage", wantCode: http.StatusInternalServerError,
+			wantBody: "internal server error: reason withheld\n"},
+		{path: "/readyz/unknown", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+
+			if len(tt.wantBody) != 0 && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+
+			if tt.wantCode == http.StatusOK && !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+				t.Errorf("Content-Type = %q, want text/plain", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// The errors withheld from the clients are logged.
+	logs.AssertContainsMessage(t, "readyz check failed: /readyz/storage: unreachable")
+}
+
+func TestExcludeUnknownCheck(t *testing.T) {
+	logs := logtest.Install(t, log.WarnLevel)
+
+	mux := http.NewServeMux()
+	InstallPathHandler(mux, "/readyz", PingHealthz)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz?exclude=ping&exclude=bogus", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	logs.AssertContainsMessage(t, `cannot exclude some health checks, no health checks are installed matching "bogus"`)
+}
@@ -0,0 +1,98 @@
+// Package metrics defines the Prometheus metrics of flora-apiserver and the
+// handler serving them on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "apiserver_request_total",
+			Help: "Counter of apiserver requests broken out for each verb, resource and HTTP response code.",
+		},
+		[]string{"verb", "resource", "code"},
+	)
+
+	requestLatencies = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "apiserver_request_duration_seconds",
+			Help: "Response latency distribution in seconds for each verb, resource and HTTP response code.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.4, 0.6, 0.8, 1.0, 1.25, 1.5, 2, 3,
+				4, 5, 6, 8, 10, 15, 20, 30, 45, 60},
+		},
+		[]string{"verb", "resource", "code"},
+	)
+
+	requestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "apiserver_current_inflight_requests",
+			Help: "Number of requests currently being served, broken out by verb and resource.",
+		},
+		[]string{"verb", "resource"},
+	)
+
+	// Registry holds the metrics of the apiserver and the Go runtime and
+	// process metrics.
+	Registry = prometheus.NewRegistry()
+)
+
+func init() {
+	Registry.MustRegister(
+		requestCounter,
+		requestLatencies,
+		requestsInFlight,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// knownVerbs are the API verbs of the resource requests and the lower case
+// HTTP methods of the other requests.
+var knownVerbs = map[string]bool{
+	"get": true, "list": true, "watch": true, "create": true, "update": true, "patch": true, "delete": true,
+	"head": true, "post": true, "put": true, "options": true, "connect": true, "trace": true,
+}
+
+// CleanVerb returns verb when it is known and "other" otherwise, so that
+// the requests with custom methods do not create new series.
+func CleanVerb(verb string) string {
+	if knownVerbs[verb] {
+		return verb
+	}
+
+	return "other"
+}
+
+// RequestStarted records that a request started and returns the function to
+// call when it finished.
+func RequestStarted(verb, resource string) func() {
+	g := requestsInFlight.WithLabelValues(verb, resource)
+	g.Inc()
+
+	return g.Dec
+}
+
+// MonitorRequest records a finished request. Watches are long running, their
+// latency is not recorded.
+func MonitorRequest(verb, resource string, code int, elapsed time.Duration) {
+	codeLabel := strconv.Itoa(code)
+
+	requestCounter.WithLabelValues(verb, resource, codeLabel).Inc()
+
+	if verb != "watch" {
+		requestLatencies.WithLabelValues(verb, resource, codeLabel).Observe(elapsed.Seconds())
+	}
+}
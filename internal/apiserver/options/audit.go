@@ -0,0 +1,130 @@
+package options
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/audit"
+)
+
+// AuditOptions contains the options of the audit subsystem. Auditing is
+// enabled by configuring at least one backend.
+type AuditOptions struct {
+	PolicyFile string `json:"policy-file" mapstructure:"policy-file"`
+	LogPath    string `json:"log-path"    mapstructure:"log-path"`
+
+	WebhookURL          string        `json:"webhook-url"               mapstructure:"webhook-url"`
+	WebhookTimeout      time.Duration `json:"webhook-timeout"           mapstructure:"webhook-timeout"`
+	WebhookBatchBuffer  int           `json:"webhook-batch-buffer-size" mapstructure:"webhook-batch-buffer-size"`
+	WebhookBatchMaxSize int           `json:"webhook-batch-max-size"    mapstructure:"webhook-batch-max-size"`
+	WebhookBatchMaxWait time.Duration `json:"webhook-batch-max-wait"    mapstructure:"webhook-batch-max-wait"`
+}
+
+// NewAuditOptions creates an AuditOptions object with default parameters.
+func NewAuditOptions() *AuditOptions {
+	batch := audit.NewDefaultBatchConfig()
+
+	return &AuditOptions{
+		WebhookTimeout:      10 * time.Second,
+		WebhookBatchBuffer:  batch.BufferSize,
+		WebhookBatchMaxSize: batch.MaxBatchSize,
+		WebhookBatchMaxWait: batch.MaxBatchWait,
+	}
+}
+
+// Validate checks validation of AuditOptions.
+func (a *AuditOptions) Validate() []error {
+	var errs []error
+
+	if len(a.WebhookURL) != 0 {
+		u, err := url.Parse(a.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			errs = append(errs, fmt.Errorf("--audit.webhook-url %q must be an http or https URL", a.WebhookURL))
+		}
+	}
+
+	if a.WebhookTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("--audit.webhook-timeout %v must be greater than 0", a.WebhookTimeout))
+	}
+
+	if a.WebhookBatchBuffer <= 0 {
+		errs = append(errs, fmt.Errorf("--audit.webhook-batch-buffer-size %v must be greater than 0",
+			a.WebhookBatchBuffer))
+	}
+
+	if a.WebhookBatchMaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("--audit.webhook-batch-max-size %v must be greater than 0",
+			a.WebhookBatchMaxSize))
+	}
+
+	if a.WebhookBatchMaxWait <= 0 {
+		errs = append(errs, fmt.Errorf("--audit.webhook-batch-max-wait %v must be greater than 0",
+			a.WebhookBatchMaxWait))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for the audit subsystem to the specified FlagSet.
+func (a *AuditOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&a.PolicyFile, "audit.policy-file", a.PolicyFile, ""+
+		"Path to the file that defines the audit policy. Without it the metadata of every request is recorded.")
+	fs.StringVar(&a.LogPath, "audit.log-path", a.LogPath, ""+
+		"If set, all requests coming to the apiserver will be logged to this file. '-' means standard out.")
+	fs.StringVar(&a.WebhookURL, "audit.webhook-url", a.WebhookURL, ""+
+		"If set, audit events are sent in batches to this URL.")
+	fs.DurationVar(&a.WebhookTimeout, "audit.webhook-timeout", a.WebhookTimeout, ""+
+		"The timeout of the requests sent to the audit webhook.")
+	fs.IntVar(&a.WebhookBatchBuffer, "audit.webhook-batch-buffer-size", a.WebhookBatchBuffer, ""+
+		"The number of events buffered before they are sent to the webhook. Events are dropped when it is full.")
+	fs.IntVar(&a.WebhookBatchMaxSize, "audit.webhook-batch-max-size", a.WebhookBatchMaxSize, ""+
+		"The maximum number of events sent to the webhook in one request.")
+	fs.DurationVar(&a.WebhookBatchMaxWait, "audit.webhook-batch-max-wait", a.WebhookBatchMaxWait, ""+
+		"The maximum time an event waits before its batch is sent to the webhook.")
+}
+
+// Enabled returns true if at least one audit backend is configured.
+func (a *AuditOptions) Enabled() bool {
+	return len(a.LogPath) != 0 || len(a.WebhookURL) != 0
+}
+
+// NewPolicy loads the audit policy.
+func (a *AuditOptions) NewPolicy() (*audit.Policy, error) {
+	if len(a.PolicyFile) == 0 {
+		return audit.DefaultPolicy(), nil
+	}
+
+	return audit.LoadPolicy(a.PolicyFile)
+}
+
+// NewBackend creates the configured audit backends, or returns nil when
+// auditing is disabled.
+func (a *AuditOptions) NewBackend() (audit.Backend, error) {
+	var backends []audit.Backend
+
+	if len(a.LogPath) != 0 {
+		b, err := audit.NewFileBackend(a.LogPath)
+		if err != nil {
+			return nil, err
+		}
+
+		backends = append(backends, b)
+	}
+
+	if len(a.WebhookURL) != 0 {
+		backends = append(backends, audit.NewWebhookBackend(a.WebhookURL, a.WebhookTimeout, audit.BatchConfig{
+			BufferSize:   a.WebhookBatchBuffer,
+			MaxBatchSize: a.WebhookBatchMaxSize,
+			MaxBatchWait: a.WebhookBatchMaxWait,
+		}))
+	}
+
+	if len(backends) == 0 {
+		return nil, nil
+	}
+
+	return audit.Union(backends...), nil
+}
@@ -0,0 +1,117 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/authentication"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// AuthenticationOptions contains the options of the authentication of the
+// users. Authentication is enabled by configuring a password file.
+type AuthenticationOptions struct {
+	PasswordFile    string        `json:"password-file"     mapstructure:"password-file"`
+	SigningKeyFile  string        `json:"signing-key-file"  mapstructure:"signing-key-file"`
+	TokenTTL        time.Duration `json:"token-ttl"         mapstructure:"token-ttl"`
+	RefreshTokenTTL time.Duration `json:"refresh-token-ttl" mapstructure:"refresh-token-ttl"`
+	Anonymous       bool          `json:"anonymous"         mapstructure:"anonymous"`
+	AdminGroup      string        `json:"admin-group"       mapstructure:"admin-group"`
+}
+
+// NewAuthenticationOptions creates an AuthenticationOptions object with
+// default parameters.
+func NewAuthenticationOptions() *AuthenticationOptions {
+	return &AuthenticationOptions{
+		TokenTTL:        time.Hour,
+		RefreshTokenTTL: 24 * time.Hour,
+		Anonymous:       true,
+		AdminGroup:      "admins",
+	}
+}
+
+// Validate checks validation of AuthenticationOptions.
+func (a *AuthenticationOptions) Validate() []error {
+	var errs []error
+
+	if a.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("--authentication.token-ttl %v must be greater than 0", a.TokenTTL))
+	}
+
+	if a.RefreshTokenTTL < a.TokenTTL {
+		errs = append(errs, fmt.Errorf("--authentication.refresh-token-ttl %v must be greater than or equal to "+
+			"--authentication.token-ttl %v", a.RefreshTokenTTL, a.TokenTTL))
+	}
+
+	if len(a.SigningKeyFile) != 0 && !a.Enabled() {
+		errs = append(errs, fmt.Errorf("--authentication.signing-key-file requires --authentication.password-file"))
+	}
+
+	if len(a.AdminGroup) == 0 {
+		errs = append(errs, fmt.Errorf("--authentication.admin-group must not be empty"))
+	}
+
+	if !a.Anonymous && !a.Enabled() {
+		errs = append(errs, fmt.Errorf("--authentication.anonymous=false requires --authentication.password-file"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for the authentication to the specified FlagSet.
+func (a *AuthenticationOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&a.PasswordFile, "authentication.password-file", a.PasswordFile, ""+
+		"If set, the users of this file can log in to get a token. Each line holds a user as "+
+		"username:bcrypt-hash[:group1,group2], e.g. written by 'htpasswd -nB username'.")
+	fs.StringVar(&a.SigningKeyFile, "authentication.signing-key-file", a.SigningKeyFile, ""+
+		"Path to the file of the keys signing the tokens, one per line of at least 32 bytes. The first key "+
+		"signs the new tokens, the others still verify the tokens they signed. Without it, a random key is "+
+		"generated and the tokens are invalidated by a restart.")
+	fs.DurationVar(&a.TokenTTL, "authentication.token-ttl", a.TokenTTL, ""+
+		"The lifetime of the access tokens.")
+	fs.DurationVar(&a.RefreshTokenTTL, "authentication.refresh-token-ttl", a.RefreshTokenTTL, ""+
+		"The lifetime of the refresh tokens, after which the users must log in again.")
+	fs.BoolVar(&a.Anonymous, "authentication.anonymous", a.Anonymous, ""+
+		"Serve the requests without a token as the anonymous user. If false, only the login and the health "+
+		"checks are served without a token.")
+	fs.StringVar(&a.AdminGroup, "authentication.admin-group", a.AdminGroup, ""+
+		"The group of the users allowed to use the debug endpoints, e.g. /debug/loglevel to change the log "+
+		"levels. The debug endpoints are not served without authentication.")
+}
+
+// Enabled returns true if the users can log in.
+func (a *AuthenticationOptions) Enabled() bool {
+	return len(a.PasswordFile) != 0
+}
+
+// NewPasswords loads the password file.
+func (a *AuthenticationOptions) NewPasswords() (*authentication.Passwords, error) {
+	return authentication.LoadPasswordFile(a.PasswordFile)
+}
+
+// NewTokenIssuer creates the issuer of the tokens with the keys of the
+// signing key file, or with a random key.
+func (a *AuthenticationOptions) NewTokenIssuer() (*authentication.TokenIssuer, error) {
+	var (
+		keys [][]byte
+		err  error
+	)
+
+	if len(a.SigningKeyFile) != 0 {
+		keys, err = authentication.LoadSigningKeys(a.SigningKeyFile)
+	} else {
+		log.Warn("No --authentication.signing-key-file set, the tokens will be invalidated by a restart")
+
+		var key []byte
+		key, err = authentication.GenerateSigningKey()
+		keys = [][]byte{key}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return authentication.NewTokenIssuer(keys, a.TokenTTL, a.RefreshTokenTTL)
+}
@@ -0,0 +1,241 @@
+// Package options contains the flags used by flora-apiserver.
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// Options runs a flora api server.
+type Options struct {
+	InsecureServing *InsecureServingOptions `json:"insecure"       mapstructure:"insecure"`
+	SecureServing   *SecureServingOptions   `json:"secure"         mapstructure:"secure"`
+	Server          *ServerOptions          `json:"server"         mapstructure:"server"`
+	Storage         *StorageOptions         `json:"storage"        mapstructure:"storage"`
+	Authentication  *AuthenticationOptions  `json:"authentication" mapstructure:"authentication"`
+	Audit           *AuditOptions           `json:"audit"          mapstructure:"audit"`
+	Tracing         *TracingOptions         `json:"tracing"        mapstructure:"tracing"`
+	Log             *log.Options            `json:"log"            mapstructure:"log"`
+}
+
+// NewOptions creates a new Options object with default parameters.
+func NewOptions() *Options {
+	return &Options{
+		InsecureServing: NewInsecureServingOptions(),
+		SecureServing:   NewSecureServingOptions(),
+		Server:          NewServerOptions(),
+		Storage:         NewStorageOptions(),
+		Authentication:  NewAuthenticationOptions(),
+		Audit:           NewAuditOptions(),
+		Tracing:         NewTracingOptions(),
+		Log:             log.NewOptions(),
+	}
+}
+
+// Validate checks Options and return a slice of found errs.
+func (o *Options) Validate() []error {
+	var errs []error
+
+	errs = append(errs, o.InsecureServing.Validate()...)
+	errs = append(errs, o.SecureServing.Validate()...)
+	errs = append(errs, o.Server.Validate()...)
+	errs = append(errs, o.Storage.Validate()...)
+	errs = append(errs, o.Authentication.Validate()...)
+	errs = append(errs, o.Audit.Validate()...)
+	errs = append(errs, o.Tracing.Validate()...)
+	errs = append(errs, o.Log.Validate()...)
+
+	if o.InsecureServing.BindPort == 0 && !o.SecureServing.Enabled() {
+		errs = append(errs, fmt.Errorf("--insecure.bind-port=0 requires --secure.tls.cert-file, the apiserver "+
+			"would not serve any request"))
+	}
+
+	// The passwords and the tokens must not be sent in clear text over the
+	// network.
+	if o.Authentication.Enabled() && o.InsecureServing.BindPort != 0 && !o.InsecureServing.IsLoopback() {
+		errs = append(errs, fmt.Errorf("--authentication.password-file requires --insecure.bind-address %q to be "+
+			"a loopback address or --insecure.bind-port=0, serve the clients over TLS with --secure.tls.cert-file",
+			o.InsecureServing.BindAddress))
+	}
+
+	return errs
+}
+
+// AddFlags adds the flags of all options to fs.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.InsecureServing.AddFlags(fs)
+	o.SecureServing.AddFlags(fs)
+	o.Server.AddFlags(fs)
+	o.Storage.AddFlags(fs)
+	o.Authentication.AddFlags(fs)
+	o.Audit.AddFlags(fs)
+	o.Tracing.AddFlags(fs)
+	o.Log.AddFlags(fs)
+}
+
+func (o *Options) String() string {
+	data, _ := json.Marshal(o)
+
+	return string(data)
+}
+
+// InsecureServingOptions are for creating an unauthenticated, unauthorized, insecure port.
+type InsecureServingOptions struct {
+	BindAddress string `json:"bind-address" mapstructure:"bind-address"`
+	BindPort    int    `json:"bind-port"    mapstructure:"bind-port"`
+}
+
+// NewInsecureServingOptions creates an InsecureServingOptions object with default parameters.
+func NewInsecureServingOptions() *InsecureServingOptions {
+	return &InsecureServingOptions{
+		BindAddress: "127.0.0.1",
+		BindPort:    8080,
+	}
+}
+
+// Address returns the host:port the server listens on.
+func (s *InsecureServingOptions) Address() string {
+	return net.JoinHostPort(s.BindAddress, fmt.Sprint(s.BindPort))
+}
+
+// IsLoopback returns true if the server only listens to the local clients.
+func (s *InsecureServingOptions) IsLoopback() bool {
+	if s.BindAddress == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(s.BindAddress)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (s *InsecureServingOptions) Validate() []error {
+	var errs []error
+
+	if s.BindPort < 0 || s.BindPort > 65535 {
+		errs = append(
+			errs,
+			fmt.Errorf(
+				"--insecure.bind-port %v must be between 0 and 65535, inclusive. 0 for turning off insecure (HTTP) port",
+				s.BindPort,
+			),
+		)
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to features for a specific api server to the
+// specified FlagSet.
+func (s *InsecureServingOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.BindAddress, "insecure.bind-address", s.BindAddress, ""+
+		"The IP address on which to serve the --insecure.bind-port "+
+		"(set to 0.0.0.0 for all IPv4 interfaces and :: for all IPv6 interfaces).")
+	fs.IntVar(&s.BindPort, "insecure.bind-port", s.BindPort, ""+
+		"The port on which to serve unsecured, unauthenticated access.")
+}
+
+// SecureServingOptions contains the options of the HTTPS port, served when a
+// certificate is set.
+type SecureServingOptions struct {
+	BindAddress string `json:"bind-address" mapstructure:"bind-address"`
+	BindPort    int    `json:"bind-port"    mapstructure:"bind-port"`
+	// CertFile is the PEM file of the server certificate, followed by the
+	// intermediate certificates if any.
+	CertFile string `json:"tls-cert-file"        mapstructure:"tls-cert-file"`
+	// KeyFile is the PEM file of the private key of CertFile.
+	KeyFile string `json:"tls-private-key-file" mapstructure:"tls-private-key-file"`
+}
+
+// NewSecureServingOptions creates a SecureServingOptions object with default parameters.
+func NewSecureServingOptions() *SecureServingOptions {
+	return &SecureServingOptions{
+		BindAddress: "0.0.0.0",
+		BindPort:    8443,
+	}
+}
+
+// Address returns the host:port the server listens on.
+func (s *SecureServingOptions) Address() string {
+	return net.JoinHostPort(s.BindAddress, fmt.Sprint(s.BindPort))
+}
+
+// Enabled returns true if the HTTPS port is served.
+func (s *SecureServingOptions) Enabled() bool {
+	return len(s.CertFile) != 0
+}
+
+// Validate checks validation of SecureServingOptions.
+func (s *SecureServingOptions) Validate() []error {
+	var errs []error
+
+	if s.BindPort < 1 || s.BindPort > 65535 {
+		errs = append(errs, fmt.Errorf("--secure.bind-port %v must be between 1 and 65535, inclusive", s.BindPort))
+	}
+
+	if (len(s.CertFile) == 0) != (len(s.KeyFile) == 0) {
+		errs = append(errs, fmt.Errorf("--secure.tls.cert-file and --secure.tls.private-key-file must be set together"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for the HTTPS port to the specified FlagSet.
+func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.BindAddress, "secure.bind-address", s.BindAddress, ""+
+		"The IP address on which to serve the --secure.bind-port "+
+		"(set to 0.0.0.0 for all IPv4 interfaces and :: for all IPv6 interfaces).")
+	fs.IntVar(&s.BindPort, "secure.bind-port", s.BindPort, ""+
+		"The port on which to serve HTTPS, when --secure.tls.cert-file is set.")
+	fs.StringVar(&s.CertFile, "secure.tls.cert-file", s.CertFile, ""+
+		"File containing the x509 certificate for HTTPS, followed by the intermediate certificates if any. "+
+		"If set, the apiserver serves HTTPS on --secure.bind-port.")
+	fs.StringVar(&s.KeyFile, "secure.tls.private-key-file", s.KeyFile, ""+
+		"File containing the x509 private key matching --secure.tls.cert-file.")
+}
+
+// ServerOptions contains the options of the generic server behaviour.
+type ServerOptions struct {
+	ShutdownTimeout       time.Duration `json:"shutdown-timeout"        mapstructure:"shutdown-timeout"`
+	ShutdownDelayDuration time.Duration `json:"shutdown-delay-duration" mapstructure:"shutdown-delay-duration"`
+}
+
+// NewServerOptions creates a ServerOptions object with default parameters.
+func NewServerOptions() *ServerOptions {
+	return &ServerOptions{
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+// Validate checks validation of ServerOptions.
+func (s *ServerOptions) Validate() []error {
+	var errs []error
+
+	if s.ShutdownTimeout < 0 {
+		errs = append(errs, fmt.Errorf("--server.shutdown-timeout %v must be greater than or equal to 0", s.ShutdownTimeout))
+	}
+
+	if s.ShutdownDelayDuration < 0 {
+		errs = append(errs, fmt.Errorf("--server.shutdown-delay-duration %v must be greater than or equal to 0",
+			s.ShutdownDelayDuration))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for the generic server to the specified FlagSet.
+func (s *ServerOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&s.ShutdownTimeout, "server.shutdown-timeout", s.ShutdownTimeout, ""+
+		"The time to wait for in-flight requests to finish when the server shuts down.")
+	fs.DurationVar(&s.ShutdownDelayDuration, "server.shutdown-delay-duration", s.ShutdownDelayDuration, ""+
+		"Time to delay the shutdown after /readyz starts failing, so that load balancers stop sending "+
+		"new requests while the server still serves them.")
+}
@@ -0,0 +1,51 @@
+package options
+
+import (
+	"testing"
+)
+
+func TestValidateServing(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func(o *Options)
+		wantErr bool
+	}{
+		{name: "default", set: func(o *Options) {}},
+		{name: "password on loopback", set: func(o *Options) { o.Authentication.PasswordFile = "passwd" }},
+		{
+			name: "password on localhost",
+			set: func(o *Options) {
+				o.Authentication.PasswordFile = "passwd"
+				o.InsecureServing.BindAddress = "localhost"
+			},
+		},
+		{
+			name: "password on all interfaces",
+			set: func(o *Options) {
+				o.Authentication.PasswordFile = "passwd"
+				o.InsecureServing.BindAddress = "0.0.0.0"
+			},
+			wantErr: true,
+		},
+		{
+			name: "password over TLS only",
+			set: func(o *Options) {
+				o.Authentication.PasswordFile = "passwd"
+				o.InsecureServing.BindAddress = "0.0.0.0"
+				o.InsecureServing.BindPort = 0
+				o.SecureServing.CertFile, o.SecureServing.KeyFile = "tls.crt", "tls.key"
+			},
+		},
+		{name: "no port", set: func(o *Options) { o.InsecureServing.BindPort = 0 }, wantErr: true},
+		{name: "certificate without key", set: func(o *Options) { o.SecureServing.CertFile = "tls.crt" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		o := NewOptions()
+		tt.set(o)
+
+		if errs := o.Validate(); (len(errs) != 0) != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, want error %v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
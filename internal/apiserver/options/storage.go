@@ -0,0 +1,61 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Supported storage backends.
+const (
+	StorageBackendMemory = "memory"
+	StorageBackendBolt   = "bolt"
+)
+
+// StorageOptions contains the options of the storage the resources are persisted in.
+type StorageOptions struct {
+	Backend      string `json:"backend"        mapstructure:"backend"`
+	Path         string `json:"path"           mapstructure:"path"`
+	EventLogSize int    `json:"event-log-size" mapstructure:"event-log-size"`
+}
+
+// NewStorageOptions creates a StorageOptions object with default parameters.
+func NewStorageOptions() *StorageOptions {
+	return &StorageOptions{
+		Backend:      StorageBackendBolt,
+		Path:         "flora-apiserver.db",
+		EventLogSize: 1000,
+	}
+}
+
+// Validate checks validation of StorageOptions.
+func (s *StorageOptions) Validate() []error {
+	var errs []error
+
+	switch s.Backend {
+	case StorageBackendMemory:
+	case StorageBackendBolt:
+		if len(s.Path) == 0 {
+			errs = append(errs, fmt.Errorf("--storage.path is required by the %s backend", StorageBackendBolt))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("--storage.backend %q must be one of %s, %s",
+			s.Backend, StorageBackendMemory, StorageBackendBolt))
+	}
+
+	if s.EventLogSize <= 0 {
+		errs = append(errs, fmt.Errorf("--storage.event-log-size %v must be greater than 0", s.EventLogSize))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for the storage to the specified FlagSet.
+func (s *StorageOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.Backend, "storage.backend", s.Backend, ""+
+		"The storage backend for persistence. Options: 'memory', 'bolt'.")
+	fs.StringVar(&s.Path, "storage.path", s.Path, ""+
+		"The path of the database file used by the bolt storage backend.")
+	fs.IntVar(&s.EventLogSize, "storage.event-log-size", s.EventLogSize, ""+
+		"The number of changes the bolt storage backend keeps to resume watches.")
+}
@@ -0,0 +1,81 @@
+package options
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/pflag"
+
+	"github.com/hanzhuoxian/flora/pkg/trace"
+)
+
+// TracingOptions contains the options of the request tracing. Tracing is
+// enabled by configuring an exporter.
+type TracingOptions struct {
+	OTLPEndpoint  string  `json:"otlp-endpoint"  mapstructure:"otlp-endpoint"`
+	FilePath      string  `json:"file-path"      mapstructure:"file-path"`
+	SamplingRatio float64 `json:"sampling-ratio" mapstructure:"sampling-ratio"`
+}
+
+// NewTracingOptions creates a TracingOptions object with default parameters.
+func NewTracingOptions() *TracingOptions {
+	return &TracingOptions{
+		SamplingRatio: 1,
+	}
+}
+
+// Validate checks validation of TracingOptions.
+func (t *TracingOptions) Validate() []error {
+	var errs []error
+
+	if len(t.OTLPEndpoint) != 0 {
+		u, err := url.Parse(t.OTLPEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			errs = append(errs, fmt.Errorf("--tracing.otlp-endpoint %q must be an http or https URL", t.OTLPEndpoint))
+		}
+	}
+
+	if len(t.OTLPEndpoint) != 0 && len(t.FilePath) != 0 {
+		errs = append(errs, fmt.Errorf("--tracing.otlp-endpoint and --tracing.file-path are mutually exclusive"))
+	}
+
+	if t.SamplingRatio < 0 || t.SamplingRatio > 1 {
+		errs = append(errs, fmt.Errorf("--tracing.sampling-ratio %v must be between 0 and 1, inclusive", t.SamplingRatio))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for tracing to the specified FlagSet.
+func (t *TracingOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&t.OTLPEndpoint, "tracing.otlp-endpoint", t.OTLPEndpoint, ""+
+		"The base URL of an OpenTelemetry collector receiving the spans over OTLP/HTTP, "+
+		"e.g. http://localhost:4318.")
+	fs.StringVar(&t.FilePath, "tracing.file-path", t.FilePath, ""+
+		"If set, spans are appended to this file as OTLP JSON.")
+	fs.Float64Var(&t.SamplingRatio, "tracing.sampling-ratio", t.SamplingRatio, ""+
+		"The fraction of new traces that are recorded. Requests carrying a traceparent header "+
+		"follow the decision of the caller.")
+}
+
+// NewProvider creates the trace provider, or returns nil when tracing is disabled.
+func (t *TracingOptions) NewProvider(serviceName string) (*trace.Provider, error) {
+	var exporter trace.Exporter
+
+	switch {
+	case len(t.OTLPEndpoint) != 0:
+		exporter = trace.NewOTLPExporter(t.OTLPEndpoint, serviceName, nil)
+	case len(t.FilePath) != 0:
+		var err error
+		if exporter, err = trace.NewFileExporter(t.FilePath, serviceName); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	return trace.NewProvider(exporter, trace.ProviderConfig{
+		ServiceName:   serviceName,
+		SamplingRatio: t.SamplingRatio,
+	}), nil
+}
@@ -0,0 +1,380 @@
+// Package generic implements the REST semantics shared by all resources on top
+// of storage.Interface.
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/labels"
+	"github.com/hanzhuoxian/flora/pkg/log"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// Strategy lets a resource customize how objects are created and updated.
+type Strategy interface {
+	// PrepareForCreate is invoked on create before validation to normalize
+	// the object.
+	PrepareForCreate(ctx context.Context, obj metav1.Object)
+	// PrepareForUpdate is invoked on update before validation to normalize
+	// the object. old is the currently stored object.
+	PrepareForUpdate(ctx context.Context, obj, old metav1.Object)
+	// Validate returns the validation errors of obj, if any.
+	Validate(ctx context.Context, obj metav1.Object) []error
+}
+
+// Store implements the standard REST verbs of a resource.
+type Store struct {
+	// NewFunc returns a new, empty instance of the resource type.
+	NewFunc func() metav1.Object
+
+	// GroupVersionKind is the kind set on every object returned by the store.
+	GroupVersionKind scheme.GroupVersionKind
+
+	// QualifiedResource is the resource name used in keys and errors.
+	QualifiedResource scheme.GroupResource
+
+	// ShortNames are the short names advertised by discovery, e.g. "lease"
+	// for leases.
+	ShortNames []string
+
+	// Strategy customizes creates and updates.
+	Strategy Strategy
+
+	// TableConvertor adds the columns specific to the resource to the
+	// tables returned to clients. Tables have NAME and AGE columns if nil.
+	TableConvertor *TableConvertor
+
+	// Storage is the underlying storage.
+	Storage storage.Interface
+}
+
+// Kind returns the kind of the objects served by the store.
+func (e *Store) Kind() string {
+	return e.GroupVersionKind.Kind
+}
+
+func (e *Store) keyRoot() string {
+	return "/" + e.QualifiedResource.Resource + "/"
+}
+
+func (e *Store) key(name string) string {
+	return e.keyRoot() + name
+}
+
+// Get retrieves the object with the given name.
+func (e *Store) Get(ctx context.Context, name string) (metav1.Object, error) {
+	kv, err := e.Storage.Get(ctx, e.key(name))
+	if err != nil {
+		return nil, e.interpretError(err, name)
+	}
+
+	return e.decode(kv)
+}
+
+// List retrieves the objects of the resource. When opts.Limit is set the
+// returned ListMeta carries a continue token for the next page. The label
+// selector is applied to each page, so a page may hold fewer objects than the
+// limit.
+func (e *Store) List(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, metav1.ListMeta, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, metav1.ListMeta{}, apierrors.NewBadRequest(err.Error())
+	}
+
+	result, err := e.Storage.List(ctx, e.keyRoot(), storage.ListOptions{Limit: opts.Limit, Continue: opts.Continue})
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidContinue) {
+			return nil, metav1.ListMeta{}, apierrors.NewBadRequest(err.Error())
+		}
+
+		return nil, metav1.ListMeta{}, apierrors.NewInternalError(err)
+	}
+
+	objs := make([]metav1.Object, 0, len(result.Items))
+
+	for _, kv := range result.Items {
+		obj, err := e.decode(kv)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+
+		if !selector.Matches(obj.GetObjectMeta().Labels) {
+			continue
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, metav1.ListMeta{
+		ResourceVersion: formatResourceVersion(result.ResourceVersion),
+		Continue:        result.Continue,
+	}, nil
+}
+
+// WatchEvent is a change to an object of the resource.
+type WatchEvent struct {
+	Type   string
+	Object metav1.Object
+}
+
+// Watch sends the changes to the objects of the resource that happened after
+// opts.ResourceVersion. The channel is closed when ctx is done or the watcher
+// falls behind; clients resume from the last resource version they received.
+// Only the changes to objects matching the label selector are sent.
+func (e *Store) Watch(ctx context.Context, opts metav1.ListOptions) (<-chan WatchEvent, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	rv, err := parseResourceVersion(opts.ResourceVersion)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	events, err := e.Storage.Watch(ctx, e.keyRoot(), rv)
+	if err != nil {
+		if errors.Is(err, storage.ErrResourceVersionTooOld) {
+			return nil, apierrors.NewResourceExpired(fmt.Sprintf("too old resource version: %d", rv))
+		}
+
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	out := make(chan WatchEvent)
+
+	go func() {
+		defer close(out)
+
+		for event := range events {
+			obj, err := e.decode(&event.KeyValue)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Unable to decode watch event", "key", event.Key)
+
+				continue
+			}
+
+			if !selector.Matches(obj.GetObjectMeta().Labels) {
+				continue
+			}
+
+			select {
+			case out <- WatchEvent{Type: string(event.Type), Object: obj}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Create persists a new object. With a dry run the object is validated and
+// returned as it would be created, without persisting it.
+func (e *Store) Create(ctx context.Context, obj metav1.Object, opts metav1.CreateOptions) (metav1.Object, error) {
+	meta := obj.GetObjectMeta()
+	if len(meta.Name) == 0 {
+		return nil, apierrors.NewInvalid(e.GroupVersionKind.GroupKind(), "", []error{errors.New("metadata.name: Required value")})
+	}
+
+	meta.ResourceVersion = ""
+	meta.CreatedAt = time.Now()
+	meta.UpdatedAt = meta.CreatedAt
+
+	if e.Strategy != nil {
+		e.Strategy.PrepareForCreate(ctx, obj)
+
+		if errs := e.Strategy.Validate(ctx, obj); len(errs) != 0 {
+			return nil, apierrors.NewInvalid(e.GroupVersionKind.GroupKind(), meta.Name, errs)
+		}
+	}
+
+	if isDryRun(opts.DryRun) {
+		if _, err := e.Storage.Get(ctx, e.key(meta.Name)); !errors.Is(err, storage.ErrKeyNotFound) {
+			if err == nil {
+				err = storage.ErrKeyExists
+			}
+
+			return nil, e.interpretError(err, meta.Name)
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(e.GroupVersionKind)
+
+		return obj, nil
+	}
+
+	data, err := e.encode(obj)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	rv, err := e.Storage.Create(ctx, e.key(meta.Name), data)
+	if err != nil {
+		return nil, e.interpretError(err, meta.Name)
+	}
+
+	meta.ResourceVersion = formatResourceVersion(rv)
+	obj.GetObjectKind().SetGroupVersionKind(e.GroupVersionKind)
+
+	return obj, nil
+}
+
+// Update replaces the object with the given name. When the object carries a
+// resource version the update fails with a conflict if it is stale. With a
+// dry run the object is validated and returned as it would be updated,
+// without persisting it.
+func (e *Store) Update(ctx context.Context, name string, obj metav1.Object, opts metav1.UpdateOptions) (metav1.Object, error) {
+	meta := obj.GetObjectMeta()
+	if len(meta.Name) == 0 {
+		meta.Name = name
+	}
+
+	if meta.Name != name {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the name of the object (%s) does not match the name on the URL (%s)",
+			meta.Name, name))
+	}
+
+	expectedRV, err := parseResourceVersion(meta.ResourceVersion)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	old, err := e.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	meta.CreatedAt = old.GetObjectMeta().CreatedAt
+	meta.UpdatedAt = time.Now()
+
+	if e.Strategy != nil {
+		e.Strategy.PrepareForUpdate(ctx, obj, old)
+
+		if errs := e.Strategy.Validate(ctx, obj); len(errs) != 0 {
+			return nil, apierrors.NewInvalid(e.GroupVersionKind.GroupKind(), meta.Name, errs)
+		}
+	}
+
+	oldRV, _ := parseResourceVersion(old.GetObjectMeta().ResourceVersion)
+	if expectedRV == 0 {
+		expectedRV = oldRV
+	}
+
+	if isDryRun(opts.DryRun) {
+		if expectedRV != oldRV {
+			return nil, e.interpretError(storage.ErrResourceVersionConflict, name)
+		}
+
+		meta.ResourceVersion = old.GetObjectMeta().ResourceVersion
+		obj.GetObjectKind().SetGroupVersionKind(e.GroupVersionKind)
+
+		return obj, nil
+	}
+
+	data, err := e.encode(obj)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	rv, err := e.Storage.Update(ctx, e.key(name), data, expectedRV)
+	if err != nil {
+		return nil, e.interpretError(err, name)
+	}
+
+	meta.ResourceVersion = formatResourceVersion(rv)
+	obj.GetObjectKind().SetGroupVersionKind(e.GroupVersionKind)
+
+	return obj, nil
+}
+
+// Delete removes the object with the given name and returns its last state.
+// With a dry run the object is returned without being removed.
+func (e *Store) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) (metav1.Object, error) {
+	if isDryRun(opts.DryRun) {
+		return e.Get(ctx, name)
+	}
+
+	kv, err := e.Storage.Delete(ctx, e.key(name), 0)
+	if err != nil {
+		return nil, e.interpretError(err, name)
+	}
+
+	return e.decode(kv)
+}
+
+func (e *Store) encode(obj metav1.Object) ([]byte, error) {
+	// The resource version is assigned by the storage, never persist it.
+	meta := obj.GetObjectMeta()
+	rv := meta.ResourceVersion
+	meta.ResourceVersion = ""
+
+	defer func() { meta.ResourceVersion = rv }()
+
+	return json.Marshal(obj)
+}
+
+func (e *Store) decode(kv *storage.KeyValue) (metav1.Object, error) {
+	obj := e.NewFunc()
+	if err := json.Unmarshal(kv.Value, obj); err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to decode %s: %w", kv.Key, err))
+	}
+
+	obj.GetObjectMeta().ResourceVersion = formatResourceVersion(kv.ResourceVersion)
+	obj.GetObjectKind().SetGroupVersionKind(e.GroupVersionKind)
+
+	return obj, nil
+}
+
+func (e *Store) interpretError(err error, name string) error {
+	switch {
+	case errors.Is(err, storage.ErrKeyNotFound):
+		return apierrors.NewNotFound(e.QualifiedResource, name)
+	case errors.Is(err, storage.ErrKeyExists):
+		return apierrors.NewAlreadyExists(e.QualifiedResource, name)
+	case errors.Is(err, storage.ErrResourceVersionConflict):
+		return apierrors.NewConflict(e.QualifiedResource, name,
+			errors.New("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+
+	return apierrors.NewInternalError(err)
+}
+
+// isDryRun returns true if dryRun asks not to persist the changes.
+func isDryRun(dryRun []string) bool {
+	for _, v := range dryRun {
+		if v == metav1.DryRunAll {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatResourceVersion(rv uint64) string {
+	if rv == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(rv, 10)
+}
+
+func parseResourceVersion(rv string) (uint64, error) {
+	if len(rv) == 0 {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(rv, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid resource version %q", rv)
+	}
+
+	return version, nil
+}
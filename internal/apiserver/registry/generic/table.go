@@ -0,0 +1,111 @@
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// TableConvertor describes the columns of a resource shown between the NAME
+// and AGE columns of a table.
+type TableConvertor struct {
+	// Columns are the definitions of the columns of the resource.
+	Columns []metav1.TableColumnDefinition
+
+	// Cells returns the cells of obj, one per column.
+	Cells func(obj metav1.Object) []interface{}
+}
+
+var (
+	nameColumn = metav1.TableColumnDefinition{
+		Name:        "Name",
+		Type:        "string",
+		Format:      "name",
+		Description: "Name must be unique within a resource.",
+	}
+	ageColumn = metav1.TableColumnDefinition{
+		Name:        "Age",
+		Type:        "string",
+		Description: "The time since the object was created.",
+	}
+)
+
+// ConvertToTable returns the table of objs, each row carries its object.
+func (e *Store) ConvertToTable(objs []metav1.Object, listMeta metav1.ListMeta) (*metav1.Table, error) {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: "v1"},
+		ListMeta: listMeta,
+		Rows:     make([]metav1.TableRow, 0, len(objs)),
+	}
+
+	table.ColumnDefinitions = append(table.ColumnDefinitions, nameColumn)
+	if e.TableConvertor != nil {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, e.TableConvertor.Columns...)
+	}
+
+	table.ColumnDefinitions = append(table.ColumnDefinitions, ageColumn)
+
+	now := time.Now()
+
+	for _, obj := range objs {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		meta := obj.GetObjectMeta()
+		row := metav1.TableRow{Cells: []interface{}{meta.Name}, Object: data}
+
+		if e.TableConvertor != nil {
+			cells := e.TableConvertor.Cells(obj)
+			if len(cells) != len(e.TableConvertor.Columns) {
+				return nil, fmt.Errorf("%s has %d cells for %d columns", meta.Name, len(cells), len(e.TableConvertor.Columns))
+			}
+
+			row.Cells = append(row.Cells, cells...)
+		}
+
+		row.Cells = append(row.Cells, HumanDuration(now.Sub(meta.CreatedAt)))
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table, nil
+}
+
+// HumanDuration returns a short human readable form of d with up to two
+// units, e.g. 45s, 3m20s, 5h, 2d3h or 3y.
+func HumanDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+
+	switch {
+	case d < 0:
+		return "<invalid>"
+	case d < 2*time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < 10*time.Minute:
+		return withRemainder(d, time.Minute, "m", time.Second, "s")
+	case d < 3*time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 8*time.Hour:
+		return withRemainder(d, time.Hour, "h", time.Minute, "m")
+	case d < 2*day:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 8*day:
+		return withRemainder(d, day, "d", time.Hour, "h")
+	case d < 2*365*day:
+		return fmt.Sprintf("%dd", d/day)
+	}
+
+	return fmt.Sprintf("%dy", d/(365*day))
+}
+
+// withRemainder formats d in unit and, if not zero, the remainder in sub.
+func withRemainder(d, unit time.Duration, unitName string, sub time.Duration, subName string) string {
+	if rem := (d % unit) / sub; rem != 0 {
+		return fmt.Sprintf("%d%s%d%s", d/unit, unitName, rem, subName)
+	}
+
+	return fmt.Sprintf("%d%s", d/unit, unitName)
+}
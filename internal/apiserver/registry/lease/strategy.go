@@ -0,0 +1,72 @@
+// Package lease implements the storage of the Lease resource.
+package lease
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/generic"
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// NewStore returns the REST store of leases.
+func NewStore(s storage.Interface) *generic.Store {
+	return &generic.Store{
+		NewFunc:           func() metav1.Object { return &v1.Lease{} },
+		GroupVersionKind:  v1.SchemeGroupVersion.WithKind("Lease"),
+		QualifiedResource: v1.Resource("leases"),
+		Strategy:          strategy{},
+		TableConvertor:    tableConvertor,
+		Storage:           s,
+	}
+}
+
+// tableConvertor shows the holder of the leases, and the duration,
+// transitions and renew time in wide tables.
+var tableConvertor = &generic.TableConvertor{
+	Columns: []metav1.TableColumnDefinition{
+		{Name: "Holder", Type: "string", Description: "The identity of the holder of the lease."},
+		{Name: "Duration", Type: "string", Priority: 1, Description: "How long candidates wait to force acquire the lease."},
+		{Name: "Transitions", Type: "integer", Priority: 1, Description: "The number of transitions between holders."},
+		{Name: "Renewed", Type: "string", Priority: 1, Description: "The time since the holder last renewed the lease."},
+	},
+	Cells: func(obj metav1.Object) []interface{} {
+		lease, _ := obj.(*v1.Lease)
+
+		renewed := "<none>"
+		if lease.Spec.RenewTime != nil {
+			renewed = generic.HumanDuration(time.Since(*lease.Spec.RenewTime))
+		}
+
+		return []interface{}{
+			lease.Spec.HolderIdentity,
+			(time.Duration(lease.Spec.LeaseDurationSeconds) * time.Second).String(),
+			lease.Spec.LeaseTransitions,
+			renewed,
+		}
+	},
+}
+
+type strategy struct{}
+
+func (strategy) PrepareForCreate(ctx context.Context, obj metav1.Object) {}
+
+func (strategy) PrepareForUpdate(ctx context.Context, obj, old metav1.Object) {}
+
+func (strategy) Validate(ctx context.Context, obj metav1.Object) []error {
+	lease, _ := obj.(*v1.Lease)
+
+	var errs []error
+	if lease.Spec.LeaseDurationSeconds < 0 {
+		errs = append(errs, errors.New("spec.leaseDurationSeconds: must be greater than or equal to 0"))
+	}
+
+	if lease.Spec.LeaseTransitions < 0 {
+		errs = append(errs, errors.New("spec.leaseTransitions: must be greater than or equal to 0"))
+	}
+
+	return errs
+}
@@ -0,0 +1,36 @@
+// Package request holds the per-request information the apiserver filters
+// store in the request context.
+package request
+
+import "context"
+
+type key int
+
+const (
+	requestInfoKey key = iota
+	userKey
+)
+
+// WithRequestInfo returns a copy of parent in which the request info value is set.
+func WithRequestInfo(parent context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(parent, requestInfoKey, info)
+}
+
+// RequestInfoFrom returns the value of the request info key on the ctx.
+func RequestInfoFrom(ctx context.Context) (*RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey).(*RequestInfo)
+
+	return info, ok
+}
+
+// WithUser returns a copy of parent in which the user value is set.
+func WithUser(parent context.Context, user *UserInfo) context.Context {
+	return context.WithValue(parent, userKey, user)
+}
+
+// UserFrom returns the value of the user key on the ctx.
+func UserFrom(ctx context.Context) (*UserInfo, bool) {
+	user, ok := ctx.Value(userKey).(*UserInfo)
+
+	return user, ok
+}
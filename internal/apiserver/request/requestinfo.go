@@ -0,0 +1,100 @@
+package request
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RequestInfo holds the information parsed from the http request.
+type RequestInfo struct {
+	// IsResourceRequest indicates whether the request targets an API
+	// resource, as opposed to paths like /healthz or /metrics.
+	IsResourceRequest bool
+	// Path is the URL path of the request.
+	Path string
+	// Verb is the API verb (get, list, watch, create, update, delete) for
+	// resource requests and the lower case HTTP method otherwise.
+	Verb string
+
+	APIVersion string
+	Resource   string
+	Name       string
+}
+
+// RequestInfoFactory parses the RequestInfo of the requests to the API
+// served under /<version>/<resource>[/<name>].
+type RequestInfoFactory struct {
+	// APIVersions are the versions served by the apiserver, e.g. v1.
+	APIVersions []string
+}
+
+// NewRequestInfo returns the information of r. Requests that are not
+// resource requests are still described by their path and verb.
+func (f *RequestInfoFactory) NewRequestInfo(r *http.Request) *RequestInfo {
+	info := &RequestInfo{
+		Path: r.URL.Path,
+		Verb: strings.ToLower(r.Method),
+	}
+
+	parts := splitPath(r.URL.Path)
+	if len(parts) < 2 || len(parts) > 3 || !f.servesVersion(parts[0]) {
+		return info
+	}
+
+	info.IsResourceRequest = true
+	info.APIVersion = parts[0]
+	info.Resource = parts[1]
+
+	if len(parts) == 3 {
+		info.Name = parts[2]
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case len(info.Name) != 0:
+			info.Verb = "get"
+		case isWatch(r):
+			info.Verb = "watch"
+		default:
+			info.Verb = "list"
+		}
+	case http.MethodPost:
+		info.Verb = "create"
+	case http.MethodPut:
+		info.Verb = "update"
+	case http.MethodPatch:
+		info.Verb = "patch"
+	case http.MethodDelete:
+		info.Verb = "delete"
+	}
+
+	return info
+}
+
+func (f *RequestInfoFactory) servesVersion(version string) bool {
+	for _, v := range f.APIVersions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWatch(r *http.Request) bool {
+	watch, _ := strconv.ParseBool(r.URL.Query().Get("watch"))
+
+	return watch
+}
+
+// splitPath returns the segments of path, ignoring leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,10 @@
+package request
+
+// Anonymous is the name of the user of unauthenticated requests.
+const Anonymous = "system:anonymous"
+
+// UserInfo describes the user that made a request.
+type UserInfo struct {
+	Name   string   `json:"username"`
+	Groups []string `json:"groups,omitempty"`
+}
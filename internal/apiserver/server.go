@@ -0,0 +1,308 @@
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/audit"
+	"github.com/hanzhuoxian/flora/internal/apiserver/authentication"
+	"github.com/hanzhuoxian/flora/internal/apiserver/endpoints"
+	"github.com/hanzhuoxian/flora/internal/apiserver/filters"
+	"github.com/hanzhuoxian/flora/internal/apiserver/healthz"
+	"github.com/hanzhuoxian/flora/internal/apiserver/metrics"
+	"github.com/hanzhuoxian/flora/internal/apiserver/options"
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/generic"
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/lease"
+	"github.com/hanzhuoxian/flora/internal/apiserver/request"
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage"
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage/bolt"
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage/memory"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/trace"
+	"github.com/hanzhuoxian/flora/pkg/version"
+)
+
+type apiServer struct {
+	options      *options.Options
+	storage      storage.Interface
+	auditBackend audit.Backend
+	tracer       *trace.Provider
+	mux          *http.ServeMux
+	// resources are the names of the served resources.
+	resources []string
+	// passwords and tokenIssuer are set when the users log in.
+	passwords   *authentication.Passwords
+	tokenIssuer *authentication.TokenIssuer
+	handler     http.Handler
+
+	// shuttingDown is set once the server starts its graceful shutdown, it
+	// fails /readyz so that load balancers stop sending new requests.
+	shuttingDown atomic.Bool
+}
+
+func createAPIServer(opts *options.Options) (*apiServer, error) {
+	tracer, err := opts.Tracing.NewProvider("flora-apiserver")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		passwords   *authentication.Passwords
+		tokenIssuer *authentication.TokenIssuer
+	)
+
+	if opts.Authentication.Enabled() {
+		if passwords, err = opts.Authentication.NewPasswords(); err != nil {
+			return nil, err
+		}
+
+		if tokenIssuer, err = opts.Authentication.NewTokenIssuer(); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := newStorage(opts.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if tracer != nil {
+		trace.SetProvider(tracer)
+		trace.SetErrorHandler(func(err error) { log.Warnf("Tracing: %v", err) })
+
+		store = storage.WithTracing(store)
+	}
+
+	s := &apiServer{
+		options:     opts,
+		storage:     store,
+		tracer:      tracer,
+		mux:         http.NewServeMux(),
+		passwords:   passwords,
+		tokenIssuer: tokenIssuer,
+	}
+
+	s.installAPIs()
+	s.installHealthChecks()
+
+	if err := s.buildHandlerChain(); err != nil {
+		store.Close()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// buildHandlerChain wraps the API handlers with the filters applied to every
+// request. The last filter wrapped runs first.
+func (s *apiServer) buildHandlerChain() error {
+	handler := http.Handler(s.mux)
+	failedHandler := filters.Unauthorized()
+
+	if s.options.Audit.Enabled() {
+		policy, err := s.options.Audit.NewPolicy()
+		if err != nil {
+			return err
+		}
+
+		if s.auditBackend, err = s.options.Audit.NewBackend(); err != nil {
+			return err
+		}
+
+		handler = filters.WithAudit(handler, policy, s.auditBackend)
+		failedHandler = filters.WithFailedAuthenticationAudit(failedHandler, policy, s.auditBackend)
+	}
+
+	if s.tokenIssuer != nil {
+		handler = filters.WithAuthentication(handler, s.tokenIssuer, failedHandler, s.options.Authentication.Anonymous,
+			[]string{authentication.LoginPath, authentication.RefreshPath, "/healthz", "/livez", "/readyz"})
+	}
+
+	handler = filters.WithMetrics(handler, s.resources)
+
+	if s.tracer != nil {
+		handler = filters.WithTracing(handler)
+	}
+
+	handler = filters.WithRequestInfo(handler, &request.RequestInfoFactory{
+		APIVersions: []string{v1.SchemeGroupVersion.Version},
+	})
+	handler = filters.WithRequestID(handler)
+
+	s.handler = handler
+
+	return nil
+}
+
+// newStorage creates the storage backend selected by opts.
+func newStorage(opts *options.StorageOptions) (storage.Interface, error) {
+	switch opts.Backend {
+	case options.StorageBackendMemory:
+		return memory.New(), nil
+	case options.StorageBackendBolt:
+		return bolt.New(bolt.Config{Path: opts.Path, EventLogSize: opts.EventLogSize})
+	}
+
+	return nil, fmt.Errorf("unknown storage backend %q", opts.Backend)
+}
+
+// installAPIs registers every resource served by the apiserver.
+func (s *apiServer) installAPIs() {
+	prefix := "/" + v1.SchemeGroupVersion.Version
+
+	stores := []*generic.Store{
+		lease.NewStore(s.storage),
+	}
+
+	for _, store := range stores {
+		endpoints.InstallREST(s.mux, prefix, store)
+		s.resources = append(s.resources, store.QualifiedResource.Resource)
+	}
+
+	endpoints.InstallDiscovery(s.mux, prefix, v1.SchemeGroupVersion.String(), stores...)
+
+	s.mux.HandleFunc("GET /version", func(w http.ResponseWriter, _ *http.Request) {
+		endpoints.WriteObject(w, http.StatusOK, version.Get())
+	})
+	s.mux.Handle("GET /metrics", metrics.Handler())
+
+	if s.tokenIssuer == nil {
+		log.Info("The debug endpoints are not served without authentication")
+
+		return
+	}
+
+	authentication.InstallLogin(s.mux, s.passwords, s.tokenIssuer)
+	// The debug endpoints change the behavior of the server, they are
+	// reserved to the administrators.
+	s.mux.Handle("/debug/loglevel", filters.WithGroup(log.LevelHandler(), s.options.Authentication.AdminGroup))
+}
+
+// installHealthChecks registers /healthz, /livez and /readyz. Liveness only
+// tells whether the process works, readiness whether it can serve requests.
+func (s *apiServer) installHealthChecks() {
+	storageCheck := healthz.NamedCheck("storage", func(_ *http.Request) error {
+		return s.storage.ReadinessCheck()
+	})
+	shutdownCheck := healthz.NamedCheck("shutdown", func(_ *http.Request) error {
+		if s.shuttingDown.Load() {
+			return errors.New("the apiserver is shutting down")
+		}
+
+		return nil
+	})
+
+	readyChecks := []healthz.HealthChecker{healthz.PingHealthz, storageCheck, shutdownCheck}
+
+	if s.tokenIssuer != nil {
+		readyChecks = append(readyChecks, healthz.NamedCheck("signing-keys", func(_ *http.Request) error {
+			return s.tokenIssuer.Check()
+		}))
+	}
+
+	healthz.InstallPathHandler(s.mux, "/healthz", healthz.PingHealthz, storageCheck)
+	healthz.InstallPathHandler(s.mux, "/livez", healthz.PingHealthz)
+	healthz.InstallPathHandler(s.mux, "/readyz", readyChecks...)
+}
+
+// Run serves the API until ctx is done, then shuts the server down gracefully.
+func (s *apiServer) Run(ctx context.Context) error {
+	defer s.storage.Close()
+
+	if s.auditBackend != nil {
+		defer s.auditBackend.Shutdown()
+	}
+
+	if s.tracer != nil {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			if err := s.tracer.Shutdown(ctx); err != nil {
+				log.Warnf("Unable to flush the pending spans: %v", err)
+			}
+		}()
+	}
+
+	var servers []*http.Server
+
+	errCh := make(chan error, 2)
+
+	if s.options.InsecureServing.BindPort != 0 {
+		server := &http.Server{
+			Addr:     s.options.InsecureServing.Address(),
+			Handler:  s.handler,
+			ErrorLog: log.StdErrLogger(),
+		}
+		servers = append(servers, server)
+
+		go func() {
+			log.Infof("Start to listening the incoming requests on http address: %s", server.Addr)
+
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+	}
+
+	if secure := s.options.SecureServing; secure.Enabled() {
+		server := &http.Server{
+			Addr:      secure.Address(),
+			Handler:   s.handler,
+			ErrorLog:  log.StdErrLogger(),
+			TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		}
+		servers = append(servers, server)
+
+		go func() {
+			log.Infof("Start to listening the incoming requests on https address: %s", server.Addr)
+
+			err := server.ListenAndServeTLS(secure.CertFile, secure.KeyFile)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		shutdown(servers, s.options.Server.ShutdownTimeout)
+
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down the apiserver")
+	s.shuttingDown.Store(true)
+
+	if delay := s.options.Server.ShutdownDelayDuration; delay > 0 {
+		log.Infof("Waiting %v for load balancers to notice the apiserver is not ready", delay)
+		time.Sleep(delay)
+	}
+
+	return shutdown(servers, s.options.Server.ShutdownTimeout)
+}
+
+// shutdown shuts the servers down gracefully, waiting at most timeout for
+// the requests in flight.
+func shutdown(servers []*http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var errUnknownSchema = errors.New("database schema is newer than this apiserver supports")
+
+// migration upgrades the database schema by one version.
+type migration struct {
+	description string
+	migrate     func(tx *bolt.Tx) error
+}
+
+// migrations holds the schema migrations in order: migrations[i] upgrades
+// the schema from version i to version i+1. Never change or remove an
+// existing migration, append a new one instead.
+var migrations = []migration{
+	{
+		description: "create the meta, objects and events buckets",
+		migrate: func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{metaBucket, objectsBucket, eventsBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+}
+
+// migrate applies the pending migrations, each one in its own transaction.
+func migrate(db *bolt.DB) error {
+	for {
+		done, err := migrateOnce(db)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+func migrateOnce(db *bolt.DB) (bool, error) {
+	done := false
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		version := schemaVersion(tx)
+		if version > uint64(len(migrations)) {
+			return errUnknownSchema
+		}
+
+		if version == uint64(len(migrations)) {
+			done = true
+
+			return nil
+		}
+
+		m := migrations[version]
+		if err := m.migrate(tx); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", version+1, m.description, err)
+		}
+
+		return tx.Bucket(metaBucket).Put(schemaVersionKey, encodeUint64(version+1))
+	})
+
+	return done, err
+}
+
+func schemaVersion(tx *bolt.Tx) uint64 {
+	b := tx.Bucket(metaBucket)
+	if b == nil {
+		return 0
+	}
+
+	data := b.Get(schemaVersionKey)
+	if len(data) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(data)
+}
@@ -0,0 +1,384 @@
+// Package bolt implements storage.Interface on top of an embedded bbolt
+// database, so that resources survive apiserver restarts.
+//
+// Every write runs in a single bolt transaction which checks the resource
+// version, stores the value and appends an event to the event log. The event
+// log keeps the last EventLogSize events so that watches can be resumed from
+// a resource version obtained before a restart.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage"
+)
+
+var (
+	metaBucket    = []byte("meta")
+	objectsBucket = []byte("objects")
+	eventsBucket  = []byte("events")
+
+	resourceVersionKey = []byte("resource-version")
+	schemaVersionKey   = []byte("schema-version")
+)
+
+// Config configures the bolt storage.
+type Config struct {
+	// Path is the file of the database. It is created if it does not exist.
+	Path string
+	// EventLogSize is the number of events kept to resume watches.
+	EventLogSize int
+}
+
+type store struct {
+	db           *bolt.DB
+	eventLogSize int
+
+	// writeLock serializes writes with their notification, so that watchers
+	// receive events in resource version order.
+	writeLock   sync.Mutex
+	broadcaster *storage.Broadcaster
+}
+
+var _ storage.Interface = &store{}
+
+// New opens the database at c.Path and migrates it to the latest schema.
+func New(c Config) (storage.Interface, error) {
+	if dir := filepath.Dir(c.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := bolt.Open(c.Path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", c.Path, err)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("migrate %s: %w", c.Path, err)
+	}
+
+	return &store{
+		db:           db,
+		eventLogSize: c.EventLogSize,
+		broadcaster:  storage.NewBroadcaster(),
+	}, nil
+}
+
+// record is the value stored in the objects bucket.
+type record struct {
+	ResourceVersion uint64          `json:"rv"`
+	Value           json.RawMessage `json:"value"`
+}
+
+// event is the value stored in the events bucket, keyed by resource version.
+type event struct {
+	Type  storage.EventType `json:"type"`
+	Key   string            `json:"key"`
+	Value json.RawMessage   `json:"value"`
+}
+
+func (s *store) Create(ctx context.Context, key string, value []byte) (uint64, error) {
+	return s.write(key, func(current *record) (*record, storage.EventType, error) {
+		if current != nil {
+			return nil, "", storage.ErrKeyExists
+		}
+
+		return &record{Value: value}, storage.Added, nil
+	})
+}
+
+func (s *store) Get(ctx context.Context, key string) (*storage.KeyValue, error) {
+	var kv *storage.KeyValue
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		r, err := getRecord(tx.Bucket(objectsBucket), key)
+		if err != nil {
+			return err
+		}
+
+		if r == nil {
+			return storage.ErrKeyNotFound
+		}
+
+		kv = &storage.KeyValue{Key: key, Value: r.Value, ResourceVersion: r.ResourceVersion}
+
+		return nil
+	})
+
+	return kv, err
+}
+
+func (s *store) List(ctx context.Context, prefix string, opts storage.ListOptions) (*storage.ListResult, error) {
+	start := prefix
+	if len(opts.Continue) != 0 {
+		var err error
+		if start, err = storage.DecodeContinue(opts.Continue, prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &storage.ListResult{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		result.ResourceVersion = currentResourceVersion(tx)
+
+		c := tx.Bucket(objectsBucket).Cursor()
+		for k, v := c.Seek([]byte(start)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+			if opts.Limit > 0 && int64(len(result.Items)) == opts.Limit {
+				result.Continue = storage.EncodeContinue(string(k))
+
+				break
+			}
+
+			var r record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("decode %s: %w", k, err)
+			}
+
+			result.Items = append(result.Items, &storage.KeyValue{
+				Key:             string(k),
+				Value:           r.Value,
+				ResourceVersion: r.ResourceVersion,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *store) Update(ctx context.Context, key string, value []byte, expectedRV uint64) (uint64, error) {
+	return s.write(key, func(current *record) (*record, storage.EventType, error) {
+		if current == nil {
+			return nil, "", storage.ErrKeyNotFound
+		}
+
+		if expectedRV != 0 && current.ResourceVersion != expectedRV {
+			return nil, "", storage.ErrResourceVersionConflict
+		}
+
+		return &record{Value: value}, storage.Modified, nil
+	})
+}
+
+func (s *store) Delete(ctx context.Context, key string, expectedRV uint64) (*storage.KeyValue, error) {
+	var deleted *storage.KeyValue
+
+	_, err := s.write(key, func(current *record) (*record, storage.EventType, error) {
+		if current == nil {
+			return nil, "", storage.ErrKeyNotFound
+		}
+
+		if expectedRV != 0 && current.ResourceVersion != expectedRV {
+			return nil, "", storage.ErrResourceVersionConflict
+		}
+
+		deleted = &storage.KeyValue{Key: key, Value: current.Value, ResourceVersion: current.ResourceVersion}
+
+		return nil, storage.Deleted, nil
+	})
+
+	return deleted, err
+}
+
+func (s *store) Watch(ctx context.Context, prefix string, resourceVersion uint64) (<-chan storage.Event, error) {
+	return s.broadcaster.Watch(ctx, prefix, resourceVersion, s.history)
+}
+
+func (s *store) ReadinessCheck() error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(metaBucket) == nil {
+			return errors.New("database is not initialized")
+		}
+
+		return nil
+	})
+}
+
+func (s *store) Close() error {
+	s.broadcaster.Close()
+
+	return s.db.Close()
+}
+
+// write runs fn in a transaction with the currently stored record of key.
+// fn returns the new record, or nil to delete the key, and the type of the
+// event to record.
+func (s *store) write(
+	key string,
+	fn func(current *record) (*record, storage.EventType, error),
+) (uint64, error) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	var e storage.Event
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		objects := tx.Bucket(objectsBucket)
+
+		current, err := getRecord(objects, key)
+		if err != nil {
+			return err
+		}
+
+		updated, eventType, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		rv := currentResourceVersion(tx) + 1
+		if err := tx.Bucket(metaBucket).Put(resourceVersionKey, encodeUint64(rv)); err != nil {
+			return err
+		}
+
+		e = storage.Event{Type: eventType, KeyValue: storage.KeyValue{Key: key, ResourceVersion: rv}}
+
+		if updated == nil {
+			e.Value = current.Value
+			if err := objects.Delete([]byte(key)); err != nil {
+				return err
+			}
+		} else {
+			updated.ResourceVersion = rv
+			e.Value = updated.Value
+
+			data, err := json.Marshal(updated)
+			if err != nil {
+				return err
+			}
+
+			if err := objects.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+
+		return s.appendEvent(tx, e)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.broadcaster.Notify(e)
+
+	return e.ResourceVersion, nil
+}
+
+// appendEvent adds e to the event log and drops the events that exceed the
+// size of the log.
+func (s *store) appendEvent(tx *bolt.Tx, e storage.Event) error {
+	events := tx.Bucket(eventsBucket)
+
+	data, err := json.Marshal(event{Type: e.Type, Key: e.Key, Value: e.Value})
+	if err != nil {
+		return err
+	}
+
+	if err := events.Put(encodeUint64(e.ResourceVersion), data); err != nil {
+		return err
+	}
+
+	if s.eventLogSize <= 0 || e.ResourceVersion <= uint64(s.eventLogSize) {
+		return nil
+	}
+
+	oldest := e.ResourceVersion - uint64(s.eventLogSize)
+
+	c := events.Cursor()
+	for k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) <= oldest; k, _ = c.Next() {
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// history returns the events after since.
+func (s *store) history(since uint64) ([]storage.Event, error) {
+	var events []storage.Event
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		current := currentResourceVersion(tx)
+		if since >= current {
+			return nil
+		}
+
+		c := tx.Bucket(eventsBucket).Cursor()
+
+		// Every write produces an event, so the log is complete if it reaches
+		// back to the version right after since.
+		k, _ := c.First()
+		if k == nil || binary.BigEndian.Uint64(k) > since+1 {
+			return storage.ErrResourceVersionTooOld
+		}
+
+		for k, v := c.Seek(encodeUint64(since + 1)); k != nil; k, v = c.Next() {
+			var e event
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+
+			events = append(events, storage.Event{
+				Type: e.Type,
+				KeyValue: storage.KeyValue{
+					Key:             e.Key,
+					Value:           e.Value,
+					ResourceVersion: binary.BigEndian.Uint64(k),
+				},
+			})
+		}
+
+		return nil
+	})
+
+	return events, err
+}
+
+func getRecord(b *bolt.Bucket, key string) (*record, error) {
+	data := b.Get([]byte(key))
+	if data == nil {
+		return nil, nil
+	}
+
+	r := &record{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", key, err)
+	}
+
+	return r, nil
+}
+
+func currentResourceVersion(tx *bolt.Tx) uint64 {
+	data := tx.Bucket(metaBucket).Get(resourceVersionKey)
+	if len(data) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(data)
+}
+
+func encodeUint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+
+	return b
+}
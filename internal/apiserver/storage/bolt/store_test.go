@@ -0,0 +1,183 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage"
+)
+
+func newTestStore(t *testing.T, path string) storage.Interface {
+	t.Helper()
+
+	s, err := New(Config{Path: path, EventLogSize: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	return s
+}
+
+func TestStoreCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+	defer s.Close()
+
+	rv, err := s.Create(ctx, "/leases/a", []byte(`"v1"`))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := s.Create(ctx, "/leases/a", []byte(`"v1"`)); !errors.Is(err, storage.ErrKeyExists) {
+		t.Errorf("Create existing key: got %v, want %v", err, storage.ErrKeyExists)
+	}
+
+	updated, err := s.Update(ctx, "/leases/a", []byte(`"v2"`), rv)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if _, err := s.Update(ctx, "/leases/a", []byte(`"v3"`), rv); !errors.Is(err, storage.ErrResourceVersionConflict) {
+		t.Errorf("Update with stale version: got %v, want %v", err, storage.ErrResourceVersionConflict)
+	}
+
+	if _, err := s.Delete(ctx, "/leases/a", rv); !errors.Is(err, storage.ErrResourceVersionConflict) {
+		t.Errorf("Delete with stale version: got %v, want %v", err, storage.ErrResourceVersionConflict)
+	}
+
+	kv, err := s.Delete(ctx, "/leases/a", updated)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if string(kv.Value) != `"v2"` {
+		t.Errorf("Delete returned %s, want %s", kv.Value, `"v2"`)
+	}
+
+	if _, err := s.Get(ctx, "/leases/a"); !errors.Is(err, storage.ErrKeyNotFound) {
+		t.Errorf("Get deleted key: got %v, want %v", err, storage.ErrKeyNotFound)
+	}
+}
+
+func TestStoreListPagination(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+	defer s.Close()
+
+	for _, key := range []string{"/leases/c", "/leases/a", "/users/a", "/leases/b"} {
+		if _, err := s.Create(ctx, key, []byte(`{}`)); err != nil {
+			t.Fatalf("Create %s: %v", key, err)
+		}
+	}
+
+	var keys []string
+
+	opts := storage.ListOptions{Limit: 2}
+
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatalf("List did not terminate")
+		}
+
+		result, err := s.List(ctx, "/leases/", opts)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+
+		if result.ResourceVersion != 4 {
+			t.Errorf("List resource version = %d, want 4", result.ResourceVersion)
+		}
+
+		for _, kv := range result.Items {
+			keys = append(keys, kv.Key)
+		}
+
+		if len(result.Continue) == 0 {
+			break
+		}
+
+		opts.Continue = result.Continue
+	}
+
+	want := []string{"/leases/a", "/leases/b", "/leases/c"}
+	if len(keys) != len(want) {
+		t.Fatalf("List returned %v, want %v", keys, want)
+	}
+
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("List returned %v, want %v", keys, want)
+		}
+	}
+
+	if _, err := s.List(ctx, "/users/", opts); !errors.Is(err, storage.ErrInvalidContinue) {
+		t.Errorf("List with foreign continue token: got %v, want %v", err, storage.ErrInvalidContinue)
+	}
+}
+
+func TestStoreWatchAcrossRestart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	s := newTestStore(t, path)
+
+	rv, err := s.Create(ctx, "/leases/a", []byte(`"v1"`))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := s.Update(ctx, "/leases/a", []byte(`"v2"`), 0); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if _, err := s.Create(ctx, "/users/a", []byte(`{}`)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s = newTestStore(t, path)
+	defer s.Close()
+
+	events, err := s.Watch(ctx, "/leases/", rv)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	if _, err := s.Delete(ctx, "/leases/a", 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	want := []storage.Event{
+		{Type: storage.Modified, KeyValue: storage.KeyValue{Key: "/leases/a", Value: []byte(`"v2"`), ResourceVersion: 2}},
+		{Type: storage.Deleted, KeyValue: storage.KeyValue{Key: "/leases/a", Value: []byte(`"v2"`), ResourceVersion: 4}},
+	}
+
+	for _, w := range want {
+		select {
+		case e := <-events:
+			if e.Type != w.Type || e.Key != w.Key || string(e.Value) != string(w.Value) || e.ResourceVersion != w.ResourceVersion {
+				t.Errorf("got event %+v, want %+v", e, w)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %+v", w)
+		}
+	}
+
+	// The event log keeps the last 3 events, so the one right after rv is
+	// gone once another write happens.
+	if _, err := s.Create(ctx, "/leases/b", []byte(`{}`)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := s.Watch(ctx, "/leases/", rv); !errors.Is(err, storage.ErrResourceVersionTooOld) {
+		t.Errorf("Watch from compacted version: got %v, want %v", err, storage.ErrResourceVersionTooOld)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// watchChanSize is the number of events buffered per watcher. A watcher
+// that falls further behind is closed and has to resume from the last
+// resource version it received.
+const watchChanSize = 100
+
+// Broadcaster fans out the events written by a storage implementation to
+// its watchers.
+type Broadcaster struct {
+	lock     sync.Mutex
+	watchers map[*watcher]struct{}
+}
+
+type watcher struct {
+	prefix string
+	input  chan Event
+}
+
+// NewBroadcaster returns a Broadcaster without watchers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{watchers: map[*watcher]struct{}{}}
+}
+
+// Watch registers a watcher for prefix. history is called once the watcher
+// is registered and must return the events after resourceVersion, so that no
+// event is lost between reading the history and receiving new events.
+func (b *Broadcaster) Watch(
+	ctx context.Context,
+	prefix string,
+	resourceVersion uint64,
+	history func(since uint64) ([]Event, error),
+) (<-chan Event, error) {
+	w := &watcher{prefix: prefix, input: make(chan Event, watchChanSize)}
+
+	b.lock.Lock()
+	b.watchers[w] = struct{}{}
+	b.lock.Unlock()
+
+	var events []Event
+
+	if resourceVersion != 0 {
+		var err error
+
+		events, err = history(resourceVersion)
+		if err != nil {
+			b.remove(w)
+
+			return nil, err
+		}
+	}
+
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+		defer b.remove(w)
+
+		last := resourceVersion
+
+		send := func(e Event) bool {
+			if e.ResourceVersion <= last && last != 0 {
+				return true
+			}
+
+			last = e.ResourceVersion
+
+			if !strings.HasPrefix(e.Key, prefix) {
+				return true
+			}
+
+			select {
+			case out <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, e := range events {
+			if !send(e) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case e, ok := <-w.input:
+				if !ok || !send(e) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Notify sends e to every watcher. It never blocks: watchers whose buffer is
+// full are closed.
+func (b *Broadcaster) Notify(e Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for w := range b.watchers {
+		if !strings.HasPrefix(e.Key, w.prefix) {
+			continue
+		}
+
+		select {
+		case w.input <- e:
+		default:
+			delete(b.watchers, w)
+			close(w.input)
+		}
+	}
+}
+
+// Close closes all watchers.
+func (b *Broadcaster) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for w := range b.watchers {
+		delete(b.watchers, w)
+		close(w.input)
+	}
+}
+
+func (b *Broadcaster) remove(w *watcher) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.watchers[w]; ok {
+		delete(b.watchers, w)
+		close(w.input)
+	}
+}
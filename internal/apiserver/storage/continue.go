@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidContinue is returned when a continue token can not be decoded.
+var ErrInvalidContinue = errors.New("invalid continue token")
+
+type continueToken struct {
+	StartKey string `json:"start"`
+}
+
+// EncodeContinue returns the opaque continue token of a list that resumes at key.
+func EncodeContinue(key string) string {
+	data, _ := json.Marshal(continueToken{StartKey: key})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeContinue returns the key a list resumes at. The key must start with prefix.
+func DecodeContinue(token, prefix string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", ErrInvalidContinue
+	}
+
+	var c continueToken
+	if err := json.Unmarshal(data, &c); err != nil {
+		return "", ErrInvalidContinue
+	}
+
+	if len(c.StartKey) < len(prefix) || c.StartKey[:len(prefix)] != prefix {
+		return "", ErrInvalidContinue
+	}
+
+	return c.StartKey, nil
+}
@@ -0,0 +1,98 @@
+// Package storage defines the key/value storage used by the apiserver to
+// persist resources. Every write bumps a global resource version which is
+// used for optimistic concurrency control and to resume watches.
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrKeyNotFound is returned when the key does not exist.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrKeyExists is returned when creating a key that already exists.
+	ErrKeyExists = errors.New("key exists")
+	// ErrResourceVersionConflict is returned when the resource version of a
+	// conditional write does not match the stored one.
+	ErrResourceVersionConflict = errors.New("resource version conflict")
+	// ErrResourceVersionTooOld is returned when a watch starts from a
+	// resource version that is no longer in the event log.
+	ErrResourceVersionTooOld = errors.New("resource version too old")
+)
+
+// KeyValue is a stored value together with the resource version of its last write.
+type KeyValue struct {
+	Key             string
+	Value           []byte
+	ResourceVersion uint64
+}
+
+// EventType defines the possible types of events.
+type EventType string
+
+// Types of the events sent by Watch.
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+)
+
+// Event represents a single change to a key. For Deleted events Value holds
+// the last value of the key and ResourceVersion the version of the delete.
+type Event struct {
+	Type EventType
+	KeyValue
+}
+
+// ListOptions restricts the keys returned by List.
+type ListOptions struct {
+	// Limit is the maximum number of keys to return. Zero means no limit.
+	Limit int64
+	// Continue is the token returned by a previous, truncated List.
+	Continue string
+}
+
+// ListResult is a page of keys returned by List.
+type ListResult struct {
+	Items []*KeyValue
+	// ResourceVersion is the resource version of the store when the list was read.
+	ResourceVersion uint64
+	// Continue is set when more keys are available.
+	Continue string
+}
+
+// Interface offers a common interface for object marshaling/unmarshaling operations and
+// hides all the storage-related operations behind it.
+type Interface interface {
+	// Create adds a new key and returns the resource version it was written at.
+	// It returns ErrKeyExists if the key already exists.
+	Create(ctx context.Context, key string, value []byte) (uint64, error)
+
+	// Get returns the value stored at key, or ErrKeyNotFound.
+	Get(ctx context.Context, key string) (*KeyValue, error)
+
+	// List returns the values whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string, opts ListOptions) (*ListResult, error)
+
+	// Update replaces the value stored at key. If expectedRV is not zero the
+	// write only succeeds when it matches the stored resource version,
+	// otherwise ErrResourceVersionConflict is returned.
+	Update(ctx context.Context, key string, value []byte, expectedRV uint64) (uint64, error)
+
+	// Delete removes key and returns the last stored value. If expectedRV is
+	// not zero the delete only succeeds when it matches the stored resource
+	// version.
+	Delete(ctx context.Context, key string, expectedRV uint64) (*KeyValue, error)
+
+	// Watch sends the changes of the keys starting with prefix that happened
+	// after resourceVersion. A zero resourceVersion only sends new changes.
+	// The channel is closed when ctx is done or the watcher falls behind.
+	Watch(ctx context.Context, prefix string, resourceVersion uint64) (<-chan Event, error)
+
+	// ReadinessCheck returns an error if the storage can not serve requests.
+	ReadinessCheck() error
+
+	// Close releases the resources held by the storage.
+	Close() error
+}
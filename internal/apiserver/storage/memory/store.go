@@ -0,0 +1,192 @@
+// Package memory implements storage.Interface in memory. All data is lost
+// when the process exits.
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage"
+)
+
+// eventLogSize is the number of events kept to resume watches.
+const eventLogSize = 1000
+
+type store struct {
+	lock            sync.RWMutex
+	items           map[string]*storage.KeyValue
+	resourceVersion uint64
+
+	// events holds the most recent events, oldest first.
+	events      []storage.Event
+	broadcaster *storage.Broadcaster
+}
+
+var _ storage.Interface = &store{}
+
+// New returns an empty in-memory storage.
+func New() storage.Interface {
+	return &store{
+		items:       map[string]*storage.KeyValue{},
+		broadcaster: storage.NewBroadcaster(),
+	}
+}
+
+func (s *store) Create(ctx context.Context, key string, value []byte) (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.items[key]; ok {
+		return 0, storage.ErrKeyExists
+	}
+
+	s.resourceVersion++
+	s.items[key] = &storage.KeyValue{Key: key, Value: value, ResourceVersion: s.resourceVersion}
+	s.record(storage.Added, s.items[key])
+
+	return s.resourceVersion, nil
+}
+
+func (s *store) Get(ctx context.Context, key string) (*storage.KeyValue, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	kv, ok := s.items[key]
+	if !ok {
+		return nil, storage.ErrKeyNotFound
+	}
+
+	return copyKeyValue(kv), nil
+}
+
+func (s *store) List(ctx context.Context, prefix string, opts storage.ListOptions) (*storage.ListResult, error) {
+	start := prefix
+	if len(opts.Continue) != 0 {
+		var err error
+		if start, err = storage.DecodeContinue(opts.Continue, prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var kvs []*storage.KeyValue
+
+	for key, kv := range s.items {
+		if strings.HasPrefix(key, prefix) && key >= start {
+			kvs = append(kvs, kv)
+		}
+	}
+
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+	result := &storage.ListResult{ResourceVersion: s.resourceVersion}
+
+	if opts.Limit > 0 && int64(len(kvs)) > opts.Limit {
+		result.Continue = storage.EncodeContinue(kvs[opts.Limit].Key)
+		kvs = kvs[:opts.Limit]
+	}
+
+	for _, kv := range kvs {
+		result.Items = append(result.Items, copyKeyValue(kv))
+	}
+
+	return result, nil
+}
+
+func (s *store) Update(ctx context.Context, key string, value []byte, expectedRV uint64) (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	kv, ok := s.items[key]
+	if !ok {
+		return 0, storage.ErrKeyNotFound
+	}
+
+	if expectedRV != 0 && kv.ResourceVersion != expectedRV {
+		return 0, storage.ErrResourceVersionConflict
+	}
+
+	s.resourceVersion++
+	s.items[key] = &storage.KeyValue{Key: key, Value: value, ResourceVersion: s.resourceVersion}
+	s.record(storage.Modified, s.items[key])
+
+	return s.resourceVersion, nil
+}
+
+func (s *store) Delete(ctx context.Context, key string, expectedRV uint64) (*storage.KeyValue, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	kv, ok := s.items[key]
+	if !ok {
+		return nil, storage.ErrKeyNotFound
+	}
+
+	if expectedRV != 0 && kv.ResourceVersion != expectedRV {
+		return nil, storage.ErrResourceVersionConflict
+	}
+
+	s.resourceVersion++
+	delete(s.items, key)
+	s.record(storage.Deleted, &storage.KeyValue{Key: key, Value: kv.Value, ResourceVersion: s.resourceVersion})
+
+	return copyKeyValue(kv), nil
+}
+
+func (s *store) Watch(ctx context.Context, prefix string, resourceVersion uint64) (<-chan storage.Event, error) {
+	return s.broadcaster.Watch(ctx, prefix, resourceVersion, s.history)
+}
+
+func (s *store) ReadinessCheck() error {
+	return nil
+}
+
+func (s *store) Close() error {
+	s.broadcaster.Close()
+
+	return nil
+}
+
+// record appends an event to the event log and notifies the watchers. The
+// caller must hold the write lock.
+func (s *store) record(eventType storage.EventType, kv *storage.KeyValue) {
+	e := storage.Event{Type: eventType, KeyValue: *copyKeyValue(kv)}
+
+	s.events = append(s.events, e)
+	if len(s.events) > eventLogSize {
+		s.events = s.events[len(s.events)-eventLogSize:]
+	}
+
+	s.broadcaster.Notify(e)
+}
+
+// history returns the events after since.
+func (s *store) history(since uint64) ([]storage.Event, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if since > s.resourceVersion {
+		return nil, nil
+	}
+
+	// Every write produces an event, so the log is complete if it reaches
+	// back to the version right after since.
+	if since < s.resourceVersion && (len(s.events) == 0 || s.events[0].ResourceVersion > since+1) {
+		return nil, storage.ErrResourceVersionTooOld
+	}
+
+	i := sort.Search(len(s.events), func(i int) bool { return s.events[i].ResourceVersion > since })
+
+	return append([]storage.Event(nil), s.events[i:]...), nil
+}
+
+func copyKeyValue(kv *storage.KeyValue) *storage.KeyValue {
+	value := make([]byte, len(kv.Value))
+	copy(value, kv.Value)
+
+	return &storage.KeyValue{Key: kv.Key, Value: value, ResourceVersion: kv.ResourceVersion}
+}
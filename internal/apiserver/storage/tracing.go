@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hanzhuoxian/flora/pkg/trace"
+)
+
+type tracing struct {
+	Interface
+}
+
+// WithTracing returns an Interface recording a span for every operation of delegate.
+func WithTracing(delegate Interface) Interface {
+	return &tracing{Interface: delegate}
+}
+
+func startSpan(ctx context.Context, op, key string) (context.Context, *trace.Span) {
+	return trace.Start(ctx, "storage."+op, trace.WithAttributes(trace.String("storage.key", key)))
+}
+
+// endSpan ends span, recording err unless it is an expected outcome such as
+// a missing key.
+func endSpan(span *trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+func (t *tracing) Create(ctx context.Context, key string, value []byte) (uint64, error) {
+	ctx, span := startSpan(ctx, "Create", key)
+	rv, err := t.Interface.Create(ctx, key, value)
+	endSpan(span, err)
+
+	return rv, err
+}
+
+func (t *tracing) Get(ctx context.Context, key string) (*KeyValue, error) {
+	ctx, span := startSpan(ctx, "Get", key)
+	kv, err := t.Interface.Get(ctx, key)
+	endSpan(span, err)
+
+	return kv, err
+}
+
+func (t *tracing) List(ctx context.Context, prefix string, opts ListOptions) (*ListResult, error) {
+	ctx, span := startSpan(ctx, "List", prefix)
+	result, err := t.Interface.List(ctx, prefix, opts)
+
+	if result != nil {
+		span.SetAttributes(trace.Int("storage.count", len(result.Items)))
+	}
+
+	endSpan(span, err)
+
+	return result, err
+}
+
+func (t *tracing) Update(ctx context.Context, key string, value []byte, expectedRV uint64) (uint64, error) {
+	ctx, span := startSpan(ctx, "Update", key)
+	rv, err := t.Interface.Update(ctx, key, value, expectedRV)
+	endSpan(span, err)
+
+	return rv, err
+}
+
+func (t *tracing) Delete(ctx context.Context, key string, expectedRV uint64) (*KeyValue, error) {
+	ctx, span := startSpan(ctx, "Delete", key)
+	kv, err := t.Interface.Delete(ctx, key, expectedRV)
+	endSpan(span, err)
+
+	return kv, err
+}
+
+// Watch only traces setting up the watch, the watch itself is long running.
+func (t *tracing) Watch(ctx context.Context, prefix string, resourceVersion uint64) (<-chan Event, error) {
+	_, span := startSpan(ctx, "Watch", prefix)
+	events, err := t.Interface.Watch(ctx, prefix, resourceVersion)
+	endSpan(span, err)
+
+	return events, err
+}
@@ -0,0 +1,154 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// LastAppliedConfigAnnotation is the annotation holding the configuration
+// of the last apply, which tells the fields to remove when they are removed
+// from the configuration.
+const LastAppliedConfigAnnotation = "floractl.flora.io/last-applied-configuration"
+
+// The operations reported by Applier.
+const (
+	OperationCreated    = "created"
+	OperationConfigured = "configured"
+	OperationUnchanged  = "unchanged"
+)
+
+// Applier creates the objects of manifests or updates them with a three-way
+// merge.
+type Applier struct {
+	client         rest.Interface
+	dryRunStrategy resource.DryRunStrategy
+}
+
+// NewApplier returns an Applier sending its changes through client, unless
+// dryRunStrategy is resource.DryRunClient.
+func NewApplier(client rest.Interface, dryRunStrategy resource.DryRunStrategy) *Applier {
+	return &Applier{client: client, dryRunStrategy: dryRunStrategy}
+}
+
+// Apply creates or updates the object of info. It returns the object as
+// created or updated, or as it would be with a dry run, and the operation
+// performed.
+func (a *Applier) Apply(ctx context.Context, info resource.Info) (map[string]interface{}, string, error) {
+	modified, err := withLastApplied(info.Object)
+	if err != nil {
+		return nil, "", err
+	}
+
+	helper := resource.NewHelper(a.client, info.Resource).DryRun(a.dryRunStrategy == resource.DryRunServer)
+
+	live, err := helper.Get(ctx, info.Name)
+	if apierrors.IsNotFound(err) {
+		if a.dryRunStrategy == resource.DryRunClient {
+			return modified, OperationCreated, nil
+		}
+
+		obj, err := helper.Create(ctx, modified)
+
+		return obj, OperationCreated, err
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	original, err := lastApplied(live)
+	if err != nil {
+		return nil, "", err
+	}
+
+	patched := resource.ThreeWayMerge(original, modified, live)
+	if reflect.DeepEqual(patched, live) {
+		return live, OperationUnchanged, nil
+	}
+
+	if a.dryRunStrategy == resource.DryRunClient {
+		return patched, OperationConfigured, nil
+	}
+
+	obj, err := helper.Replace(ctx, info.Name, patched)
+
+	return obj, OperationConfigured, err
+}
+
+// withLastApplied returns a copy of obj annotated with its configuration.
+func withLastApplied(obj map[string]interface{}) (map[string]interface{}, error) {
+	config, err := setLastApplied(obj, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return setLastApplied(obj, string(data))
+}
+
+// setLastApplied returns a deep copy of obj with the last applied
+// configuration annotation set to config, or removed if config is empty.
+func setLastApplied(obj map[string]interface{}, config string) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+
+	metadata, _ := out["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		out["metadata"] = metadata
+	}
+
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+
+	if len(config) == 0 {
+		delete(annotations, LastAppliedConfigAnnotation)
+
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+
+		return out, nil
+	}
+
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+
+	annotations[LastAppliedConfigAnnotation] = config
+
+	return out, nil
+}
+
+// lastApplied returns the configuration of the last apply of obj, nil if
+// obj was never applied.
+func lastApplied(obj map[string]interface{}) (map[string]interface{}, error) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+
+	config, _ := annotations[LastAppliedConfigAnnotation].(string)
+	if len(config) == 0 {
+		return nil, nil
+	}
+
+	var original map[string]interface{}
+	if err := json.Unmarshal([]byte(config), &original); err != nil {
+		return nil, err
+	}
+
+	return original, nil
+}
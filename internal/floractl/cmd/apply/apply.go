@@ -0,0 +1,152 @@
+// Package apply implements the floractl apply command.
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/printers"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// ApplyOptions contains the input to the apply command.
+type ApplyOptions struct {
+	PrintFlags      *printers.PrintFlags
+	FilenameOptions resource.FilenameOptions
+
+	DryRunStrategy resource.DryRunStrategy
+
+	client  *rest.RESTClient
+	infos   []resource.Info
+	printer printers.ResourcePrinter
+
+	options.IOStreams
+}
+
+var (
+	applyLong = templates.LongDesc(`
+		Apply a configuration to a resource by file name or stdin.
+
+		The resource is created if it doesn't exist yet, otherwise it is updated with a
+		three-way merge of the last applied configuration, the live object and the new
+		configuration: the fields removed from the configuration since the last apply are
+		removed from the object and the fields set by other clients are kept. The last
+		applied configuration is saved in the floractl.flora.io/last-applied-configuration
+		annotation.
+
+		JSON and YAML formats are accepted, a file may hold several objects.`)
+
+	applyExample = templates.Examples(`
+		# Apply the configuration in lease.yaml
+		floractl apply -f ./lease.yaml
+
+		# Apply the configurations of the directory and its subdirectories
+		floractl apply -R -f ./manifests
+
+		# Apply the JSON passed into stdin
+		cat lease.json | floractl apply -f -
+
+		# Check the configuration with the server without changing anything
+		floractl apply -f ./lease.yaml --dry-run=server`)
+)
+
+// NewApplyOptions returns an initialized ApplyOptions.
+func NewApplyOptions(ioStreams options.IOStreams) *ApplyOptions {
+	return &ApplyOptions{PrintFlags: printers.NewPrintFlags(), IOStreams: ioStreams}
+}
+
+// NewCmdApply returns the apply command.
+func NewCmdApply(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewApplyOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "apply (-f FILENAME | -R -f DIRECTORY)",
+		DisableFlagsInUseLine: true,
+		Short:                 "Apply a configuration to a resource by file name or stdin",
+		Long:                  applyLong,
+		Example:               applyExample,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd, f); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	o.FilenameOptions.AddFlags(cmd, "that contain the configuration to apply")
+	resource.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+// Complete reads the manifests and resolves their objects through the
+// discovery of the server.
+func (o *ApplyOptions) Complete(cmd *cobra.Command, f options.RESTClientGetter) error {
+	var err error
+
+	if o.DryRunStrategy, err = resource.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+
+	if err := o.FilenameOptions.Validate(); err != nil {
+		return err
+	}
+
+	if o.printer, err = o.PrintFlags.ToPrinter(""); err != nil {
+		return err
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(cmd.Context(), o.client)
+	if err != nil {
+		return err
+	}
+
+	o.infos, err = o.FilenameOptions.Infos(cmd.Context(), mapper, o.In)
+
+	return err
+}
+
+// Run applies the objects one by one and prints the result of each. The
+// objects that fail do not stop the others, their errors are returned
+// together.
+func (o *ApplyOptions) Run(ctx context.Context) error {
+	applier := NewApplier(o.client, o.DryRunStrategy)
+
+	var errs []error
+
+	for _, info := range o.infos {
+		obj, operation, err := applier.Apply(ctx, info)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error when applying %s from %q: %w", info, info.Source, err))
+			continue
+		}
+
+		if p, ok := o.printer.(*printers.NamePrinter); ok {
+			p.Operation = operation + o.DryRunStrategy.Suffix()
+		}
+
+		if err := o.printer.PrintObj(obj, o.Out); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,109 @@
+package apply
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cmdtesting "github.com/hanzhuoxian/flora/internal/floractl/cmd/testing"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// run runs floractl apply with args against server.
+func run(server *httptest.Server, args ...string) (string, error) {
+	out, _, err := cmdtesting.Run(context.Background(), server, []cmdtesting.NewCmdFunc{NewCmdApply}, append([]string{"apply"}, args...)...)
+
+	return out, err
+}
+
+func TestApply(t *testing.T) {
+	server, store := cmdtesting.NewServer(t)
+	dir := t.TempDir()
+
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	leases := write("leases.yaml", `apiVersion: apiserver/v1
+kind: Lease
+metadata:
+  name: leader
+  labels:
+    env: prod
+spec:
+  holderIdentity: node-a
+---
+apiVersion: apiserver/v1
+kind: Lease
+metadata:
+  name: scheduler
+spec:
+  holderIdentity: node-b
+`)
+	changed := write("leader.yaml", `apiVersion: apiserver/v1
+kind: Lease
+metadata:
+  name: leader
+spec:
+  holderIdentity: node-c
+`)
+	unknown := write("unknown.yaml", "apiVersion: apiserver/v1\nkind: User\nmetadata:\n  name: a\n")
+
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{"-f", leases, "--dry-run=client"}, want: "lease/leader created (dry run)\nlease/scheduler created (dry run)\n"},
+		{args: []string{"-f", leases, "--dry-run=server"}, want: "lease/leader created (server dry run)\nlease/scheduler created (server dry run)\n"},
+		{args: []string{"-f", leases}, want: "lease/leader created\nlease/scheduler created\n"},
+		{args: []string{"-f", leases}, want: "lease/leader unchanged\nlease/scheduler unchanged\n"},
+		{args: []string{"-f", changed, "--dry-run=server"}, want: "lease/leader configured (server dry run)\n"},
+		{args: []string{"-f", changed, "-o", "jsonpath={.spec.holderIdentity}"}, want: "node-c"},
+		{args: []string{"-f", changed}, want: "lease/leader unchanged\n"},
+		{args: []string{"-f", unknown}, wantErr: true},
+		{args: []string{"-f", leases, "--dry-run=maybe"}, wantErr: true},
+		{args: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		out, err := run(server, tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("apply %s: error = %v, want error %v", strings.Join(tt.args, " "), err, tt.wantErr)
+			continue
+		}
+
+		if out != tt.want {
+			t.Errorf("apply %s = %q, want %q", strings.Join(tt.args, " "), out, tt.want)
+		}
+	}
+
+	// The label removed from the configuration was removed from the lease.
+	obj, err := store.Get(context.Background(), "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := obj.(*v1.Lease); l.Spec.HolderIdentity != "node-c" || len(l.Labels) != 0 {
+		t.Errorf("leader = %+v, want holder node-c without labels", l)
+	}
+
+	// Fields set by other clients are kept by apply.
+	obj.(*v1.Lease).Spec.LeaseTransitions = 4
+	if _, err := store.Update(context.Background(), "leader", obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := run(server, "-f", changed, "-o", "jsonpath={.spec.leaseTransitions}"); err != nil || out != "4" {
+		t.Errorf("apply after an update = %q, %v, want the transitions kept", out, err)
+	}
+}
@@ -6,7 +6,18 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/apply"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/config"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/create"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/delete"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/describe"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/diff"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/edit"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/get"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/login"
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/version"
 	cliflag "github.com/hanzhuoxian/flora/pkg/cli/flag"
+	clioptions "github.com/hanzhuoxian/flora/pkg/cli/options"
 	"github.com/hanzhuoxian/flora/pkg/log"
 )
 
@@ -22,6 +33,8 @@ func NewCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 		Short: "floractl is a command line tool for managing the Flora project",
 		Long:  "floractl is a command line tool for managing the Flora project",
 		Run:   runHelp,
+		// The errors are enough to tell what went wrong, --help shows the usage.
+		SilenceUsage: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return initProfiling()
 		},
@@ -37,7 +50,21 @@ func NewCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 
 	addProfilingFlags(flags)
 
-	// configFlags := clioptions.NewConfigFlags(true)
+	configFlags := clioptions.NewConfigFlags(true)
+	configFlags.AddFlags(flags)
+
+	ioStreams := clioptions.IOStreams{In: in, Out: out, ErrOut: err}
+
+	cmds.AddCommand(create.NewCmdCreate(configFlags, ioStreams))
+	cmds.AddCommand(get.NewCmdGet(configFlags, ioStreams))
+	cmds.AddCommand(describe.NewCmdDescribe(configFlags, ioStreams))
+	cmds.AddCommand(edit.NewCmdEdit(configFlags, ioStreams))
+	cmds.AddCommand(diff.NewCmdDiff(configFlags, ioStreams))
+	cmds.AddCommand(apply.NewCmdApply(configFlags, ioStreams))
+	cmds.AddCommand(delete.NewCmdDelete(configFlags, ioStreams))
+	cmds.AddCommand(login.NewCmdLogin(configFlags, ioStreams))
+	cmds.AddCommand(config.NewCmdConfig(configFlags, ioStreams))
+	cmds.AddCommand(version.NewCmdVersion(configFlags, ioStreams))
 
 	return cmds
 }
@@ -0,0 +1,71 @@
+// Package config implements the floractl config command editing the client
+// config files.
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+)
+
+var configLong = templates.LongDesc(`
+	Modify floractl config files using subcommands like "floractl config set-context my-context".
+
+	The loading order follows these rules:
+
+	1. If the --config flag is set, then only that file is loaded. The flag may only be set once
+	and no merging takes place.
+	2. If $FLORACONFIG environment variable is set, then it is used as a list of paths (normal
+	path delimiting rules for your system). These paths are merged. When a value is modified,
+	it is modified in the file that defines the stanza. When a value is created, it is created
+	in the first file that exists.
+	3. Otherwise, ~/.flora/config is used and no merging takes place.`)
+
+// NewCmdConfig returns the config command and its subcommands.
+func NewCmdConfig(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "config SUBCOMMAND",
+		DisableFlagsInUseLine: true,
+		Short:                 "Modify floractl config files",
+		Long:                  configLong,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdConfigView(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigCurrentContext(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigGetContexts(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigUseContext(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigSetContext(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigDeleteContext(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigSetCluster(configFlags, ioStreams))
+	cmd.AddCommand(NewCmdConfigSetCredentials(configFlags, ioStreams))
+
+	return cmd
+}
+
+// loadConfig loads the merged config files.
+func loadConfig(configFlags *options.ConfigFlags) (*clientcmd.Config, error) {
+	return configFlags.ToLoadingRules().Load()
+}
+
+// absPath returns the absolute path of a file set on the command line, so
+// that it does not depend on the directory floractl runs in.
+func absPath(path string) (string, error) {
+	if len(path) == 0 {
+		return path, nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	return abs, nil
+}
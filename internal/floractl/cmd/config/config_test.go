@@ -0,0 +1,101 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+)
+
+// run runs floractl config with args against the config file at path.
+func run(t *testing.T, path string, args ...string) (string, error) {
+	t.Helper()
+
+	configFlags := options.NewConfigFlags(false)
+	ioStreams, _, out, _ := options.NewTestIOStreams()
+
+	root := &cobra.Command{Use: "floractl", SilenceErrors: true, SilenceUsage: true}
+	configFlags.AddFlags(root.PersistentFlags())
+	root.AddCommand(NewCmdConfig(configFlags, ioStreams))
+	root.SetArgs(append([]string{"--config", path, "config"}, args...))
+
+	err := root.Execute()
+
+	return out.String(), err
+}
+
+func TestConfigCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	steps := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{"current-context"}, wantErr: true},
+		{args: []string{"set-cluster", "dev", "--server=https://dev:8443"}, want: `Cluster "dev" set.`},
+		{args: []string{"set-credentials", "admin", "--secret-id=id", "--secret-key=key"}, want: `User "admin" set.`},
+		{args: []string{"set-credentials", "admin", "--token=token"}, wantErr: true},
+		{args: []string{"set-credentials", "admin", "--exec-command=plugin"}, wantErr: true},
+		{args: []string{"set-credentials", "bot", "--exec-arg=prod"}, wantErr: true},
+		{args: []string{"set-credentials", "bot", "--exec-command=plugin", "--exec-env=VAULT"}, wantErr: true},
+		{
+			args: []string{"set-credentials", "bot", "--exec-command=plugin", "--exec-arg=prod", "--exec-env=VAULT=dev"},
+			want: `User "bot" set.`,
+		},
+		{args: []string{"view"}, want: "exec:\n        command: plugin\n        args:\n          - prod\n        env:\n          - name: VAULT"},
+		{args: []string{"set-credentials", "bot", "--exec-command=", "--token=token"}, want: `User "bot" set.`},
+		{args: []string{"set-context", "dev", "--cluster=dev", "--user=admin"}, want: `Context "dev" created.`},
+		{args: []string{"set-context", "--current", "--cluster=prod"}, wantErr: true},
+		{args: []string{"use-context", "prod"}, wantErr: true},
+		{args: []string{"use-context", "dev"}, want: `Switched to context "dev".`},
+		{args: []string{"set-context", "--current", "--user=root"}, want: `Context "dev" modified.`},
+		{args: []string{"current-context"}, want: "dev"},
+		{args: []string{"get-contexts"}, want: "*         dev    dev       root"},
+		{args: []string{"get-contexts", "-o", "name"}, want: "dev"},
+		{args: []string{"get-contexts", "prod"}, wantErr: true},
+		{args: []string{"view"}, want: "secret-key: '--- REDACTED ---'"},
+		{args: []string{"view", "--raw"}, want: "secret-key: key"},
+		{args: []string{"delete-context", "dev"}, want: "deleted context dev from " + path},
+		{args: []string{"delete-context", "dev"}, wantErr: true},
+	}
+
+	for _, step := range steps {
+		out, err := run(t, path, step.args...)
+		if (err != nil) != step.wantErr {
+			t.Fatalf("config %s: error = %v, want error %v", strings.Join(step.args, " "), err, step.wantErr)
+		}
+
+		if !strings.Contains(out, step.want) {
+			t.Errorf("config %s: output = %q, want %q", strings.Join(step.args, " "), out, step.want)
+		}
+	}
+}
+
+func TestConfigViewMinify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	for _, args := range [][]string{
+		{"set-cluster", "dev", "--server=https://dev"},
+		{"set-cluster", "prod", "--server=https://prod"},
+		{"set-context", "dev", "--cluster=dev"},
+		{"set-context", "prod", "--cluster=prod"},
+		{"use-context", "prod"},
+	} {
+		if _, err := run(t, path, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := run(t, path, "view", "--minify")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, "https://prod") || strings.Contains(out, "https://dev") {
+		t.Errorf("view --minify = %s, want the prod context only", out)
+	}
+}
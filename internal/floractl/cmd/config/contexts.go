@@ -0,0 +1,321 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+)
+
+// NewCmdConfigCurrentContext returns the config current-context command.
+func NewCmdConfigCurrentContext(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "current-context",
+		Short: "Display the current-context",
+		Example: templates.Examples(`
+			# Display the current-context
+			floractl config current-context`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configFlags)
+			if err != nil {
+				return err
+			}
+
+			if len(config.CurrentContext) == 0 {
+				return fmt.Errorf("current-context is not set")
+			}
+
+			_, err = fmt.Fprintln(ioStreams.Out, config.CurrentContext)
+
+			return err
+		},
+	}
+}
+
+// GetContextsOptions holds the options of config get-contexts.
+type GetContextsOptions struct {
+	ConfigFlags *options.ConfigFlags
+	Names       []string
+	Output      string
+	NoHeaders   bool
+	nameOnly    bool
+
+	options.IOStreams
+}
+
+// NewCmdConfigGetContexts returns the config get-contexts command.
+func NewCmdConfigGetContexts(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	o := &GetContextsOptions{ConfigFlags: configFlags, IOStreams: ioStreams}
+
+	cmd := &cobra.Command{
+		Use:   "get-contexts [(-o|--output=)name]",
+		Short: "Describe one or many contexts",
+		Example: templates.Examples(`
+			# List all the contexts in your floractl config file
+			floractl config get-contexts
+
+			# Describe one context in your floractl config file
+			floractl config get-contexts my-context`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = args
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders,
+		"When using the default output format, don't print headers (default print headers).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: name")
+
+	return cmd
+}
+
+// Validate checks the output format.
+func (o *GetContextsOptions) Validate() error {
+	switch o.Output {
+	case "":
+	case "name":
+		o.nameOnly = true
+	default:
+		return fmt.Errorf("output must be one of '' or 'name': %v", o.Output)
+	}
+
+	return nil
+}
+
+// Run prints the contexts.
+func (o *GetContextsOptions) Run() error {
+	config, err := loadConfig(o.ConfigFlags)
+	if err != nil {
+		return err
+	}
+
+	names := o.Names
+	if len(names) == 0 {
+		for name := range config.Contexts {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+	}
+
+	var missing []string
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 3, ' ', 0)
+	if !o.nameOnly && !o.NoHeaders {
+		fmt.Fprintln(w, "CURRENT\tNAME\tCLUSTER\tAUTHINFO")
+	}
+
+	for _, name := range names {
+		context, ok := config.Contexts[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+
+		if o.nameOnly {
+			fmt.Fprintln(w, name)
+			continue
+		}
+
+		current := ""
+		if name == config.CurrentContext {
+			current = "*"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, name, context.Cluster, context.AuthInfo)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(missing) != 0 {
+		return fmt.Errorf("context %s not found", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// NewCmdConfigUseContext returns the config use-context command.
+func NewCmdConfigUseContext(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:                   "use-context CONTEXT_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Set the current-context in a floractl config file",
+		Aliases:               []string{"use"},
+		Example: templates.Examples(`
+			# Use the context for the prod cluster
+			floractl config use-context prod`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			err := configFlags.ToLoadingRules().Modify(func(config *clientcmd.Config) error {
+				if _, ok := config.Contexts[name]; !ok {
+					return fmt.Errorf("no context exists with the name: %q", name)
+				}
+
+				config.CurrentContext = name
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(ioStreams.Out, "Switched to context %q.\n", name)
+
+			return err
+		},
+	}
+}
+
+// NewCmdConfigDeleteContext returns the config delete-context command.
+func NewCmdConfigDeleteContext(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:                   "delete-context NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Delete the specified context from the floractl config",
+		Example: templates.Examples(`
+			# Delete the context for the dev cluster
+			floractl config delete-context dev`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				name   = args[0]
+				origin string
+				active bool
+			)
+
+			err := configFlags.ToLoadingRules().Modify(func(config *clientcmd.Config) error {
+				context, ok := config.Contexts[name]
+				if !ok {
+					return fmt.Errorf("cannot delete context %s, not in config", name)
+				}
+
+				origin, active = context.LocationOfOrigin, config.CurrentContext == name
+				delete(config.Contexts, name)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if active {
+				fmt.Fprintln(ioStreams.ErrOut, "warning: this removed your active context, "+
+					"use \"floractl config use-context\" to select a different one")
+			}
+
+			_, err = fmt.Fprintf(ioStreams.Out, "deleted context %s from %s\n", name, origin)
+
+			return err
+		},
+	}
+}
+
+// SetContextOptions holds the options of config set-context.
+type SetContextOptions struct {
+	ConfigFlags *options.ConfigFlags
+	Name        string
+	CurrentFlag bool
+	Cluster     string
+	AuthInfo    string
+	setCluster  bool
+	setAuthInfo bool
+
+	options.IOStreams
+}
+
+// NewCmdConfigSetContext returns the config set-context command.
+func NewCmdConfigSetContext(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	o := &SetContextOptions{ConfigFlags: configFlags, IOStreams: ioStreams}
+
+	cmd := &cobra.Command{
+		Use:                   "set-context [NAME | --current] [--cluster=cluster_nickname] [--user=user_nickname]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Set a context entry in floractl config",
+		Long: templates.LongDesc(`
+			Set a context entry in floractl config.
+
+			Specifying a name that already exists will merge new fields on top of existing values for those fields.`),
+		Example: templates.Examples(`
+			# Set the user field on the prod context entry without touching other values
+			floractl config set-context prod --user=admin`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				o.Name = args[0]
+			}
+
+			o.setCluster = cmd.Flags().Changed("cluster")
+			o.setAuthInfo = cmd.Flags().Changed("user")
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.CurrentFlag, "current", o.CurrentFlag, "Modify the current context.")
+	cmd.Flags().StringVar(&o.Cluster, "cluster", o.Cluster, "cluster for the context entry in floractl config")
+	cmd.Flags().StringVar(&o.AuthInfo, "user", o.AuthInfo, "user for the context entry in floractl config")
+
+	return cmd
+}
+
+// Run creates or modifies the context.
+func (o *SetContextOptions) Run() error {
+	if o.CurrentFlag == (len(o.Name) != 0) {
+		return fmt.Errorf("you must specify either a context name or --current")
+	}
+
+	var created bool
+
+	err := o.ConfigFlags.ToLoadingRules().Modify(func(config *clientcmd.Config) error {
+		if o.CurrentFlag {
+			if len(config.CurrentContext) == 0 {
+				return fmt.Errorf("no current context is set")
+			}
+
+			o.Name = config.CurrentContext
+		}
+
+		context, ok := config.Contexts[o.Name]
+		if !ok {
+			context, created = &clientcmd.Context{}, true
+			config.Contexts[o.Name] = context
+		}
+
+		if o.setCluster {
+			context.Cluster = o.Cluster
+		}
+
+		if o.setAuthInfo {
+			context.AuthInfo = o.AuthInfo
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		_, err = fmt.Fprintf(o.Out, "Context %q created.\n", o.Name)
+	} else {
+		_, err = fmt.Fprintf(o.Out, "Context %q modified.\n", o.Name)
+	}
+
+	return err
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+)
+
+// SetClusterOptions holds the options of config set-cluster.
+type SetClusterOptions struct {
+	ConfigFlags *options.ConfigFlags
+	Name        string
+	Cluster     clientcmd.Cluster
+	flags       *pflag.FlagSet
+
+	options.IOStreams
+}
+
+// NewCmdConfigSetCluster returns the config set-cluster command.
+func NewCmdConfigSetCluster(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	o := &SetClusterOptions{ConfigFlags: configFlags, IOStreams: ioStreams}
+
+	cmd := &cobra.Command{
+		Use:                   "set-cluster NAME [--server=server] [--certificate-authority=path/to/certificate/authority] [--insecure-skip-tls-verify=true] [--tls-server-name=example.com]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Set a cluster entry in floractl config",
+		Long: templates.LongDesc(`
+			Set a cluster entry in floractl config.
+
+			Specifying a name that already exists will merge new fields on top of existing values for those fields.`),
+		Example: templates.Examples(`
+			# Set only the server field on the dev cluster entry
+			floractl config set-cluster dev --server=https://1.2.3.4:8443
+
+			# Set a custom certificate authority for the dev cluster entry
+			floractl config set-cluster dev --certificate-authority=~/.flora/dev/ca.pem
+
+			# Disable cert checking for the dev cluster entry
+			floractl config set-cluster dev --insecure-skip-tls-verify=true`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run()
+		},
+	}
+
+	o.flags = cmd.Flags()
+	o.flags.StringVar(&o.Cluster.Server, "server", "", "server for the cluster entry in floractl config")
+	o.flags.StringVar(&o.Cluster.CertificateAuthority, "certificate-authority", "",
+		"Path to certificate-authority file for the cluster entry in floractl config")
+	o.flags.BoolVar(&o.Cluster.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false,
+		"insecure-skip-tls-verify for the cluster entry in floractl config")
+	o.flags.StringVar(&o.Cluster.TLSServerName, "tls-server-name", "",
+		"tls-server-name for the cluster entry in floractl config")
+	o.flags.DurationVar(&o.Cluster.Timeout, "timeout", 0, "timeout of the requests to the cluster")
+	o.flags.IntVar(&o.Cluster.MaxRetries, "max-retries", 0, "maximum number of retries of the failed requests")
+	o.flags.DurationVar(&o.Cluster.RetryInterval, "retry-interval", time.Duration(0), "interval between the retries")
+
+	return cmd
+}
+
+// Run creates or modifies the cluster with the flags set.
+func (o *SetClusterOptions) Run() error {
+	ca, err := absPath(o.Cluster.CertificateAuthority)
+	if err != nil {
+		return err
+	}
+
+	err = o.ConfigFlags.ToLoadingRules().Modify(func(config *clientcmd.Config) error {
+		cluster, ok := config.Clusters[o.Name]
+		if !ok {
+			cluster = &clientcmd.Cluster{}
+			config.Clusters[o.Name] = cluster
+		}
+
+		set(o.flags, "server", &cluster.Server, o.Cluster.Server)
+		set(o.flags, "certificate-authority", &cluster.CertificateAuthority, ca)
+		set(o.flags, "insecure-skip-tls-verify", &cluster.InsecureSkipTLSVerify, o.Cluster.InsecureSkipTLSVerify)
+		set(o.flags, "tls-server-name", &cluster.TLSServerName, o.Cluster.TLSServerName)
+		set(o.flags, "timeout", &cluster.Timeout, o.Cluster.Timeout)
+		set(o.flags, "max-retries", &cluster.MaxRetries, o.Cluster.MaxRetries)
+		set(o.flags, "retry-interval", &cluster.RetryInterval, o.Cluster.RetryInterval)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(o.Out, "Cluster %q set.\n", o.Name)
+
+	return err
+}
+
+// SetCredentialsOptions holds the options of config set-credentials.
+type SetCredentialsOptions struct {
+	ConfigFlags *options.ConfigFlags
+	Name        string
+	AuthInfo    clientcmd.AuthInfo
+	ExecCommand string
+	ExecArgs    []string
+	// ExecEnv holds the NAME=value environment variables of the plugin.
+	ExecEnv []string
+	flags   *pflag.FlagSet
+
+	options.IOStreams
+}
+
+// NewCmdConfigSetCredentials returns the config set-credentials command.
+func NewCmdConfigSetCredentials(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	o := &SetCredentialsOptions{ConfigFlags: configFlags, IOStreams: ioStreams}
+
+	cmd := &cobra.Command{
+		Use: "set-credentials NAME [--token=bearer_token] [--username=basic_user] [--password=basic_password] " +
+			"[--secret-id=id] [--secret-key=key] [--client-certificate=path/to/certfile] [--client-key=path/to/keyfile] " +
+			"[--exec-command=exec_command] [--exec-arg=arg] [--exec-env=key=value]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Set a user entry in floractl config",
+		Long: templates.LongDesc(`
+			Set a user entry in floractl config.
+
+			Specifying a name that already exists will merge new fields on top of existing values.
+
+			Only one of the bearer token, the basic auth username and password, the secret id
+			and key and the exec credential plugin may be set. Set a field to an empty value to
+			remove it, an empty exec command removes the plugin.
+
+			The exec credential plugin prints the token or the secret id and key of the user as
+			an ExecCredential object, keeping them out of the config file:
+
+			    {"kind": "ExecCredential", "apiVersion": "apiserver/v1",
+			     "status": {"token": "...", "expirationTimestamp": "2024-01-02T15:04:05Z"}}
+
+			It is run again when the credentials expire or are rejected by the server.`),
+		Example: templates.Examples(`
+			# Set the secret of the "admin" entry
+			floractl config set-credentials admin --secret-id=id --secret-key=key
+
+			# Replace the secret of the "admin" entry by a bearer token
+			floractl config set-credentials admin --secret-id= --secret-key= --token=token
+
+			# Get the credentials of the "admin" entry from a credential plugin
+			floractl config set-credentials admin --exec-command=flora-credentials --exec-arg=--vault --exec-arg=prod \
+			  --exec-env=VAULT_ADDR=https://vault.example.com`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Name = args[0]
+
+			return o.Run()
+		},
+	}
+
+	o.flags = cmd.Flags()
+	o.flags.StringVar(&o.AuthInfo.Token, "token", "", "token for the user entry in floractl config")
+	o.flags.StringVar(&o.AuthInfo.Username, "username", "", "username for the user entry in floractl config")
+	o.flags.StringVar(&o.AuthInfo.Password, "password", "", "password for the user entry in floractl config")
+	o.flags.StringVar(&o.AuthInfo.SecretID, "secret-id", "", "secret id for the user entry in floractl config")
+	o.flags.StringVar(&o.AuthInfo.SecretKey, "secret-key", "", "secret key for the user entry in floractl config")
+	o.flags.StringVar(&o.AuthInfo.ClientCertificate, "client-certificate", "",
+		"Path to client-certificate file for the user entry in floractl config")
+	o.flags.StringVar(&o.AuthInfo.ClientKey, "client-key", "",
+		"Path to client-key file for the user entry in floractl config")
+	o.flags.StringVar(&o.ExecCommand, "exec-command", "",
+		"command of the exec credential plugin for the user entry in floractl config")
+	o.flags.StringArrayVar(&o.ExecArgs, "exec-arg", nil,
+		"argument of the exec credential plugin, repeat it for several arguments")
+	o.flags.StringArrayVar(&o.ExecEnv, "exec-env", nil,
+		"NAME=value environment variable of the exec credential plugin, repeat it for several variables")
+
+	return cmd
+}
+
+// Run creates or modifies the user with the flags set.
+func (o *SetCredentialsOptions) Run() error {
+	cert, err := absPath(o.AuthInfo.ClientCertificate)
+	if err != nil {
+		return err
+	}
+
+	key, err := absPath(o.AuthInfo.ClientKey)
+	if err != nil {
+		return err
+	}
+
+	err = o.ConfigFlags.ToLoadingRules().Modify(func(config *clientcmd.Config) error {
+		authInfo, ok := config.AuthInfos[o.Name]
+		if !ok {
+			authInfo = &clientcmd.AuthInfo{}
+			config.AuthInfos[o.Name] = authInfo
+		}
+
+		if o.flags.Changed("token") {
+			// The refresh token of floractl login renews the old token only.
+			authInfo.RefreshToken, authInfo.TokenExpiry = "", time.Time{}
+		}
+
+		set(o.flags, "token", &authInfo.Token, o.AuthInfo.Token)
+		set(o.flags, "username", &authInfo.Username, o.AuthInfo.Username)
+		set(o.flags, "password", &authInfo.Password, o.AuthInfo.Password)
+		set(o.flags, "secret-id", &authInfo.SecretID, o.AuthInfo.SecretID)
+		set(o.flags, "secret-key", &authInfo.SecretKey, o.AuthInfo.SecretKey)
+		set(o.flags, "client-certificate", &authInfo.ClientCertificate, cert)
+		set(o.flags, "client-key", &authInfo.ClientKey, key)
+
+		if err := o.setExec(authInfo); err != nil {
+			return err
+		}
+
+		methods := 0
+
+		for _, set := range []bool{
+			len(authInfo.Token) != 0, len(authInfo.Username) != 0, len(authInfo.SecretID) != 0, authInfo.Exec != nil,
+		} {
+			if set {
+				methods++
+			}
+		}
+
+		if methods > 1 {
+			return fmt.Errorf("user %q may only have one of token, username, secret-id and exec-command set", o.Name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(o.Out, "User %q set.\n", o.Name)
+
+	return err
+}
+
+// setExec applies the exec flags to the credential plugin of authInfo.
+func (o *SetCredentialsOptions) setExec(authInfo *clientcmd.AuthInfo) error {
+	if !o.flags.Changed("exec-command") && !o.flags.Changed("exec-arg") && !o.flags.Changed("exec-env") {
+		return nil
+	}
+
+	command := o.ExecCommand
+	if strings.ContainsRune(command, filepath.Separator) {
+		// A command without a directory is looked up in the PATH.
+		var err error
+		if command, err = absPath(command); err != nil {
+			return err
+		}
+	}
+
+	var env []clientcmd.ExecEnvVar
+
+	for _, s := range o.ExecEnv {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || len(name) == 0 {
+			return fmt.Errorf("invalid exec-env %q, want NAME=value", s)
+		}
+
+		env = append(env, clientcmd.ExecEnvVar{Name: name, Value: value})
+	}
+
+	exec := authInfo.Exec
+	if exec == nil {
+		exec = &clientcmd.ExecConfig{}
+	}
+
+	set(o.flags, "exec-command", &exec.Command, command)
+	set(o.flags, "exec-arg", &exec.Args, o.ExecArgs)
+	set(o.flags, "exec-env", &exec.Env, env)
+
+	switch {
+	case len(exec.Command) != 0:
+		authInfo.Exec = exec
+	case o.flags.Changed("exec-command"):
+		authInfo.Exec = nil
+	default:
+		return fmt.Errorf("user %q has no exec credential plugin, set its exec-command", o.Name)
+	}
+
+	return nil
+}
+
+// set sets field to value when the flag is set on the command line.
+func set[T any](flags *pflag.FlagSet, flag string, field *T, value T) {
+	if flags.Changed(flag) {
+		*field = value
+	}
+}
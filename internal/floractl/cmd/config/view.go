@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// ViewOptions holds the options of config view.
+type ViewOptions struct {
+	ConfigFlags *options.ConfigFlags
+	Raw         bool
+	Minify      bool
+
+	options.IOStreams
+}
+
+var (
+	viewLong = templates.LongDesc(`
+		Display merged floractl settings or a specified floractl config file.
+
+		The tokens, passwords and secret keys are redacted unless --raw is set.`)
+
+	viewExample = templates.Examples(`
+		# Show merged floractl settings
+		floractl config view
+
+		# Show the settings of the context in use with the credentials
+		floractl config view --minify --raw`)
+)
+
+// NewCmdConfigView returns the config view command.
+func NewCmdConfigView(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	o := &ViewOptions{ConfigFlags: configFlags, IOStreams: ioStreams}
+
+	cmd := &cobra.Command{
+		Use:     "view",
+		Short:   "Display merged floractl settings or a specified floractl config file",
+		Long:    viewLong,
+		Example: viewExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Raw, "raw", o.Raw, "Display the tokens, passwords and secret keys.")
+	cmd.Flags().BoolVar(&o.Minify, "minify", o.Minify,
+		"Remove all information not used by the context in use from the output.")
+
+	return cmd
+}
+
+// Run prints the config.
+func (o *ViewOptions) Run() error {
+	config, err := loadConfig(o.ConfigFlags)
+	if err != nil {
+		return err
+	}
+
+	if o.Minify {
+		if config, err = minify(config, o.ConfigFlags.ToRawConfigLoader().ContextName()); err != nil {
+			return err
+		}
+	}
+
+	if !o.Raw {
+		redact(config)
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return err
+	}
+
+	_, err = o.Out.Write(data)
+
+	return err
+}
+
+// minify returns the context, the cluster and the user in use of config.
+func minify(config *clientcmd.Config, contextName string) (*clientcmd.Config, error) {
+	if len(contextName) == 0 {
+		return nil, fmt.Errorf("current-context must exist in order to minify")
+	}
+
+	context, ok := config.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("cannot locate context %s", contextName)
+	}
+
+	out := clientcmd.NewConfig()
+	out.CurrentContext = contextName
+	out.Contexts[contextName] = context
+
+	if cluster, ok := config.Clusters[context.Cluster]; ok {
+		out.Clusters[context.Cluster] = cluster
+	}
+
+	if authInfo, ok := config.AuthInfos[context.AuthInfo]; ok {
+		out.AuthInfos[context.AuthInfo] = authInfo
+	}
+
+	return out, nil
+}
+
+// redact replaces the credentials of config by log.Redacted.
+func redact(config *clientcmd.Config) {
+	for _, authInfo := range config.AuthInfos {
+		for _, s := range []*string{&authInfo.Token, &authInfo.RefreshToken, &authInfo.Password, &authInfo.SecretKey} {
+			if len(*s) != 0 {
+				*s = log.Redacted
+			}
+		}
+	}
+}
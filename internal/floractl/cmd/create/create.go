@@ -0,0 +1,144 @@
+// Package create implements the floractl create command.
+package create
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/printers"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// CreateOptions contains the input to the create command.
+type CreateOptions struct {
+	PrintFlags      *printers.PrintFlags
+	FilenameOptions resource.FilenameOptions
+
+	DryRunStrategy resource.DryRunStrategy
+
+	client  *rest.RESTClient
+	infos   []resource.Info
+	printer printers.ResourcePrinter
+
+	options.IOStreams
+}
+
+var (
+	createLong = templates.LongDesc(`
+		Create a resource from a file or from stdin.
+
+		JSON and YAML formats are accepted, a file may hold several objects. The command
+		fails for the objects that already exist, use floractl apply to update them.`)
+
+	createExample = templates.Examples(`
+		# Create a lease using the data in lease.yaml
+		floractl create -f ./lease.yaml
+
+		# Create the leases of the JSON passed into stdin
+		cat leases.json | floractl create -f -`)
+)
+
+// NewCreateOptions returns an initialized CreateOptions.
+func NewCreateOptions(ioStreams options.IOStreams) *CreateOptions {
+	return &CreateOptions{PrintFlags: printers.NewPrintFlags(), IOStreams: ioStreams}
+}
+
+// NewCmdCreate returns the create command.
+func NewCmdCreate(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewCreateOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "create -f FILENAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Create a resource from a file or from stdin",
+		Long:                  createLong,
+		Example:               createExample,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd, f); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	o.FilenameOptions.AddFlags(cmd, "to use to create the resource")
+	resource.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+// Complete reads the manifests and resolves their objects through the
+// discovery of the server.
+func (o *CreateOptions) Complete(cmd *cobra.Command, f options.RESTClientGetter) error {
+	var err error
+
+	if o.DryRunStrategy, err = resource.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+
+	if err := o.FilenameOptions.Validate(); err != nil {
+		return err
+	}
+
+	if o.printer, err = o.PrintFlags.ToPrinter(""); err != nil {
+		return err
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(cmd.Context(), o.client)
+	if err != nil {
+		return err
+	}
+
+	o.infos, err = o.FilenameOptions.Infos(cmd.Context(), mapper, o.In)
+
+	return err
+}
+
+// Run creates the objects one by one and prints each created object. The
+// objects that fail do not stop the others, their errors are returned
+// together.
+func (o *CreateOptions) Run(ctx context.Context) error {
+	if p, ok := o.printer.(*printers.NamePrinter); ok {
+		p.Operation = "created" + o.DryRunStrategy.Suffix()
+	}
+
+	var errs []error
+
+	for _, info := range o.infos {
+		obj := info.Object
+
+		if o.DryRunStrategy != resource.DryRunClient {
+			var err error
+
+			helper := resource.NewHelper(o.client, info.Resource).DryRun(o.DryRunStrategy == resource.DryRunServer)
+			if obj, err = helper.Create(ctx, info.Object); err != nil {
+				errs = append(errs, fmt.Errorf("error when creating %q: %w", info.Source, err))
+				continue
+			}
+		}
+
+		if err := o.printer.PrintObj(obj, o.Out); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
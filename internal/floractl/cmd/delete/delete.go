@@ -0,0 +1,184 @@
+// Package delete implements the floractl delete command.
+package delete
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/printers"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// DeleteOptions contains the input to the delete command.
+type DeleteOptions struct {
+	PrintFlags      *printers.PrintFlags
+	FilenameOptions resource.FilenameOptions
+
+	IgnoreNotFound bool
+	DryRunStrategy resource.DryRunStrategy
+
+	client  *rest.RESTClient
+	infos   []resource.Info
+	printer printers.ResourcePrinter
+
+	options.IOStreams
+}
+
+var (
+	deleteLong = templates.LongDesc(`
+		Delete resources by file names, stdin, or resources and names.
+
+		JSON and YAML formats are accepted. Only one type of argument may be specified:
+		file names or resources and names.`)
+
+	deleteExample = templates.Examples(`
+		# Delete the lease of lease.yaml
+		floractl delete -f ./lease.yaml
+
+		# Delete the leases of the manifests of a directory and its subdirectories
+		floractl delete -R -f ./manifests
+
+		# Delete the leases named leader and scheduler
+		floractl delete lease leader scheduler
+
+		# Delete a lease that may not exist
+		floractl delete lease/leader --ignore-not-found`)
+)
+
+// NewDeleteOptions returns an initialized DeleteOptions.
+func NewDeleteOptions(ioStreams options.IOStreams) *DeleteOptions {
+	return &DeleteOptions{PrintFlags: printers.NewPrintFlags(), IOStreams: ioStreams}
+}
+
+// NewCmdDelete returns the delete command.
+func NewCmdDelete(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewDeleteOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "delete ([-f FILENAME] | TYPE [NAME ...] | TYPE/NAME ...)",
+		DisableFlagsInUseLine: true,
+		Short:                 "Delete resources by file names, stdin, or resources and names",
+		Long:                  deleteLong,
+		Example:               deleteExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd, f, args); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	o.FilenameOptions.AddFlags(cmd, "containing the resource to delete")
+	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound,
+		"Treat \"resource not found\" as a successful delete.")
+	resource.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+// Complete resolves the objects of the manifests or of args through the
+// discovery of the server.
+func (o *DeleteOptions) Complete(cmd *cobra.Command, f options.RESTClientGetter, args []string) error {
+	var err error
+
+	if o.DryRunStrategy, err = resource.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+
+	if len(o.FilenameOptions.Filenames) != 0 && len(args) != 0 {
+		return errors.New("resources and names can not be passed as arguments when -f is specified")
+	}
+
+	if o.printer, err = o.PrintFlags.ToPrinter(""); err != nil {
+		return err
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(cmd.Context(), o.client)
+	if err != nil {
+		return err
+	}
+
+	if len(o.FilenameOptions.Filenames) != 0 {
+		o.infos, err = o.FilenameOptions.Infos(cmd.Context(), mapper, o.In)
+	} else {
+		o.infos, err = mapper.ParseArgs(args)
+	}
+
+	return err
+}
+
+// Validate checks that the objects to delete are named.
+func (o *DeleteOptions) Validate() error {
+	for _, info := range o.infos {
+		if len(info.Name) == 0 {
+			return fmt.Errorf("resource(s) were provided, but no name was specified")
+		}
+	}
+
+	return nil
+}
+
+// Run deletes the objects one by one and prints each deleted object. The
+// objects that fail do not stop the others, their errors are returned
+// together.
+func (o *DeleteOptions) Run(ctx context.Context) error {
+	if p, ok := o.printer.(*printers.NamePrinter); ok {
+		p.Operation = "deleted" + o.DryRunStrategy.Suffix()
+	}
+
+	var errs []error
+
+	for _, info := range o.infos {
+		helper := resource.NewHelper(o.client, info.Resource).DryRun(o.DryRunStrategy == resource.DryRunServer)
+
+		var (
+			obj map[string]interface{}
+			err error
+		)
+
+		// A client dry run prints the live object to make sure it exists.
+		if o.DryRunStrategy == resource.DryRunClient {
+			obj, err = helper.Get(ctx, info.Name)
+		} else {
+			obj, err = helper.Delete(ctx, info.Name)
+		}
+
+		if err != nil {
+			if o.IgnoreNotFound && apierrors.IsNotFound(err) {
+				continue
+			}
+
+			errs = append(errs, fmt.Errorf("error when deleting %s: %w", info, err))
+
+			continue
+		}
+
+		if err := o.printer.PrintObj(obj, o.Out); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
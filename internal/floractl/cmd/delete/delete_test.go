@@ -0,0 +1,70 @@
+package delete
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/create"
+	cmdtesting "github.com/hanzhuoxian/flora/internal/floractl/cmd/testing"
+)
+
+// run runs floractl create and delete with args against server.
+func run(server *httptest.Server, args ...string) (string, error) {
+	newCmds := []cmdtesting.NewCmdFunc{create.NewCmdCreate, NewCmdDelete}
+	out, _, err := cmdtesting.Run(context.Background(), server, newCmds, args...)
+
+	return out, err
+}
+
+func TestCreateDelete(t *testing.T) {
+	server, _ := cmdtesting.NewServer(t)
+
+	manifest := filepath.Join(t.TempDir(), "leases.yaml")
+	if err := os.WriteFile(manifest, []byte(`apiVersion: apiserver/v1
+kind: List
+items:
+- apiVersion: apiserver/v1
+  kind: Lease
+  metadata:
+    name: leader
+- apiVersion: apiserver/v1
+  kind: Lease
+  metadata:
+    name: scheduler
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{"create", "-f", manifest, "--dry-run=server"}, want: "lease/leader created (server dry run)\nlease/scheduler created (server dry run)\n"},
+		{args: []string{"delete", "-f", manifest}, wantErr: true},
+		{args: []string{"create", "-f", manifest}, want: "lease/leader created\nlease/scheduler created\n"},
+		{args: []string{"create", "-f", manifest}, wantErr: true},
+		{args: []string{"delete", "lease", "leader", "--dry-run=client"}, want: "lease/leader deleted (dry run)\n"},
+		{args: []string{"delete", "lease", "leader", "--dry-run=server"}, want: "lease/leader deleted (server dry run)\n"},
+		{args: []string{"delete", "lease/leader", "lease/missing"}, want: "lease/leader deleted\n", wantErr: true},
+		{args: []string{"delete", "-f", manifest, "--ignore-not-found"}, want: "lease/scheduler deleted\n"},
+		{args: []string{"delete", "leases"}, wantErr: true},
+		{args: []string{"delete", "-f", manifest, "lease", "leader"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		out, err := run(server, tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", strings.Join(tt.args, " "), err, tt.wantErr)
+			continue
+		}
+
+		if out != tt.want {
+			t.Errorf("%s = %q, want %q", strings.Join(tt.args, " "), out, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package describe implements the floractl describe command.
+package describe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/labels"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// DescribeOptions contains the input to the describe command.
+type DescribeOptions struct {
+	client *rest.RESTClient
+	infos  []resource.Info
+
+	options.IOStreams
+}
+
+var (
+	describeLong = templates.LongDesc(`
+		Show details of a specific resource.
+
+		Print a detailed description of the selected resources, with every field of the
+		objects under a human readable label. Works with any resource served by the
+		apiserver.`)
+
+	describeExample = templates.Examples(`
+		# Describe a lease
+		floractl describe lease leader
+
+		# Describe several leases
+		floractl describe leases/leader leases/scheduler`)
+)
+
+// NewDescribeOptions returns an initialized DescribeOptions.
+func NewDescribeOptions(ioStreams options.IOStreams) *DescribeOptions {
+	return &DescribeOptions{IOStreams: ioStreams}
+}
+
+// NewCmdDescribe returns the describe command.
+func NewCmdDescribe(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewDescribeOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "describe (TYPE NAME ... | TYPE/NAME ...)",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show details of a specific resource",
+		Long:                  describeLong,
+		Example:               describeExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd.Context(), f, args); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	return cmd
+}
+
+// Complete resolves the resources of args through the discovery of the
+// server.
+func (o *DescribeOptions) Complete(ctx context.Context, f options.RESTClientGetter, args []string) error {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(ctx, o.client)
+	if err != nil {
+		return err
+	}
+
+	o.infos, err = mapper.ParseArgs(args)
+
+	return err
+}
+
+// Validate checks that the objects to describe are named.
+func (o *DescribeOptions) Validate() error {
+	for _, info := range o.infos {
+		if len(info.Name) == 0 {
+			return fmt.Errorf("you must specify the name of the %s to describe", info.Resource.SingularName)
+		}
+	}
+
+	return nil
+}
+
+// Run describes the objects, separated by blank lines.
+func (o *DescribeOptions) Run(ctx context.Context) error {
+	for i, info := range o.infos {
+		data, err := o.client.Get().Resource(info.Resource.Name).Name(info.Name).Do(ctx).Raw()
+		if err != nil {
+			return err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+
+		if err := Describe(o.Out, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Describe writes the fields of obj. The name, labels and annotations come
+// first, then the other fields sorted by name with nested fields indented.
+func Describe(w io.Writer, obj map[string]interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+
+	fmt.Fprintf(tw, "Name:\t%v\n", metadata["name"])
+	fmt.Fprintf(tw, "Labels:\t%s\n", formatMap(metadata["labels"]))
+	fmt.Fprintf(tw, "Annotations:\t%s\n", formatMap(metadata["annotations"]))
+
+	fields := make(map[string]interface{}, len(obj))
+
+	for key, value := range obj {
+		if key != "metadata" {
+			fields[key] = value
+			continue
+		}
+
+		remaining := make(map[string]interface{}, len(metadata))
+
+		for k, v := range metadata {
+			if k != "name" && k != "labels" && k != "annotations" {
+				remaining[k] = v
+			}
+		}
+
+		if len(remaining) != 0 {
+			fields[key] = remaining
+		}
+	}
+
+	describeMap(tw, 0, fields)
+
+	return tw.Flush()
+}
+
+func describeMap(w io.Writer, level int, m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		describeValue(w, level, smartLabelFor(key)+":", m[key])
+	}
+}
+
+func describeValue(w io.Writer, level int, label string, value interface{}) {
+	indent := strings.Repeat("  ", level)
+
+	switch t := value.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 {
+			fmt.Fprintf(w, "%s%s\t<none>\n", indent, label)
+			return
+		}
+
+		fmt.Fprintf(w, "%s%s\n", indent, label)
+		describeMap(w, level+1, t)
+	case []interface{}:
+		if len(t) == 0 {
+			fmt.Fprintf(w, "%s%s\t<none>\n", indent, label)
+			return
+		}
+
+		fmt.Fprintf(w, "%s%s\n", indent, label)
+
+		for _, item := range t {
+			if m, ok := item.(map[string]interface{}); ok {
+				describeMap(w, level+1, m)
+			} else {
+				fmt.Fprintf(w, "%s  %v\n", indent, item)
+			}
+		}
+	case nil:
+		fmt.Fprintf(w, "%s%s\t<none>\n", indent, label)
+	default:
+		fmt.Fprintf(w, "%s%s\t%v\n", indent, label, t)
+	}
+}
+
+// formatMap returns labels or annotations one per line, aligned on the
+// value column.
+func formatMap(value interface{}) string {
+	m, _ := value.(map[string]interface{})
+	if len(m) == 0 {
+		return "<none>"
+	}
+
+	set := make(labels.Set, len(m))
+	for key, v := range m {
+		set[key] = fmt.Sprint(v)
+	}
+
+	return strings.ReplaceAll(set.String(), ",", "\n\t")
+}
+
+// acronyms are the words labeled in upper case.
+var acronyms = map[string]bool{
+	"api": true, "id": true, "ip": true, "url": true, "uid": true, "tls": true, "http": true, "https": true,
+}
+
+// smartLabelFor turns a camel case field name into a label, e.g.
+// holderIdentity into "Holder Identity" and apiVersion into "API Version".
+func smartLabelFor(field string) string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	runes := []rune(field)
+	for i, r := range runes {
+		// A word starts at an upper case letter following a lower case one,
+		// or at the last upper case letter of an acronym, e.g. "TLSServer".
+		if unicode.IsUpper(r) && len(word) != 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words = append(words, string(word))
+			word = nil
+		}
+
+		word = append(word, r)
+	}
+
+	words = append(words, string(word))
+
+	for i, w := range words {
+		switch {
+		case len(w) == 0:
+		case acronyms[strings.ToLower(w)]:
+			words[i] = strings.ToUpper(w)
+		default:
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,61 @@
+package describe
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	var obj map[string]interface{}
+
+	err := json.Unmarshal([]byte(`{
+		"apiVersion": "apiserver/v1",
+		"kind": "Lease",
+		"metadata": {"name": "leader", "labels": {"env": "prod", "app": "web"}, "resourceVersion": "7"},
+		"spec": {"holderIdentity": "node-a", "leaseDurationSeconds": 15, "owners": ["a", "b"], "conditions": []}
+	}`), &obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Describe(&out, obj); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Name:         leader
+Labels:       app=web
+              env=prod
+Annotations:  <none>
+API Version:  apiserver/v1
+Kind:         Lease
+Metadata:
+  Resource Version:  7
+Spec:
+  Conditions:              <none>
+  Holder Identity:         node-a
+  Lease Duration Seconds:  15
+  Owners:
+    a
+    b
+`
+	if out.String() != want {
+		t.Errorf("Describe() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestSmartLabelFor(t *testing.T) {
+	for field, want := range map[string]string{
+		"holderIdentity": "Holder Identity",
+		"apiVersion":     "API Version",
+		"secretID":       "Secret ID",
+		"tlsServerName":  "TLS Server Name",
+		"URLPath":        "URL Path",
+		"name":           "Name",
+	} {
+		if got := smartLabelFor(field); got != want {
+			t.Errorf("smartLabelFor(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
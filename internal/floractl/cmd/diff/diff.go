@@ -0,0 +1,226 @@
+// Package diff implements the floractl diff command.
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/cmd/apply"
+	"github.com/hanzhuoxian/flora/internal/floractl/util/cmdutil"
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/printers"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// ExternalDiffEnv names the environment variable setting the diff program.
+const ExternalDiffEnv = "FLORACTL_EXTERNAL_DIFF"
+
+// defaultDiffCommand is the diff program used when ExternalDiffEnv is not
+// set.
+var defaultDiffCommand = []string{"diff", "-u", "-N"}
+
+// DiffOptions contains the input to the diff command.
+type DiffOptions struct {
+	FilenameOptions resource.FilenameOptions
+
+	// DiffCommand is the diff program and its arguments, the directories of
+	// the live and merged objects are appended.
+	DiffCommand []string
+
+	client *rest.RESTClient
+	infos  []resource.Info
+
+	options.IOStreams
+}
+
+var (
+	diffLong = templates.LongDesc(`
+		Diff configurations specified by file name or stdin between the current online
+		configuration, and the configuration as it would be if applied.
+
+		The output is always YAML. The objects are sent to the server with a server-side
+		dry run, so the diff shows the changes as the server would make them, including
+		the defaults and the validation of the server.
+
+		FLORACTL_EXTERNAL_DIFF environment variable can be used to select your own diff
+		command. Users can use external commands with params too, example:
+		FLORACTL_EXTERNAL_DIFF="colordiff -N -u"
+
+		By default, the "diff" command available in your path will be run with the "-u"
+		(unified diff) and "-N" (treat absent files as empty) options.
+
+		Exit status:
+		 0 No differences were found.
+		 1 Differences were found.
+		 >1 floractl or diff failed with an error.`)
+
+	diffExample = templates.Examples(`
+		# Diff the resources included in lease.yaml
+		floractl diff -f lease.yaml
+
+		# Diff the manifests of a directory and its subdirectories
+		floractl diff -R -f ./manifests
+
+		# Diff the file read from stdin
+		cat lease.yaml | floractl diff -f -`)
+)
+
+// NewDiffOptions returns a DiffOptions running the diff program of
+// ExternalDiffEnv.
+func NewDiffOptions(ioStreams options.IOStreams) *DiffOptions {
+	diffCommand := defaultDiffCommand
+	if fields := strings.Fields(os.Getenv(ExternalDiffEnv)); len(fields) != 0 {
+		diffCommand = fields
+	}
+
+	return &DiffOptions{DiffCommand: diffCommand, IOStreams: ioStreams}
+}
+
+// NewCmdDiff returns the diff command.
+func NewCmdDiff(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewDiffOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "diff -f FILENAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Diff the live version against a would-be applied version",
+		Long:                  diffLong,
+		Example:               diffExample,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Complete(cmd, f)
+			if err == nil {
+				err = o.Run(cmd.Context())
+			}
+
+			var exitErr *exec.ExitError
+
+			switch {
+			case err == nil:
+				return nil
+			case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+				// The diff program printed the differences.
+				cmd.SilenceErrors = true
+				return &cmdutil.ExitError{Code: 1}
+			case errors.As(err, &exitErr) && exitErr.ExitCode() > 1:
+				return &cmdutil.ExitError{Err: err, Code: exitErr.ExitCode()}
+			}
+
+			return &cmdutil.ExitError{Err: err, Code: 2}
+		},
+	}
+
+	o.FilenameOptions.AddFlags(cmd, "contains the configuration to diff")
+
+	return cmd
+}
+
+// Complete reads the manifests and resolves their objects through the
+// discovery of the server.
+func (o *DiffOptions) Complete(cmd *cobra.Command, f options.RESTClientGetter) error {
+	if err := o.FilenameOptions.Validate(); err != nil {
+		return err
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(cmd.Context(), o.client)
+	if err != nil {
+		return err
+	}
+
+	o.infos, err = o.FilenameOptions.Infos(cmd.Context(), mapper, o.In)
+
+	return err
+}
+
+// Run writes the live objects and the objects as they would be applied in
+// two directories, then compares the directories with the diff program.
+func (o *DiffOptions) Run(ctx context.Context) error {
+	tmp, err := os.MkdirTemp("", "floractl-diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	liveDir, mergedDir := filepath.Join(tmp, "LIVE"), filepath.Join(tmp, "MERGED")
+	for _, dir := range []string{liveDir, mergedDir} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			return err
+		}
+	}
+
+	applier := apply.NewApplier(o.client, resource.DryRunServer)
+
+	for _, info := range o.infos {
+		live, err := resource.NewHelper(o.client, info.Resource).Get(ctx, info.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		merged, _, err := applier.Apply(ctx, info)
+		if err != nil {
+			return fmt.Errorf("error when diffing %s from %q: %w", info, info.Source, err)
+		}
+
+		name := fileName(info)
+
+		if live != nil {
+			if err := writeObject(filepath.Join(liveDir, name), live); err != nil {
+				return err
+			}
+		}
+
+		if err := writeObject(filepath.Join(mergedDir, name), merged); err != nil {
+			return err
+		}
+	}
+
+	args := append(append([]string{}, o.DiffCommand[1:]...), liveDir, mergedDir)
+
+	cmd := exec.CommandContext(ctx, o.DiffCommand[0], args...)
+	cmd.Stdout = o.Out
+	cmd.Stderr = o.ErrOut
+
+	return cmd.Run()
+}
+
+// fileName returns the name of the files of the object of info, e.g.
+// apiserver.v1.Lease.leader.
+func fileName(info resource.Info) string {
+	apiVersion, _ := info.Object["apiVersion"].(string)
+
+	return strings.ReplaceAll(strings.Join([]string{apiVersion, info.Resource.Kind, info.Name}, "."), "/", ".")
+}
+
+// writeObject writes obj to path as YAML.
+func writeObject(path string, obj map[string]interface{}) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := (&printers.YAMLPrinter{}).PrintObj(obj, f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cmdtesting "github.com/hanzhuoxian/flora/internal/floractl/cmd/testing"
+	"github.com/hanzhuoxian/flora/internal/floractl/util/cmdutil"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// run runs floractl diff with args against server.
+func run(server *httptest.Server, args ...string) (string, error) {
+	out, _, err := cmdtesting.Run(context.Background(), server, []cmdtesting.NewCmdFunc{NewCmdDiff}, append([]string{"diff"}, args...)...)
+
+	return out, err
+}
+
+func TestDiff(t *testing.T) {
+	if _, err := exec.LookPath("diff"); err != nil {
+		t.Skip("diff is not installed")
+	}
+
+	t.Setenv(ExternalDiffEnv, "diff -u -N")
+
+	server, store := cmdtesting.NewServer(t)
+
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	leader := write("leader.yaml", "apiVersion: apiserver/v1\nkind: Lease\nmetadata:\n  name: leader\nspec:\n  holderIdentity: node-a\n")
+	invalid := write("invalid.yaml", "apiVersion: apiserver/v1\nkind: Lease\nmetadata:\n  name: leader\nspec:\n  leaseDurationSeconds: -1\n")
+
+	// A new object is diffed against nothing.
+	out, err := run(server, "-f", leader)
+	if code := cmdutil.ExitCode(err); code != 1 {
+		t.Fatalf("diff of a new object exit code = %d (%v), want 1", code, err)
+	}
+
+	if !strings.Contains(out, "+  name: leader") || !strings.Contains(out, "+  holderIdentity: node-a") {
+		t.Errorf("diff of a new object = %q, want the object added", out)
+	}
+
+	if _, err := store.Get(context.Background(), "leader"); err == nil {
+		t.Errorf("diff created the lease")
+	}
+
+	// The changes to a live object are shown.
+	obj := &v1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "leader"}, Spec: v1.LeaseSpec{HolderIdentity: "node-b"}}
+	if _, err := store.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = run(server, "-f", leader)
+	if code := cmdutil.ExitCode(err); code != 1 {
+		t.Fatalf("diff of a changed object exit code = %d (%v), want 1", code, err)
+	}
+
+	if !strings.Contains(out, "-  holderIdentity: node-b\n+  holderIdentity: node-a") {
+		t.Errorf("diff of a changed object = %q, want the holder changed", out)
+	}
+
+	// The errors of the server exit with a code greater than 1.
+	if _, err := run(server, "-f", invalid); cmdutil.ExitCode(err) <= 1 {
+		t.Errorf("diff of an invalid object exit code = %d (%v), want > 1", cmdutil.ExitCode(err), err)
+	}
+
+	t.Setenv(ExternalDiffEnv, "true")
+
+	if out, err := run(server, "-f", leader); err != nil || len(out) != 0 {
+		t.Errorf("diff with %s=true = %q, %v, want no differences", ExternalDiffEnv, out, err)
+	}
+}
@@ -0,0 +1,356 @@
+// Package edit implements the floractl edit command.
+package edit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/editor"
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/printers"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// editorEnvs are the environment variables selecting the editor, by
+// priority.
+var editorEnvs = []string{"FLORACTL_EDITOR", "EDITOR"}
+
+// editHeader starts the files opened in the editor.
+const editHeader = `# Please edit the object below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file will be
+# reopened with the relevant failures.
+#
+`
+
+// EditOptions contains the input to the edit command.
+type EditOptions struct {
+	OutputFormat string
+
+	Editor editor.Editor
+
+	client *rest.RESTClient
+	infos  []resource.Info
+
+	options.IOStreams
+}
+
+var (
+	editLong = templates.LongDesc(`
+		Edit a resource from the default editor.
+
+		The edit command allows you to directly edit any API resource you can retrieve via the
+		command-line tools. It will open the editor defined by your FLORACTL_EDITOR, or EDITOR
+		environment variables, or fall back to 'vi'. You can edit multiple objects, they are
+		opened one after the other. The default format is YAML, use -o json to edit in JSON.
+
+		The object is updated with a check of its resource version: if the object was changed
+		since it was opened, or if the server rejects the change, the editor is opened again
+		with the error at the top of the file and your changes kept, applied to the latest
+		version of the object in case of a conflict. Save the file unchanged after a rejected
+		change to give up, a copy of your changes is then kept in a temporary file.`)
+
+	editExample = templates.Examples(`
+		# Edit the lease named leader
+		floractl edit lease leader
+
+		# Use an alternative editor
+		FLORACTL_EDITOR="nano" floractl edit lease/leader
+
+		# Edit the lease leader in JSON
+		floractl edit lease/leader -o json`)
+)
+
+// NewEditOptions returns an EditOptions opening YAML in the default editor.
+func NewEditOptions(ioStreams options.IOStreams) *EditOptions {
+	return &EditOptions{
+		OutputFormat: printers.OutputYAML,
+		Editor:       editor.NewDefaultEditor(editorEnvs),
+		IOStreams:    ioStreams,
+	}
+}
+
+// NewCmdEdit returns the edit command.
+func NewCmdEdit(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewEditOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "edit (TYPE NAME ... | TYPE/NAME ...)",
+		DisableFlagsInUseLine: true,
+		Short:                 "Edit a resource on the server",
+		Long:                  editLong,
+		Example:               editExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd.Context(), f, args); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.OutputFormat, "output", "o", o.OutputFormat,
+		"Output format of the edited objects. One of: (yaml, json).")
+
+	return cmd
+}
+
+// Complete resolves the resources of args through the discovery of the
+// server.
+func (o *EditOptions) Complete(ctx context.Context, f options.RESTClientGetter, args []string) error {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(ctx, o.client)
+	if err != nil {
+		return err
+	}
+
+	o.infos, err = mapper.ParseArgs(args)
+
+	return err
+}
+
+// Validate checks the output format and that the objects to edit are named.
+func (o *EditOptions) Validate() error {
+	if o.OutputFormat != printers.OutputYAML && o.OutputFormat != printers.OutputJSON {
+		return fmt.Errorf("the edit mode %q is not supported, use yaml or json", o.OutputFormat)
+	}
+
+	for _, info := range o.infos {
+		if len(info.Name) == 0 {
+			return fmt.Errorf("you must specify the name of the %s to edit", info.Resource.SingularName)
+		}
+	}
+
+	return nil
+}
+
+// Run edits the objects one after the other.
+func (o *EditOptions) Run(ctx context.Context) error {
+	for _, info := range o.infos {
+		if err := o.edit(ctx, info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// edit opens the object of info in the editor until the edited object is
+// saved, the edit is cancelled or an error can't be fixed by editing.
+func (o *EditOptions) edit(ctx context.Context, info resource.Info) error {
+	helper := resource.NewHelper(o.client, info.Resource)
+
+	original, err := helper.Get(ctx, info.Name)
+	if err != nil {
+		return err
+	}
+
+	content, err := o.encode(original)
+	if err != nil {
+		return err
+	}
+
+	var (
+		editErr error
+		// retry is set when content is the latest version of the object
+		// with the edits of the user, which are saved even if the user
+		// doesn't change it further.
+		retry bool
+	)
+
+	for {
+		var buf bytes.Buffer
+
+		buf.WriteString(editHeader)
+		writeError(&buf, info, editErr)
+		buf.Write(content)
+
+		edited, path, err := o.Editor.LaunchTempFile("floractl-edit-", "."+o.OutputFormat, &buf, o.IOStreams)
+		if err != nil {
+			removeFile(path)
+			return err
+		}
+
+		edited = stripComments(edited)
+
+		if len(bytes.TrimSpace(edited)) == 0 {
+			removeFile(path)
+			fmt.Fprintln(o.ErrOut, "Edit cancelled, no changes made.")
+
+			return nil
+		}
+
+		if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(content)) && !retry {
+			if editErr == nil {
+				removeFile(path)
+				fmt.Fprintln(o.ErrOut, "Edit cancelled, no changes made.")
+
+				return nil
+			}
+
+			return fmt.Errorf("%w\nA copy of your changes has been stored to %q", editErr, path)
+		}
+
+		removeFile(path)
+
+		retry = false
+
+		obj, err := decode(edited, info, original)
+		if err == nil {
+			_, err = helper.Replace(ctx, info.Name, obj)
+		}
+
+		switch {
+		case err == nil:
+			return (&printers.NamePrinter{Operation: "edited"}).PrintObj(obj, o.Out)
+		case apierrors.IsConflict(err):
+			// Apply the edits to the latest version of the object.
+			latest, getErr := helper.Get(ctx, info.Name)
+			if getErr != nil {
+				return getErr
+			}
+
+			merged := resource.ThreeWayMerge(original, obj, latest)
+			setResourceVersion(merged, resourceVersion(latest))
+
+			var encodeErr error
+			if content, encodeErr = o.encode(merged); encodeErr != nil {
+				return encodeErr
+			}
+
+			original, retry = latest, true
+			editErr = fmt.Errorf("%w\nyour changes were applied to the latest version of the object, save the file to submit them", err)
+		case errors.As(err, new(*invalidEditError)), apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+			content, editErr = edited, err
+		default:
+			return err
+		}
+	}
+}
+
+// encode returns obj in the edit format.
+func (o *EditOptions) encode(obj map[string]interface{}) ([]byte, error) {
+	var printer printers.ResourcePrinter = &printers.YAMLPrinter{}
+	if o.OutputFormat == printers.OutputJSON {
+		printer = &printers.JSONPrinter{}
+	}
+
+	var buf bytes.Buffer
+	if err := printer.PrintObj(obj, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// invalidEditError is an edited object that can't be sent to the server.
+type invalidEditError struct {
+	reason string
+}
+
+func (e *invalidEditError) Error() string {
+	return e.reason
+}
+
+// decode returns the edited object, which must be the object of info. The
+// resource version of original is set when the user removed it so that the
+// update always checks it.
+func decode(data []byte, info resource.Info, original map[string]interface{}) (map[string]interface{}, error) {
+	objs, err := resource.Decode(data)
+	if err != nil {
+		return nil, &invalidEditError{reason: fmt.Sprintf("the edited file is invalid: %v", err)}
+	}
+
+	if len(objs) != 1 {
+		return nil, &invalidEditError{reason: fmt.Sprintf("the edited file must contain a single object, got %d", len(objs))}
+	}
+
+	obj := objs[0]
+
+	if printers.Kind(obj) != printers.Kind(original) {
+		return nil, &invalidEditError{reason: fmt.Sprintf("the kind of the object can't be changed from %q to %q",
+			printers.Kind(original), printers.Kind(obj))}
+	}
+
+	if printers.Name(obj) != info.Name {
+		return nil, &invalidEditError{reason: fmt.Sprintf("the name of the object can't be changed from %q to %q",
+			info.Name, printers.Name(obj))}
+	}
+
+	if len(resourceVersion(obj)) == 0 {
+		setResourceVersion(obj, resourceVersion(original))
+	}
+
+	return obj, nil
+}
+
+// writeError writes err as comments.
+func writeError(buf *bytes.Buffer, info resource.Info, err error) {
+	if err == nil {
+		return
+	}
+
+	fmt.Fprintf(buf, "# %s %q was not saved:\n", info.Resource.SingularName, info.Name)
+
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(buf, "# * %s\n", line)
+	}
+
+	buf.WriteString("#\n")
+}
+
+// stripComments removes the lines starting with '#'.
+func stripComments(data []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("#")) {
+			buf.Write(line)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func resourceVersion(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	rv, _ := metadata["resourceVersion"].(string)
+
+	return rv
+}
+
+func setResourceVersion(obj map[string]interface{}, rv string) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+
+	metadata["resourceVersion"] = rv
+}
+
+func removeFile(path string) {
+	if len(path) != 0 {
+		_ = os.Remove(path)
+	}
+}
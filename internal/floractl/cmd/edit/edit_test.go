@@ -0,0 +1,130 @@
+package edit
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cmdtesting "github.com/hanzhuoxian/flora/internal/floractl/cmd/testing"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// run runs floractl edit with args against server.
+func run(server *httptest.Server, args ...string) (string, string, error) {
+	return cmdtesting.Run(context.Background(), server, []cmdtesting.NewCmdFunc{NewCmdEdit}, append([]string{"edit"}, args...)...)
+}
+
+func TestEdit(t *testing.T) {
+	server, store := cmdtesting.NewServer(t, &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "leader"},
+		Spec:       v1.LeaseSpec{HolderIdentity: "node-a"},
+	})
+
+	dir := t.TempDir()
+
+	// The editors are scripts editing the file in place. The files reopened
+	// after an error are copied to reopened.
+	reopened := filepath.Join(dir, "reopened")
+	onReopen := `if grep -q "was not saved" "$1"; then cp "$1" ` + reopened + `; `
+
+	tests := []struct {
+		name     string
+		script   string
+		args     []string
+		want     string
+		wantErr  string
+		reopened []string
+		holder   string
+	}{
+		{
+			name:   "edit",
+			script: `sed -i 's/holderIdentity: node-a/holderIdentity: node-b/' "$1"`,
+			want:   "lease/leader edited\n",
+			holder: "node-b",
+		},
+		{
+			name:   "edit json",
+			script: `sed -i 's/"holderIdentity": "node-b"/"holderIdentity": "node-c"/' "$1"`,
+			args:   []string{"-o", "json"},
+			want:   "lease/leader edited\n",
+			holder: "node-c",
+		},
+		{
+			name: "invalid",
+			script: onReopen + `sed -i 's/leaseDurationSeconds: -1/leaseDurationSeconds: 30/' "$1"; ` +
+				`else sed -i 's/holderIdentity: node-c/holderIdentity: node-d\n  leaseDurationSeconds: -1/' "$1"; fi`,
+			want:     "lease/leader edited\n",
+			reopened: []string{"spec.leaseDurationSeconds: must be greater than or equal to 0", "holderIdentity: node-d"},
+			holder:   "node-d",
+		},
+		{
+			name: "conflict",
+			script: onReopen + `true; ` +
+				`else sed -i 's/holderIdentity: node-d/holderIdentity: node-e/; s/resourceVersion: .*/resourceVersion: "99"/' "$1"; fi`,
+			want:     "lease/leader edited\n",
+			reopened: []string{"Operation cannot be fulfilled", "holderIdentity: node-e", `resourceVersion: "4"`},
+			holder:   "node-e",
+		},
+		{
+			name: "give up",
+			script: onReopen + `true; ` +
+				`else sed -i 's/name: leader/name: other/' "$1"; fi`,
+			wantErr:  "A copy of your changes has been stored",
+			reopened: []string{"the name of the object can't be changed"},
+			holder:   "node-e",
+		},
+		{name: "unchanged", script: "true", holder: "node-e"},
+		{name: "emptied", script: `: > "$1"`, holder: "node-e"},
+	}
+
+	for _, tt := range tests {
+		script := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".sh")
+		if err := os.WriteFile(script, []byte("#!/bin/sh\n"+tt.script+"\n"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv("FLORACTL_EDITOR", script)
+		os.Remove(reopened)
+
+		out, errOut, err := run(server, append([]string{"lease", "leader"}, tt.args...)...)
+		if len(tt.wantErr) == 0 && err != nil || len(tt.wantErr) != 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			continue
+		}
+
+		if err != nil {
+			// Remove the copy of the changes.
+			if _, path, ok := strings.Cut(err.Error(), "stored to "); ok {
+				os.Remove(strings.Trim(path, `"`))
+			}
+		}
+
+		if len(tt.want) == 0 && len(tt.wantErr) == 0 && !strings.Contains(errOut, "Edit cancelled") {
+			t.Errorf("%s: stderr = %q, want the edit cancelled", tt.name, errOut)
+		}
+
+		if out != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, out, tt.want)
+		}
+
+		data, _ := os.ReadFile(reopened)
+		for _, want := range tt.reopened {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s: reopened file =\n%s\nwant %q", tt.name, data, want)
+			}
+		}
+
+		obj, err := store.Get(context.Background(), "leader")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if holder := obj.(*v1.Lease).Spec.HolderIdentity; holder != tt.holder {
+			t.Errorf("%s: holder = %q, want %q", tt.name, holder, tt.holder)
+		}
+	}
+}
@@ -0,0 +1,544 @@
+// Package get implements the floractl get command.
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/cli/printers"
+	"github.com/hanzhuoxian/flora/pkg/cli/resource"
+	"github.com/hanzhuoxian/flora/pkg/labels"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// defaultChunkSize is the number of objects requested per page of a list.
+const defaultChunkSize = 500
+
+// GetOptions contains the input to the get command.
+type GetOptions struct {
+	PrintFlags *printers.PrintFlags
+
+	LabelSelector  string
+	SortBy         string
+	Watch          bool
+	WatchOnly      bool
+	ChunkSize      int64
+	IgnoreNotFound bool
+
+	client *rest.RESTClient
+	infos  []resource.Info
+
+	options.IOStreams
+}
+
+var (
+	getLong = templates.LongDesc(`
+		Display one or many resources.
+
+		Prints a table of the most important information about the specified resources.
+		The columns are defined by the server, use -o wide to show the additional columns.
+		You can filter the list using a label selector and the --selector flag.
+
+		Use "-o json", "-o yaml", "-o jsonpath=..." or "-o go-template=..." to print the
+		objects instead, and --sort-by to sort them by a field.`)
+
+	getExample = templates.Examples(`
+		# List all leases in table output format
+		floractl get leases
+
+		# List all leases with more information (such as the holder renew time)
+		floractl get leases -o wide
+
+		# List a single lease in JSON output format
+		floractl get -o json lease leader
+
+		# List the names of the leases labeled env=prod, sorted by holder
+		floractl get leases -l env=prod --sort-by=.spec.holderIdentity -o name
+
+		# Print the holder of every lease
+		floractl get leases -o jsonpath='{range .items[*]}{.metadata.name}{"\t"}{.spec.holderIdentity}{"\n"}{end}'
+
+		# List the leases and watch for changes
+		floractl get leases --watch`)
+)
+
+// NewGetOptions returns a GetOptions with default chunk size 500.
+func NewGetOptions(ioStreams options.IOStreams) *GetOptions {
+	return &GetOptions{
+		PrintFlags: printers.NewTablePrintFlags(),
+		ChunkSize:  defaultChunkSize,
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdGet creates a command object for the generic "get" action, which
+// retrieves one or more resources from a server.
+func NewCmdGet(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewGetOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "get (TYPE [NAME ...] | TYPE/NAME ...) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Display one or many resources",
+		Long:                  getLong,
+		Example:               getExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd.Context(), f, args); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector,
+		"Selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin' and '!'.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy,
+		"If non-empty, sort list types using this field specification. The field specification is expressed as a "+
+			"JSONPath expression (e.g. '{.metadata.name}').")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch,
+		"After listing/getting the requested object, watch for changes.")
+	cmd.Flags().BoolVar(&o.WatchOnly, "watch-only", o.WatchOnly,
+		"Watch for changes to the requested object(s), without listing/getting first.")
+	cmd.Flags().Int64Var(&o.ChunkSize, "chunk-size", o.ChunkSize,
+		"Return large lists in chunks rather than all at once. Pass 0 to disable.")
+	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound,
+		"If the requested object does not exist the command will return exit code 0.")
+
+	return cmd
+}
+
+// Complete resolves the resources of args through the discovery of the
+// server.
+func (o *GetOptions) Complete(ctx context.Context, f options.RESTClientGetter, args []string) error {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client, err = rest.RESTClientFor(config); err != nil {
+		return err
+	}
+
+	mapper, err := resource.Discover(ctx, o.client)
+	if err != nil {
+		return err
+	}
+
+	o.infos, err = mapper.ParseArgs(args)
+
+	return err
+}
+
+// Validate checks the set of flags provided by the user.
+func (o *GetOptions) Validate() error {
+	if _, err := o.PrintFlags.ToPrinter(""); err != nil {
+		return err
+	}
+
+	if _, err := labels.Parse(o.LabelSelector); err != nil {
+		return err
+	}
+
+	if len(o.LabelSelector) != 0 && len(o.infos[0].Name) != 0 {
+		return errors.New("name cannot be provided when a selector is specified")
+	}
+
+	if o.Watch || o.WatchOnly {
+		for _, info := range o.infos[1:] {
+			if info.Resource.Name != o.infos[0].Resource.Name {
+				return errors.New("watch is only supported on individual resources and resource collections")
+			}
+		}
+	}
+
+	if o.ChunkSize < 0 {
+		return errors.New("--chunk-size must be 0 or greater")
+	}
+
+	return nil
+}
+
+// Run gets the objects and prints them, then watches for changes if asked.
+func (o *GetOptions) Run(ctx context.Context) error {
+	if o.Watch || o.WatchOnly {
+		return o.watch(ctx)
+	}
+
+	if o.PrintFlags.IsTable() {
+		return o.printTables(ctx)
+	}
+
+	printer, err := o.PrintFlags.ToPrinter("")
+	if err != nil {
+		return err
+	}
+
+	var objs []interface{}
+
+	for _, group := range groupByResource(o.infos) {
+		groupObjs, _, err := o.getObjects(ctx, group)
+		if err != nil {
+			return err
+		}
+
+		objs = append(objs, groupObjs...)
+	}
+
+	if len(o.SortBy) != 0 {
+		if err := printers.SortObjects(objs, o.SortBy); err != nil {
+			return err
+		}
+	}
+
+	// A single object named on the command line is printed as is.
+	if len(o.infos) == 1 && len(o.infos[0].Name) != 0 {
+		if len(objs) == 0 {
+			return nil
+		}
+
+		return printer.PrintObj(objs[0], o.Out)
+	}
+
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"metadata":   map[string]interface{}{},
+		"items":      objs,
+	}
+
+	return printer.PrintObj(list, o.Out)
+}
+
+// printTables prints a table per resource, with the columns defined by the
+// server.
+func (o *GetOptions) printTables(ctx context.Context) error {
+	rows := 0
+
+	for i, group := range groupByResource(o.infos) {
+		table, err := o.getTable(ctx, group)
+		if err != nil {
+			return err
+		}
+
+		if len(table.Rows) == 0 {
+			continue
+		}
+
+		if len(o.SortBy) != 0 {
+			if err := printers.SortRows(table, o.SortBy); err != nil {
+				return err
+			}
+		}
+
+		if i > 0 && rows > 0 {
+			fmt.Fprintln(o.Out)
+		}
+
+		printer, err := o.PrintFlags.ToPrinter("")
+		if err != nil {
+			return err
+		}
+
+		if err := printer.PrintObj(table, o.Out); err != nil {
+			return err
+		}
+
+		rows += len(table.Rows)
+	}
+
+	if rows == 0 && !o.IgnoreNotFound {
+		fmt.Fprintln(o.ErrOut, "No resources found")
+	}
+
+	return nil
+}
+
+// getTable returns the table of the objects of group.
+func (o *GetOptions) getTable(ctx context.Context, group []resource.Info) (*metav1.Table, error) {
+	table := &metav1.Table{}
+
+	err := o.get(ctx, group, true, func(data []byte) error {
+		page := &metav1.Table{}
+		if err := json.Unmarshal(data, page); err != nil {
+			return err
+		}
+
+		if len(table.ColumnDefinitions) == 0 {
+			table.ColumnDefinitions = page.ColumnDefinitions
+		}
+
+		table.Rows = append(table.Rows, page.Rows...)
+		table.ListMeta = page.ListMeta
+
+		return nil
+	})
+
+	return table, err
+}
+
+// getObjects returns the objects of group and the resource version of the
+// list.
+func (o *GetOptions) getObjects(ctx context.Context, group []resource.Info) ([]interface{}, string, error) {
+	var (
+		objs            []interface{}
+		resourceVersion string
+	)
+
+	err := o.get(ctx, group, false, func(data []byte) error {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+
+		items, isList := obj["items"].([]interface{})
+		if !isList {
+			objs = append(objs, obj)
+			return nil
+		}
+
+		objs = append(objs, items...)
+
+		if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+			resourceVersion, _ = metadata["resourceVersion"].(string)
+		}
+
+		return nil
+	})
+
+	return objs, resourceVersion, err
+}
+
+// get calls fn with each page of the list of the resource of group, or with
+// each object named in group.
+func (o *GetOptions) get(ctx context.Context, group []resource.Info, table bool, fn func(data []byte) error) error {
+	r := group[0].Resource
+
+	if len(group[0].Name) == 0 {
+		opts := &metav1.ListOptions{LabelSelector: o.LabelSelector, Limit: o.ChunkSize}
+
+		for {
+			data, err := o.request(r, table).VersionedParams(opts).Do(ctx).Raw()
+			if err != nil {
+				return err
+			}
+
+			if err := fn(data); err != nil {
+				return err
+			}
+
+			var page struct {
+				Metadata metav1.ListMeta `json:"metadata"`
+			}
+
+			if err := json.Unmarshal(data, &page); err != nil {
+				return err
+			}
+
+			if len(page.Metadata.Continue) == 0 {
+				return nil
+			}
+
+			opts.Continue = page.Metadata.Continue
+		}
+	}
+
+	for _, info := range group {
+		data, err := o.request(r, table).Name(info.Name).Do(ctx).Raw()
+		if err != nil {
+			if o.IgnoreNotFound && apierrors.IsNotFound(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// request returns a GET request of resource r, asking for a table if table
+// is set.
+func (o *GetOptions) request(r metav1.APIResource, table bool) *rest.Request {
+	req := o.client.Get().Resource(r.Name)
+	if table {
+		req.SetHeader("Accept", metav1.TableContentType+", application/json")
+	}
+
+	return req
+}
+
+// watch prints the objects, unless --watch-only is set, then the changes to
+// them until the server ends the watch or ctx is done.
+func (o *GetOptions) watch(ctx context.Context) error {
+	printer, err := o.PrintFlags.ToPrinter("")
+	if err != nil {
+		return err
+	}
+
+	table := o.PrintFlags.IsTable()
+	infos := []resource.Info{{Resource: o.infos[0].Resource}}
+
+	names := map[string]bool{}
+	for _, info := range o.infos {
+		if len(info.Name) != 0 {
+			names[info.Name] = true
+		}
+	}
+
+	// The list gives the resource version to watch from.
+	resourceVersion, err := o.listForWatch(ctx, printer, infos, names)
+	if err != nil {
+		return err
+	}
+
+	opts := &metav1.ListOptions{LabelSelector: o.LabelSelector, ResourceVersion: resourceVersion}
+
+	w, err := o.request(infos[0].Resource, table).VersionedParams(opts).Watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		event, err := w.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if err := o.printEvent(printer, table, event, names); err != nil {
+			return err
+		}
+	}
+}
+
+// listForWatch lists the objects to watch, prints them unless --watch-only
+// is set and returns the resource version of the list.
+func (o *GetOptions) listForWatch(ctx context.Context, printer printers.ResourcePrinter, infos []resource.Info,
+	names map[string]bool,
+) (string, error) {
+	if o.PrintFlags.IsTable() {
+		table, err := o.getTable(ctx, infos)
+		if err != nil || o.WatchOnly {
+			return table.ResourceVersion, err
+		}
+
+		if len(o.SortBy) != 0 {
+			if err := printers.SortRows(table, o.SortBy); err != nil {
+				return "", err
+			}
+		}
+
+		filterRows(table, names)
+
+		return table.ResourceVersion, printer.PrintObj(table, o.Out)
+	}
+
+	objs, resourceVersion, err := o.getObjects(ctx, infos)
+	if err != nil || o.WatchOnly {
+		return resourceVersion, err
+	}
+
+	if len(o.SortBy) != 0 {
+		if err := printers.SortObjects(objs, o.SortBy); err != nil {
+			return "", err
+		}
+	}
+
+	for _, obj := range objs {
+		if m, _ := obj.(map[string]interface{}); len(names) == 0 || names[printers.Name(m)] {
+			if err := printer.PrintObj(obj, o.Out); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return resourceVersion, nil
+}
+
+// printEvent prints the object of a watch event, a single row table if
+// table is set.
+func (o *GetOptions) printEvent(printer printers.ResourcePrinter, table bool, event metav1.WatchEvent,
+	names map[string]bool,
+) error {
+	if table {
+		t := &metav1.Table{}
+		if err := json.Unmarshal(event.Object, t); err != nil {
+			return err
+		}
+
+		if filterRows(t, names); len(t.Rows) == 0 {
+			return nil
+		}
+
+		return printer.PrintObj(t, o.Out)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(event.Object, &obj); err != nil {
+		return err
+	}
+
+	if len(names) != 0 && !names[printers.Name(obj)] {
+		return nil
+	}
+
+	return printer.PrintObj(obj, o.Out)
+}
+
+// filterRows keeps the rows of the objects named in names, if any.
+func filterRows(table *metav1.Table, names map[string]bool) {
+	if len(names) == 0 {
+		return
+	}
+
+	rows := table.Rows[:0]
+
+	for _, row := range table.Rows {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(row.Object, &obj); err == nil && names[printers.Name(obj)] {
+			rows = append(rows, row)
+		}
+	}
+
+	table.Rows = rows
+}
+
+// groupByResource splits infos into runs of the same resource.
+func groupByResource(infos []resource.Info) [][]resource.Info {
+	var groups [][]resource.Info
+
+	for i, info := range infos {
+		if i == 0 || info.Resource.Name != infos[i-1].Resource.Name || len(info.Name) == 0 {
+			groups = append(groups, []resource.Info{info})
+			continue
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], info)
+	}
+
+	return groups
+}
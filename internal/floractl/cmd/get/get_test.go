@@ -0,0 +1,149 @@
+package get
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/generic"
+	cmdtesting "github.com/hanzhuoxian/flora/internal/floractl/cmd/testing"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// newServer serves three leases.
+func newServer(t *testing.T) (*httptest.Server, *generic.Store) {
+	var leases []*v1.Lease
+
+	for _, l := range []struct {
+		name, holder, env string
+		transitions       int32
+	}{
+		{"scheduler", "node-b", "prod", 1},
+		{"controller", "node-a", "dev", 5},
+		{"leader", "node-c", "prod", 3},
+	} {
+		leases = append(leases, &v1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: l.name, Labels: map[string]string{"env": l.env}},
+			Spec:       v1.LeaseSpec{HolderIdentity: l.holder, LeaseTransitions: l.transitions},
+		})
+	}
+
+	return cmdtesting.NewServer(t, leases...)
+}
+
+// run runs floractl get with args against server.
+func run(ctx context.Context, server *httptest.Server, args ...string) (string, string, error) {
+	return cmdtesting.Run(ctx, server, []cmdtesting.NewCmdFunc{NewCmdGet}, append([]string{"get"}, args...)...)
+}
+
+func TestGet(t *testing.T) {
+	server, _ := newServer(t)
+
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			args: []string{"leases", "--no-headers", "--sort-by=.metadata.name"},
+			want: "controller   node-a   0s\nleader       node-c   0s\nscheduler    node-b   0s\n",
+		},
+		{
+			args: []string{"lease", "leader", "-o", "wide"},
+			want: "NAME     HOLDER   DURATION   TRANSITIONS   RENEWED   AGE\nleader   node-c   0s         3             <none>    0s\n",
+		},
+		{
+			args: []string{"leases", "-l", "env=prod", "-o", "name", "--sort-by", "{.spec.leaseTransitions}"},
+			want: "lease/scheduler\nlease/leader\n",
+		},
+		{
+			args: []string{"leases", "--chunk-size=1", "-o", "jsonpath={.items[*].metadata.name}"},
+			want: "controller leader scheduler",
+		},
+		{
+			args: []string{"lease/leader", "lease/controller", "-o", "go-template={{range .items}}{{.spec.holderIdentity}} {{end}}"},
+			want: "node-c node-a ",
+		},
+		{
+			args: []string{"lease", "leader", "-o", "yaml"},
+			want: "kind: Lease\n",
+		},
+		{
+			args: []string{"lease", "leader", "-L", "env", "--show-labels"},
+			want: "NAME     HOLDER   AGE   ENV    LABELS\nleader   node-c   0s    prod   env=prod\n",
+		},
+		{args: []string{"lease", "missing"}, wantErr: true},
+		{args: []string{"lease", "missing", "--ignore-not-found"}, want: ""},
+		{args: []string{"lease", "leader", "-l", "env=prod"}, wantErr: true},
+		{args: []string{"users"}, wantErr: true},
+		{args: []string{"leases", "-o", "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		out, _, err := run(context.Background(), server, tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("get %s: error = %v, want error %v", strings.Join(tt.args, " "), err, tt.wantErr)
+			continue
+		}
+
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("get %s = %q, want %q", strings.Join(tt.args, " "), out, tt.want)
+		}
+	}
+}
+
+func TestGetNoResources(t *testing.T) {
+	server, _ := newServer(t)
+
+	out, errOut, err := run(context.Background(), server, "leases", "-l", "env=none")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 0 || !strings.Contains(errOut, "No resources found") {
+		t.Errorf("get = %q, %q, want no resources found", out, errOut)
+	}
+}
+
+func TestGetWatch(t *testing.T) {
+	server, store := newServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan string)
+
+	go func() {
+		out, _, err := run(ctx, server, "lease", "leader", "--watch", "-o", "jsonpath={.spec.holderIdentity}{\"\\n\"}")
+		if err != nil {
+			t.Error(err)
+		}
+
+		done <- out
+	}()
+
+	// Wait for the watch to start, then change the lease.
+	time.Sleep(200 * time.Millisecond)
+
+	for _, name := range []string{"scheduler", "leader"} {
+		obj, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		obj.(*v1.Lease).Spec.HolderIdentity = "node-z"
+		if _, err := store.Update(ctx, name, obj, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+
+	if out := <-done; out != "node-c\nnode-z\n" {
+		t.Errorf("get --watch = %q, want the holder of the leader before and after the change", out)
+	}
+}
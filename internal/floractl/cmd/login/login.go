@@ -0,0 +1,236 @@
+// Package login implements the floractl login command.
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
+	"github.com/hanzhuoxian/flora/internal/floractl/util/term"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// LoginOptions contains the input to the login command.
+type LoginOptions struct {
+	Username string
+
+	configFlags *options.ConfigFlags
+	config      *rest.Config
+	client      *rest.RESTClient
+
+	options.IOStreams
+}
+
+var (
+	loginLong = templates.LongDesc(`
+		Log in to the API server with a username and a password.
+
+		The password is read from the terminal without being echoed, or from the first line of
+		the standard input when it is not a terminal. The token returned by the server is stored
+		in the user of the current context, replacing its other credentials, with a refresh token
+		renewing it transparently once it expires. Log in again when the refresh token expires.
+
+		When the context in use does not exist, it is created for the server and becomes the
+		current context. Without a current context, it is named USERNAME@SERVER. The context
+		USERNAME@SERVER is also used, and created if needed, when --server.address points at
+		another server than the cluster of the current context.`)
+
+	loginExample = templates.Examples(`
+		# Log in to the server of the current context, prompting for the username and the password
+		floractl login
+
+		# Log in as admin
+		floractl login -u admin
+
+		# Log in to a server, creating its context
+		floractl login -u admin -s https://flora.example.com:8443`)
+)
+
+// NewLoginOptions returns a LoginOptions reading the credentials from the
+// streams.
+func NewLoginOptions(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *LoginOptions {
+	return &LoginOptions{configFlags: configFlags, IOStreams: ioStreams}
+}
+
+// NewCmdLogin returns the login command.
+func NewCmdLogin(configFlags *options.ConfigFlags, ioStreams options.IOStreams) *cobra.Command {
+	o := NewLoginOptions(configFlags, ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "login [-u USERNAME]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Log in to the API server",
+		Long:                  loginLong,
+		Example:               loginExample,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Username, "username", "u", o.Username,
+		"The username to log in with, prompted for when not set.")
+
+	return cmd
+}
+
+// Complete creates a client of the server without credentials.
+func (o *LoginOptions) Complete() error {
+	var err error
+
+	if o.config, err = o.configFlags.ToRESTConfig(); err != nil {
+		return err
+	}
+
+	o.client, err = rest.RESTClientFor(rest.AnonymousClientConfig(o.config))
+
+	return err
+}
+
+// Run prompts for the credentials, logs in and stores the token.
+func (o *LoginOptions) Run(ctx context.Context) error {
+	if len(o.Username) == 0 {
+		fmt.Fprint(o.Out, "Username: ")
+
+		username, err := term.ReadLine(o.In)
+		if err != nil {
+			return fmt.Errorf("unable to read the username: %w", err)
+		}
+
+		o.Username = username
+	}
+
+	if len(o.Username) == 0 {
+		return errors.New("a username is required")
+	}
+
+	fmt.Fprint(o.Out, "Password: ")
+
+	password, err := term.ReadPassword(o.In, o.Out)
+	if err != nil {
+		return fmt.Errorf("unable to read the password: %w", err)
+	}
+
+	token, err := rest.Login(ctx, o.client, o.Username, password)
+	if err != nil {
+		return fmt.Errorf("unable to log in as %q: %w", o.Username, err)
+	}
+
+	contextName, err := o.saveToken(token)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Logged in as %q in context %q, the token expires at %s.\n",
+		o.Username, contextName, token.ExpiresAt.Local().Format(time.RFC3339))
+
+	return nil
+}
+
+// saveToken stores token in the user of the context in use, creating the
+// context, its cluster and its user when they do not exist. A server other
+// than the one of the current context gets the context USERNAME@SERVER,
+// unless the context was set by --context. It returns the name of the
+// context.
+func (o *LoginOptions) saveToken(token *v1.Token) (string, error) {
+	contextName := o.configFlags.ToRawConfigLoader().ContextName()
+	contextFlag := o.configFlags.Context != nil && len(*o.configFlags.Context) != 0
+
+	err := o.configFlags.ToLoadingRules().Modify(func(config *clientcmd.Config) error {
+		context, ok := config.Contexts[contextName]
+		if ok && !contextFlag {
+			if cluster, found := config.Clusters[context.Cluster]; !found || cluster.Server != o.config.Host {
+				contextName, ok = "", false
+			}
+		}
+
+		if !ok {
+			clusterName, err := o.clusterName()
+			if err != nil {
+				return err
+			}
+
+			if len(contextName) == 0 {
+				contextName = o.Username + "@" + clusterName
+			}
+
+			if context, ok = config.Contexts[contextName]; !ok {
+				if _, found := config.Clusters[clusterName]; !found {
+					// The paths of the config file are relative to its
+					// directory, the one of the flag to the working directory.
+					ca := o.config.CAFile
+					if len(ca) != 0 {
+						if ca, err = filepath.Abs(ca); err != nil {
+							return fmt.Errorf("failed to resolve %s: %w", o.config.CAFile, err)
+						}
+					}
+
+					config.Clusters[clusterName] = &clientcmd.Cluster{
+						Server:                o.config.Host,
+						TLSServerName:         o.config.ServerName,
+						InsecureSkipTLSVerify: o.config.Insecure,
+						CertificateAuthority:  ca,
+					}
+				}
+
+				context = &clientcmd.Context{Cluster: clusterName}
+				config.Contexts[contextName] = context
+			}
+
+			config.CurrentContext = contextName
+		}
+
+		if len(context.AuthInfo) == 0 {
+			context.AuthInfo = contextName
+		}
+
+		authInfo, ok := config.AuthInfos[context.AuthInfo]
+		if !ok {
+			authInfo = &clientcmd.AuthInfo{}
+			config.AuthInfos[context.AuthInfo] = authInfo
+		}
+
+		// The token replaces the other credentials, the client certificate
+		// is still used to connect.
+		authInfo.Username, authInfo.Password, authInfo.SecretID, authInfo.SecretKey = "", "", "", ""
+		authInfo.Exec = nil
+		authInfo.SetToken(token)
+
+		return nil
+	})
+
+	return contextName, err
+}
+
+// clusterName returns the name of the cluster of the server, its host and
+// port.
+func (o *LoginOptions) clusterName() (string, error) {
+	u, err := url.Parse(o.config.Host)
+	if err == nil && len(u.Host) == 0 {
+		// The address has no scheme.
+		u, err = url.Parse("//" + o.config.Host)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid server address %q", o.config.Host)
+	}
+
+	return u.Host, nil
+}
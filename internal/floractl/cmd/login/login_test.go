@@ -0,0 +1,243 @@
+package login
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/authentication"
+	"github.com/hanzhuoxian/flora/pkg/auth"
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+)
+
+// run runs floractl login with args and in as standard input.
+func run(configPath, server, in string, args ...string) (string, error) {
+	configFlags := options.NewConfigFlags(false)
+	ioStreams, stdin, out, _ := options.NewTestIOStreams()
+	stdin.WriteString(in)
+
+	root := &cobra.Command{Use: "floractl", SilenceErrors: true, SilenceUsage: true}
+	configFlags.AddFlags(root.PersistentFlags())
+	root.AddCommand(NewCmdLogin(configFlags, ioStreams))
+	root.SetArgs(append([]string{"--config", configPath, "--server.address", server, "login"}, args...))
+
+	err := root.ExecuteContext(context.Background())
+
+	return out.String(), err
+}
+
+// newServer serves the login of the user admin with the password secret
+// through a server started by start, e.g. httptest.NewServer.
+func newServer(t *testing.T, start func(http.Handler) *httptest.Server) (*httptest.Server, *authentication.TokenIssuer) {
+	t.Helper()
+
+	hash, err := auth.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwordFile := filepath.Join(t.TempDir(), "passwords")
+	if err := os.WriteFile(passwordFile, []byte("admin:"+hash+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	passwords, err := authentication.LoadPasswordFile(passwordFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := authentication.GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer, err := authentication.NewTokenIssuer([][]byte{key}, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	authentication.InstallLogin(mux, passwords, issuer)
+
+	server := start(mux)
+	t.Cleanup(server.Close)
+
+	return server, issuer
+}
+
+func TestLogin(t *testing.T) {
+	server, issuer := newServer(t, httptest.NewServer)
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := run(configPath, server.URL, "wrong\n", "-u", "admin"); err == nil ||
+		!strings.Contains(err.Error(), "invalid username or password") {
+		t.Errorf("login with a wrong password error = %v, want invalid username or password", err)
+	}
+
+	// Without a context, one is created for the server.
+	out, err := run(configPath, server.URL, "admin\nsecret\n")
+	if err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	contextName := "admin@" + host
+
+	if want := "Username: Password: Logged in as \"admin\" in context \"" + contextName + "\""; !strings.HasPrefix(out, want) {
+		t.Errorf("login output = %q, want prefix %q", out, want)
+	}
+
+	config, err := clientcmd.LoadFromFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	context, ok := config.Contexts[contextName]
+	if !ok || config.CurrentContext != contextName || config.Clusters[host].Server != server.URL {
+		t.Fatalf("config = %+v, want the context %q of the server", config, contextName)
+	}
+
+	authInfo := config.AuthInfos[context.AuthInfo]
+	if authInfo == nil || len(authInfo.Token) == 0 || len(authInfo.RefreshToken) == 0 || authInfo.TokenExpiry.IsZero() {
+		t.Fatalf("user = %+v, want the tokens", authInfo)
+	}
+
+	if user, err := issuer.AuthenticateToken(authInfo.Token); err != nil || user.Name != "admin" {
+		t.Errorf("stored token user = %v, %v, want admin", user, err)
+	}
+
+	// The token replaces the credentials of the user of the current context.
+	authInfo.Token, authInfo.RefreshToken, authInfo.SecretID, authInfo.SecretKey = "", "", "id", "key"
+	if err := clientcmd.WriteToFile(*config, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := run(configPath, server.URL, "secret\n", "-u", "admin"); err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+
+	if config, err = clientcmd.LoadFromFile(configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	authInfo = config.AuthInfos[context.AuthInfo]
+	if len(config.Contexts) != 1 || len(authInfo.Token) == 0 || len(authInfo.SecretID) != 0 || len(authInfo.SecretKey) != 0 {
+		t.Errorf("config = %+v, user = %+v, want the token only", config, authInfo)
+	}
+}
+
+func TestLoginOtherServer(t *testing.T) {
+	first, _ := newServer(t, httptest.NewServer)
+	second, _ := newServer(t, httptest.NewServer)
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	firstContext := "admin@" + strings.TrimPrefix(first.URL, "http://")
+	secondContext := "admin@" + strings.TrimPrefix(second.URL, "http://")
+
+	tests := []struct {
+		server  string
+		args    []string
+		context string
+	}{
+		{server: first.URL, context: firstContext},
+		// Another server gets its own context.
+		{server: second.URL, context: secondContext},
+		// The context of a server is reused.
+		{server: first.URL, context: firstContext},
+		// The context set by --context is used whatever its server.
+		{server: second.URL, args: []string{"--context", firstContext}, context: firstContext},
+	}
+
+	for i, tt := range tests {
+		out, err := run(configPath, tt.server, "secret\n", append([]string{"-u", "admin"}, tt.args...)...)
+		if err != nil {
+			t.Fatalf("login %d error = %v", i, err)
+		}
+
+		if want := "in context \"" + tt.context + "\""; !strings.Contains(out, want) {
+			t.Errorf("login %d output = %q, want %q", i, out, want)
+		}
+	}
+
+	config, err := clientcmd.LoadFromFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Contexts) != 2 || config.CurrentContext != firstContext {
+		t.Errorf("config = %+v, want the contexts of both servers and the first one current", config)
+	}
+
+	for _, name := range []string{firstContext, secondContext} {
+		if authInfo := config.AuthInfos[config.Contexts[name].AuthInfo]; authInfo == nil || len(authInfo.Token) == 0 {
+			t.Errorf("user of %s = %+v, want a token", name, authInfo)
+		}
+	}
+}
+
+func TestLoginCertificateAuthority(t *testing.T) {
+	server, _ := newServer(t, httptest.NewTLSServer)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The certificate authority is given relative to the working directory.
+	if err := os.Chdir(filepath.Join(dir, "..")); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if _, err := run(configPath, server.URL, "secret\n", "-u", "admin",
+		"--server.certificate-authority", filepath.Join(filepath.Base(dir), "ca.crt")); err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+
+	config, err := clientcmd.LoadFromFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := config.Clusters[strings.TrimPrefix(server.URL, "https://")]
+	if want := filepath.Join(dir, "ca.crt"); cluster == nil || cluster.CertificateAuthority != want {
+		t.Fatalf("cluster = %+v, want the certificate authority %s", cluster, want)
+	}
+
+	// The stored certificate authority verifies the server.
+	if _, err := run(configPath, server.URL, "secret\n", "-u", "admin"); err != nil {
+		t.Errorf("login with the stored certificate authority error = %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package testing holds the fake apiserver the tests of the floractl commands
+// run against.
+package testing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hanzhuoxian/flora/internal/apiserver/endpoints"
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/generic"
+	"github.com/hanzhuoxian/flora/internal/apiserver/registry/lease"
+	"github.com/hanzhuoxian/flora/internal/apiserver/storage/memory"
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// NewCmdFunc creates a floractl command.
+type NewCmdFunc func(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command
+
+// NewServer serves the leases of an in-memory storage holding leases. The
+// server is closed at the end of the test.
+func NewServer(t *testing.T, leases ...*v1.Lease) (*httptest.Server, *generic.Store) {
+	t.Helper()
+
+	store := lease.NewStore(memory.New())
+	mux := http.NewServeMux()
+	endpoints.InstallREST(mux, "/v1", store)
+	endpoints.InstallDiscovery(mux, "/v1", v1.SchemeGroupVersion.String(), store)
+
+	for _, obj := range leases {
+		if _, err := store.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, store
+}
+
+// Run runs floractl with args against server, without a config file. The
+// root command holds the commands created by newCmds. Run returns the
+// standard and error outputs of floractl.
+func Run(ctx context.Context, server *httptest.Server, newCmds []NewCmdFunc, args ...string) (string, string, error) {
+	configFlags := options.NewConfigFlags(false)
+	ioStreams, _, out, errOut := options.NewTestIOStreams()
+
+	root := &cobra.Command{Use: "floractl", SilenceErrors: true, SilenceUsage: true}
+	configFlags.AddFlags(root.PersistentFlags())
+
+	for _, newCmd := range newCmds {
+		root.AddCommand(newCmd(configFlags, ioStreams))
+	}
+
+	root.SetArgs(append([]string{"--config", "/dev/null", "--server.address", server.URL}, args...))
+
+	err := root.ExecuteContext(ctx)
+
+	return out.String(), errOut.String(), err
+}
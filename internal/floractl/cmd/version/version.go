@@ -1,23 +1,207 @@
+// Package version implements the floractl version command.
 package version
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
 	"github.com/hanzhuoxian/flora/internal/floractl/util/templates"
 	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/rest"
 	"github.com/hanzhuoxian/flora/pkg/version"
 )
 
+// supportedMinorVersionSkew is the maximum minor version difference between
+// floractl and the apiserver.
+const supportedMinorVersionSkew = 1
+
 type Version struct {
 	ClientVersion *version.Info `json:"clientVersion,omitempty" yaml:"clientVersion,omitempty"`
 	ServerVersion *version.Info `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
 }
 
 var versionExample = templates.Examples(`
-# Print the client and server versions for the current context
-iamctl version`)
+		# Print the client and server versions for the current context
+		floractl version`)
 
 type Options struct {
 	ClientOnly bool
 	Short      bool
 	Output     string
+
+	client *rest.RESTClient
+	// clientErr tells why no client of the server could be created.
+	clientErr error
+
 	options.IOStreams
 }
+
+// NewOptions returns initialized Options.
+func NewOptions(ioStreams options.IOStreams) *Options {
+	return &Options{IOStreams: ioStreams}
+}
+
+// NewCmdVersion returns a cobra command for fetching versions.
+func NewCmdVersion(f options.RESTClientGetter, ioStreams options.IOStreams) *cobra.Command {
+	o := NewOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:     "version",
+		Short:   "Print the client and server version information",
+		Long:    "Print the client and server version information for the current context",
+		Example: versionExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return o.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.ClientOnly, "client", o.ClientOnly,
+		"If true, shows client version only (no server required).")
+	cmd.Flags().BoolVar(&o.Short, "short", o.Short, "If true, print just the version number.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "One of 'yaml' or 'json'.")
+
+	return cmd
+}
+
+// Complete completes all the required options.
+func (o *Options) Complete(f options.RESTClientGetter) error {
+	if o.ClientOnly {
+		return nil
+	}
+
+	// The client version is printed even when there is no server to ask.
+	config, err := f.ToRESTConfig()
+	if err == nil {
+		o.client, err = rest.RESTClientFor(config)
+	}
+
+	o.clientErr = err
+
+	return nil
+}
+
+// Validate validates the provided options.
+func (o *Options) Validate() error {
+	if o.Output != "" && o.Output != "yaml" && o.Output != "json" {
+		return errors.New(`--output must be 'yaml' or 'json'`)
+	}
+
+	return nil
+}
+
+// Run executes version command.
+func (o *Options) Run(ctx context.Context) error {
+	var (
+		serverErr   error
+		versionInfo Version
+	)
+
+	clientVersion := version.Get()
+	versionInfo.ClientVersion = &clientVersion
+
+	switch {
+	case o.ClientOnly:
+	case o.clientErr != nil:
+		serverErr = o.clientErr
+	case o.client != nil:
+		versionInfo.ServerVersion, serverErr = serverVersion(ctx, o.client)
+	}
+
+	switch o.Output {
+	case "":
+		if o.Short {
+			fmt.Fprintf(o.Out, "Client Version: %s\n", clientVersion.GitVersion)
+
+			if versionInfo.ServerVersion != nil {
+				fmt.Fprintf(o.Out, "Server Version: %s\n", versionInfo.ServerVersion.GitVersion)
+			}
+		} else {
+			fmt.Fprintf(o.Out, "Client Version: %#v\n", clientVersion)
+
+			if versionInfo.ServerVersion != nil {
+				fmt.Fprintf(o.Out, "Server Version: %#v\n", *versionInfo.ServerVersion)
+			}
+		}
+	case "yaml":
+		marshalled, err := yaml.Marshal(&versionInfo)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(o.Out, string(marshalled))
+	case "json":
+		marshalled, err := json.MarshalIndent(&versionInfo, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(o.Out, string(marshalled))
+	}
+
+	if versionInfo.ServerVersion != nil {
+		if warning := skewWarning(clientVersion.GitVersion, versionInfo.ServerVersion.GitVersion); len(warning) != 0 {
+			fmt.Fprintln(o.ErrOut, warning)
+		}
+	}
+
+	return serverErr
+}
+
+// serverVersion fetches the version of the apiserver from /version.
+func serverVersion(ctx context.Context, client *rest.RESTClient) (*version.Info, error) {
+	info := &version.Info{}
+	if err := client.Get().AbsPath("/version").Do(ctx).Into(info); err != nil {
+		return nil, fmt.Errorf("failed to get the server version: %w", err)
+	}
+
+	return info, nil
+}
+
+// semver matches the major and minor versions of a git version, e.g.
+// v1.2.3-alpha.1+abc.
+var semver = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+
+// skewWarning returns a warning when the client and the server versions
+// differ by more than the supported minor version skew.
+func skewWarning(client, server string) string {
+	cm := semver.FindStringSubmatch(client)
+	sm := semver.FindStringSubmatch(server)
+
+	if cm == nil || sm == nil {
+		return ""
+	}
+
+	clientMajor, _ := strconv.Atoi(cm[1])
+	clientMinor, _ := strconv.Atoi(cm[2])
+	serverMajor, _ := strconv.Atoi(sm[1])
+	serverMinor, _ := strconv.Atoi(sm[2])
+
+	skew := clientMinor - serverMinor
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if clientMajor == serverMajor && skew <= supportedMinorVersionSkew {
+		return ""
+	}
+
+	return fmt.Sprintf("WARNING: version difference between client (%d.%d) and server (%d.%d) "+
+		"exceeds the supported minor version skew of +/-%d",
+		clientMajor, clientMinor, serverMajor, serverMinor, supportedMinorVersionSkew)
+}
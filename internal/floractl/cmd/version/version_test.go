@@ -0,0 +1,155 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+	"github.com/hanzhuoxian/flora/pkg/runtime"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+	"github.com/hanzhuoxian/flora/pkg/version"
+)
+
+// fakeRESTClientGetter returns the config of a test server.
+type fakeRESTClientGetter struct {
+	host string
+}
+
+func (f *fakeRESTClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return &rest.Config{
+		Host: f.host,
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &scheme.GroupVersion{Version: "v1"},
+			Negotiator:   runtime.NewSimpleClientNegotiator(),
+		},
+	}, nil
+}
+
+func (f *fakeRESTClientGetter) ToRawConfigLoader() options.ClientConfiger {
+	return nil
+}
+
+func TestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(version.Info{GitVersion: "v9.8.7"})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []string
+		notWant string
+		wantErr bool
+	}{
+		{
+			name: "short",
+			opts: Options{Short: true},
+			want: []string{"Client Version: " + version.Get().GitVersion, "Server Version: v9.8.7"},
+		},
+		{
+			name:    "client only",
+			opts:    Options{ClientOnly: true, Short: true},
+			want:    []string{"Client Version: "},
+			notWant: "Server Version",
+		},
+		{
+			name: "json",
+			opts: Options{Output: "json"},
+			want: []string{`"clientVersion": {`, `"serverVersion": {`, `"gitVersion": "v9.8.7"`},
+		},
+		{
+			name: "yaml",
+			opts: Options{Output: "yaml"},
+			want: []string{"clientVersion:", "serverVersion:", "gitVersion: v9.8.7"},
+		},
+		{
+			name:    "invalid output",
+			opts:    Options{Output: "xml"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ioStreams, _, out, _ := options.NewTestIOStreams()
+			o := tt.opts
+			o.IOStreams = ioStreams
+
+			if err := o.Complete(&fakeRESTClientGetter{host: server.URL}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := o.Validate()
+			if err == nil {
+				err = o.Run(context.Background())
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output = %q, want %q", out.String(), want)
+				}
+			}
+
+			if len(tt.notWant) != 0 && strings.Contains(out.String(), tt.notWant) {
+				t.Errorf("output = %q, want no %q", out.String(), tt.notWant)
+			}
+		})
+	}
+}
+
+func TestVersionServerError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	ioStreams, _, out, _ := options.NewTestIOStreams()
+	o := NewOptions(ioStreams)
+	o.Short = true
+
+	if err := o.Complete(&fakeRESTClientGetter{host: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Run(context.Background()); err == nil {
+		t.Errorf("Run() succeeded, want the error of the server")
+	}
+
+	if !strings.Contains(out.String(), "Client Version: ") {
+		t.Errorf("output = %q, want the client version", out.String())
+	}
+}
+
+func TestSkewWarning(t *testing.T) {
+	tests := []struct {
+		client string
+		server string
+		warn   bool
+	}{
+		{"v1.2.0", "v1.2.5", false},
+		{"v1.3.0", "v1.2.0", false},
+		{"v1.1.0-alpha.1", "v1.2.0+abc", false},
+		{"v1.4.0", "v1.2.0", true},
+		{"v2.0.0", "v1.0.0", true},
+		{"v0.0.0-master+$Format:%h$", "unknown", false},
+	}
+
+	for _, tt := range tests {
+		if got := skewWarning(tt.client, tt.server); (len(got) != 0) != tt.warn {
+			t.Errorf("skewWarning(%q, %q) = %q, want warning %v", tt.client, tt.server, got, tt.warn)
+		}
+	}
+}
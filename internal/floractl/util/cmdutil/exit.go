@@ -0,0 +1,46 @@
+// Package cmdutil holds the helpers shared by the floractl commands.
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultErrorExitCode is the exit status of floractl when a command fails.
+const DefaultErrorExitCode = 1
+
+// ExitError is an error ending floractl with Code as exit status. Err may be
+// nil when the command already reported the failure.
+type ExitError struct {
+	Err  error
+	Code int
+}
+
+// Error implements error.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit status of floractl for the error returned by a
+// command: 0 without error, the code of an ExitError or DefaultErrorExitCode.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return DefaultErrorExitCode
+}
@@ -0,0 +1,85 @@
+// Package editor launches the text editor of the user on temporary files.
+package editor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hanzhuoxian/flora/pkg/cli/options"
+)
+
+// defaultEditor is the editor launched when none of the environment
+// variables is set.
+const defaultEditor = "vi"
+
+// Editor launches a text editor.
+type Editor struct {
+	// Args is the editor command and its arguments, the file to edit is
+	// appended.
+	Args []string
+}
+
+// NewDefaultEditor returns the editor set by the first non-empty
+// environment variable of envs, e.g. FLORACTL_EDITOR and EDITOR, or vi.
+func NewDefaultEditor(envs []string) Editor {
+	for _, env := range envs {
+		if args := strings.Fields(os.Getenv(env)); len(args) != 0 {
+			return Editor{Args: args}
+		}
+	}
+
+	return Editor{Args: []string{defaultEditor}}
+}
+
+// Launch opens path in the editor connected to the streams and waits for
+// the editor to exit.
+func (e Editor) Launch(path string, streams options.IOStreams) error {
+	if len(e.Args) == 0 {
+		return fmt.Errorf("no editor defined, can't open %s", path)
+	}
+
+	args := append(append([]string{}, e.Args[1:]...), path)
+
+	cmd := exec.Command(e.Args[0], args...)
+	cmd.Stdin = streams.In
+	cmd.Stdout = streams.Out
+	cmd.Stderr = streams.ErrOut
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("unable to launch the editor %q: %w", strings.Join(e.Args, " "), err)
+	}
+
+	return nil
+}
+
+// LaunchTempFile writes r to a temporary file whose name starts with prefix
+// and ends with suffix, opens it in the editor and returns the edited
+// content and the path of the file. The caller removes the file.
+func (e Editor) LaunchTempFile(prefix, suffix string, r io.Reader, streams options.IOStreams) ([]byte, string, error) {
+	f, err := os.CreateTemp("", prefix+"*"+suffix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := f.Name()
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return nil, path, err
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, path, err
+	}
+
+	if err := e.Launch(path, streams); err != nil {
+		return nil, path, err
+	}
+
+	data, err := os.ReadFile(path)
+
+	return data, path, err
+}
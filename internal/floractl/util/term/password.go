@@ -0,0 +1,72 @@
+package term
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moby/term"
+)
+
+// ReadLine reads a line of in, without the line ending. It reads one byte at
+// a time so that the next lines are left to the next reads.
+func ReadLine(in io.Reader) (string, error) {
+	var (
+		line strings.Builder
+		b    [1]byte
+	)
+
+	for {
+		n, err := in.Read(b[:])
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+
+			line.WriteByte(b[0])
+		}
+
+		if errors.Is(err, io.EOF) {
+			if line.Len() == 0 {
+				return "", io.ErrUnexpectedEOF
+			}
+
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSuffix(line.String(), "\r"), nil
+}
+
+// ReadPassword reads a line of in without echoing it when in is a
+// terminal. The line ending the user typed is then written to out.
+func ReadPassword(in io.Reader, out io.Writer) (string, error) {
+	fd, isTerminal := term.GetFdInfo(in)
+	if !isTerminal {
+		return ReadLine(in)
+	}
+
+	state, err := term.SaveState(fd)
+	if err != nil {
+		return "", err
+	}
+
+	if err := term.DisableEcho(fd, state); err != nil {
+		return "", err
+	}
+
+	password, err := ReadLine(in)
+
+	if restoreErr := term.RestoreTerminal(fd, state); restoreErr != nil && err == nil {
+		err = restoreErr
+	}
+
+	fmt.Fprintln(out)
+
+	return password, err
+}
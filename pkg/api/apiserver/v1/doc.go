@@ -0,0 +1,2 @@
+// Package v1 contains the resources served by flora-apiserver.
+package v1
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// ExecCredential is exchanged with the credential plugins run by the
+// clients: the client passes it with the Spec set in the FLORA_EXEC_INFO
+// environment variable, and the plugin prints it with the Status set.
+type ExecCredential struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Spec holds what the client knows about the request to authenticate.
+	Spec ExecCredentialSpec `json:"spec"`
+	// Status holds the credentials returned by the plugin.
+	Status *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// ExecCredentialSpec holds the information passed to a credential plugin.
+type ExecCredentialSpec struct {
+	// Server is the address of the apiserver.
+	Server string `json:"server,omitempty"`
+}
+
+// ExecCredentialStatus holds the credentials returned by a credential
+// plugin, either a bearer token or a secret id and key.
+type ExecCredentialStatus struct {
+	Token     string `json:"token,omitempty"`
+	SecretID  string `json:"secretID,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+	// ExpirationTimestamp, if set, is when the plugin is run again. Without
+	// it, the credentials are used until the server rejects them.
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
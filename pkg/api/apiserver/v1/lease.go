@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// Lease defines a lease concept, used by leader election to make sure only
+// one replica of a component is active at a time.
+type Lease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec contains the specification of the Lease.
+	Spec LeaseSpec `json:"spec,omitempty"`
+}
+
+// LeaseSpec is a specification of a Lease.
+type LeaseSpec struct {
+	// HolderIdentity contains the identity of the holder of a current lease.
+	HolderIdentity string `json:"holderIdentity,omitempty"`
+	// LeaseDurationSeconds is a duration that candidates for a lease need
+	// to wait to force acquire it. This is measure against time of last
+	// observed RenewTime.
+	LeaseDurationSeconds int32 `json:"leaseDurationSeconds,omitempty"`
+	// AcquireTime is a time when the current lease was acquired.
+	AcquireTime *time.Time `json:"acquireTime,omitempty"`
+	// RenewTime is a time when the current holder of a lease has last
+	// updated the lease.
+	RenewTime *time.Time `json:"renewTime,omitempty"`
+	// LeaseTransitions is the number of transitions of a lease between
+	// holders.
+	LeaseTransitions int32 `json:"leaseTransitions,omitempty"`
+}
+
+// LeaseList is a list of Lease objects.
+type LeaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of schema objects.
+	Items []Lease `json:"items"`
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// LoginRequest is the body of a POST /login request, which exchanges the
+// credentials of a user for a Token.
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest is the body of a POST /login/refresh request, which
+// exchanges a refresh token for a new Token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Token is the response of a login or a refresh.
+type Token struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// AccessToken is sent as a bearer token to authenticate the requests.
+	AccessToken string `json:"accessToken"`
+	// ExpiresAt is the time the access token expires.
+	ExpiresAt time.Time `json:"expiresAt"`
+	// RefreshToken gets a new Token once the access token expired, until
+	// it expires itself at RefreshExpiresAt.
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
@@ -0,0 +1,19 @@
+package v1
+
+import "github.com/hanzhuoxian/flora/pkg/scheme"
+
+// GroupName is the group name used in this package.
+const GroupName = "apiserver"
+
+// SchemeGroupVersion is group version used to register these objects.
+var SchemeGroupVersion = scheme.GroupVersion{Group: GroupName, Version: "v1"}
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource.
+func Resource(resource string) scheme.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+// Kind takes an unqualified kind and returns a Group qualified GroupKind.
+func Kind(kind string) scheme.GroupKind {
+	return SchemeGroupVersion.WithKind(kind).GroupKind()
+}
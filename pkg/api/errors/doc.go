@@ -0,0 +1,4 @@
+// Package errors provides detailed error types for api field validation.
+// The server encodes them as metav1.Status objects and the client decodes
+// them back, so both sides can inspect the reason of a failed request.
+package errors
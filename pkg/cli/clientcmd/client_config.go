@@ -0,0 +1,193 @@
+package clientcmd
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+	"github.com/hanzhuoxian/flora/pkg/runtime"
+)
+
+const (
+	// DefaultTimeout is the timeout of the requests when none is configured.
+	DefaultTimeout = 30 * time.Second
+	// DefaultRetryInterval is the interval between the retries when none is
+	// configured.
+	DefaultRetryInterval = time.Second
+)
+
+// ConfigOverrides holds the values overriding the ones of the config, e.g.
+// from the command line.
+type ConfigOverrides struct {
+	// CurrentContext replaces the current context of the config.
+	CurrentContext string
+	// Apply modifies copies of the cluster and the user of the context.
+	Apply func(cluster *Cluster, authInfo *AuthInfo) error
+}
+
+// ClientConfig builds the rest.Config of the current context of a config.
+type ClientConfig struct {
+	config    Config
+	overrides ConfigOverrides
+	// configAccess, if set, is where the tokens refreshed by the clients
+	// are saved.
+	configAccess *ClientConfigLoadingRules
+}
+
+// NewClientConfig returns the ClientConfig of config. The tokens refreshed
+// by the clients are saved to the files of configAccess, if set.
+func NewClientConfig(config *Config, overrides ConfigOverrides, configAccess *ClientConfigLoadingRules) *ClientConfig {
+	return &ClientConfig{config: *config, overrides: overrides, configAccess: configAccess}
+}
+
+// RawConfig returns the config the ClientConfig was created from, without
+// the overrides.
+func (c *ClientConfig) RawConfig() (Config, error) {
+	return c.config, nil
+}
+
+// ContextName returns the name of the context in use.
+func (c *ClientConfig) ContextName() string {
+	if len(c.overrides.CurrentContext) != 0 {
+		return c.overrides.CurrentContext
+	}
+
+	return c.config.CurrentContext
+}
+
+// ClientConfig returns a rest.Config ready to create a client of the
+// apiserver API.
+func (c *ClientConfig) ClientConfig() (*rest.Config, error) {
+	cluster, authInfo, err := c.context()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cluster.Server) == 0 {
+		return nil, fmt.Errorf("no server address found, set a context in %s or use --server.address",
+			RecommendedHomeFile())
+	}
+
+	timeout := cluster.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	retryInterval := cluster.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = DefaultRetryInterval
+	}
+
+	config := &rest.Config{
+		Host: cluster.Server,
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &v1.SchemeGroupVersion,
+			Negotiator:   runtime.NewSimpleClientNegotiator(),
+		},
+		Username:     authInfo.Username,
+		Password:     authInfo.Password,
+		SecretID:     authInfo.SecretID,
+		SecretKey:    authInfo.SecretKey,
+		BearerToken:  authInfo.Token,
+		RefreshToken: authInfo.RefreshToken,
+		TokenExpiry:  authInfo.TokenExpiry,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CertFile:   authInfo.ClientCertificate,
+			KeyFile:    authInfo.ClientKey,
+			CAFile:     cluster.CertificateAuthority,
+		},
+		Timeout:       timeout,
+		MaxRetries:    cluster.MaxRetries,
+		RetryInterval: retryInterval,
+	}
+
+	if exec := authInfo.Exec; exec != nil {
+		config.ExecProvider = &rest.ExecProvider{Command: exec.Command, Args: exec.Args}
+
+		for _, env := range exec.Env {
+			config.ExecProvider.Env = append(config.ExecProvider.Env, env.Name+"="+env.Value)
+		}
+	}
+
+	if name := c.AuthInfoName(); len(authInfo.RefreshToken) != 0 && len(name) != 0 && c.configAccess != nil {
+		config.TokenPersister = c.tokenPersister(name)
+	}
+
+	if err := rest.SetIAMDefaults(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// AuthInfoName returns the name of the user of the context in use, if any.
+func (c *ClientConfig) AuthInfoName() string {
+	if context, ok := c.config.Contexts[c.ContextName()]; ok {
+		return context.AuthInfo
+	}
+
+	return ""
+}
+
+// tokenPersister returns the function saving the refreshed tokens of the
+// user name to the config files.
+func (c *ClientConfig) tokenPersister(name string) func(token *v1.Token) error {
+	return func(token *v1.Token) error {
+		return c.configAccess.Modify(func(config *Config) error {
+			authInfo, ok := config.AuthInfos[name]
+			if !ok {
+				return fmt.Errorf("user %q not found", name)
+			}
+
+			authInfo.SetToken(token)
+
+			return nil
+		})
+	}
+}
+
+// context returns the cluster and the user of the context in use with the
+// overrides applied. Without a context, they are built from the overrides
+// only.
+func (c *ClientConfig) context() (Cluster, AuthInfo, error) {
+	var (
+		cluster  Cluster
+		authInfo AuthInfo
+	)
+
+	if name := c.ContextName(); len(name) != 0 {
+		context, ok := c.config.Contexts[name]
+		if !ok {
+			return cluster, authInfo, fmt.Errorf("context %q not found", name)
+		}
+
+		if len(context.Cluster) != 0 {
+			cl, ok := c.config.Clusters[context.Cluster]
+			if !ok {
+				return cluster, authInfo, fmt.Errorf("cluster %q of context %q not found", context.Cluster, name)
+			}
+
+			cluster = *cl
+		}
+
+		if len(context.AuthInfo) != 0 {
+			ai, ok := c.config.AuthInfos[context.AuthInfo]
+			if !ok {
+				return cluster, authInfo, fmt.Errorf("user %q of context %q not found", context.AuthInfo, name)
+			}
+
+			authInfo = *ai
+		}
+	}
+
+	if c.overrides.Apply != nil {
+		if err := c.overrides.Apply(&cluster, &authInfo); err != nil {
+			return cluster, authInfo, err
+		}
+	}
+
+	return cluster, authInfo, nil
+}
@@ -1,10 +1,281 @@
+// Package clientcmd loads the floractl client config files and builds the
+// rest.Config used to talk to the flora apiserver.
 package clientcmd
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"time"
 
-type Server struct {
-	LocationOfOrigin string
-	Timeout          time.Duration
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+)
+
+// Config holds the information needed to connect to flora apiservers as a
+// given user. Like a kubeconfig, it has named clusters, users and contexts
+// binding a user to a cluster:
+//
+//	current-context: dev
+//	clusters:
+//	- name: dev
+//	  cluster:
+//	    server: https://dev.flora.example.com:8443
+//	    certificate-authority: dev-ca.pem
+//	users:
+//	- name: admin
+//	  user:
+//	    secret-id: ...
+//	    secret-key: ...
+//	contexts:
+//	- name: dev
+//	  context:
+//	    cluster: dev
+//	    user: admin
+type Config struct {
+	// CurrentContext is the name of the context used by default.
+	CurrentContext string
+	// Clusters is a map of referenceable names to cluster configs.
+	Clusters map[string]*Cluster
+	// AuthInfos is a map of referenceable names to user configs.
+	AuthInfos map[string]*AuthInfo
+	// Contexts is a map of referenceable names to context configs.
+	Contexts map[string]*Context
+}
+
+// Cluster holds the address of an apiserver and how to connect to it.
+type Cluster struct {
+	// LocationOfOrigin is the file the cluster was loaded from.
+	LocationOfOrigin string `yaml:"-"`
+
+	// Server is the address of the apiserver, https://hostname:port.
+	Server                string        `yaml:"server,omitempty"`
+	TLSServerName         string        `yaml:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify bool          `yaml:"insecure-skip-tls-verify,omitempty"`
+	CertificateAuthority  string        `yaml:"certificate-authority,omitempty"`
+	Timeout               time.Duration `yaml:"timeout,omitempty"`
+	MaxRetries            int           `yaml:"max-retries,omitempty"`
+	RetryInterval         time.Duration `yaml:"retry-interval,omitempty"`
+}
+
+// AuthInfo holds the credentials used to authenticate to an apiserver. Only
+// one of the token, the username, the secret id and the exec plugin may be
+// set.
+type AuthInfo struct {
+	// LocationOfOrigin is the file the user was loaded from.
+	LocationOfOrigin string `yaml:"-"`
+
+	Token string `yaml:"token,omitempty"`
+	// RefreshToken renews the token once it expires at TokenExpiry, they
+	// are set by floractl login.
+	RefreshToken      string    `yaml:"refresh-token,omitempty"`
+	TokenExpiry       time.Time `yaml:"token-expiry,omitempty"`
+	Username          string    `yaml:"username,omitempty"`
+	Password          string    `yaml:"password,omitempty"`
+	SecretID          string    `yaml:"secret-id,omitempty"`
+	SecretKey         string    `yaml:"secret-key,omitempty"`
+	ClientCertificate string    `yaml:"client-certificate,omitempty"`
+	ClientKey         string    `yaml:"client-key,omitempty"`
+	// Exec runs a credential plugin printing the token or the secret id
+	// and key of the user, keeping them out of the config file.
+	Exec *ExecConfig `yaml:"exec,omitempty"`
+}
+
+// ExecConfig is the credential plugin of a user, see rest.ExecProvider:
+//
+//	users:
+//	- name: admin
+//	  user:
+//	    exec:
+//	      command: flora-credentials
+//	      args: [--vault, prod]
+//	      env:
+//	      - name: VAULT_ADDR
+//	        value: https://vault.example.com
+type ExecConfig struct {
+	// Command is the path of the plugin, relative to the config file when
+	// it has a directory, or its name looked up in the PATH.
+	Command string       `yaml:"command"`
+	Args    []string     `yaml:"args,omitempty"`
+	Env     []ExecEnvVar `yaml:"env,omitempty"`
+}
+
+// ExecEnvVar is an environment variable of a credential plugin.
+type ExecEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// DeepCopy returns a copy of e sharing nothing with it.
+func (e *ExecConfig) DeepCopy() *ExecConfig {
+	if e == nil {
+		return nil
+	}
+
+	return &ExecConfig{
+		Command: e.Command,
+		Args:    append([]string(nil), e.Args...),
+		Env:     append([]ExecEnvVar(nil), e.Env...),
+	}
+}
+
+// SetToken replaces the token of the user by the one issued by a login or
+// a refresh.
+func (a *AuthInfo) SetToken(token *v1.Token) {
+	a.Token = token.AccessToken
+	a.RefreshToken = token.RefreshToken
+	a.TokenExpiry = token.ExpiresAt
+}
+
+// Context binds a user to a cluster.
+type Context struct {
+	// LocationOfOrigin is the file the context was loaded from.
+	LocationOfOrigin string `yaml:"-"`
+
+	// Cluster is the name of the cluster of the context.
+	Cluster string `yaml:"cluster"`
+	// AuthInfo is the name of the user of the context.
+	AuthInfo string `yaml:"user"`
+}
+
+// NewConfig returns an empty Config.
+func NewConfig() *Config {
+	return &Config{
+		Clusters:  map[string]*Cluster{},
+		AuthInfos: map[string]*AuthInfo{},
+		Contexts:  map[string]*Context{},
+	}
+}
+
+// DeepCopy returns a copy of c sharing no entries with it.
+func (c *Config) DeepCopy() *Config {
+	out := NewConfig()
+	out.CurrentContext = c.CurrentContext
+
+	for name, cluster := range c.Clusters {
+		cp := *cluster
+		out.Clusters[name] = &cp
+	}
+
+	for name, authInfo := range c.AuthInfos {
+		cp := *authInfo
+		cp.Exec = authInfo.Exec.DeepCopy()
+		out.AuthInfos[name] = &cp
+	}
+
+	for name, context := range c.Contexts {
+		cp := *context
+		out.Contexts[name] = &cp
+	}
+
+	return out
+}
+
+// configFile is the layout of a config file, where the maps of Config are
+// lists of named entries.
+type configFile struct {
+	APIVersion     string          `yaml:"apiVersion,omitempty"`
+	Kind           string          `yaml:"kind,omitempty"`
+	CurrentContext string          `yaml:"current-context"`
+	Clusters       []namedCluster  `yaml:"clusters"`
+	AuthInfos      []namedAuthInfo `yaml:"users"`
+	Contexts       []namedContext  `yaml:"contexts"`
 }
 
-type ClientConfig struct{}
+type namedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+type namedAuthInfo struct {
+	Name     string   `yaml:"name"`
+	AuthInfo AuthInfo `yaml:"user"`
+}
+
+type namedContext struct {
+	Name    string  `yaml:"name"`
+	Context Context `yaml:"context"`
+}
+
+// MarshalYAML implements yaml.Marshaler, the entries are sorted by name.
+func (c Config) MarshalYAML() (interface{}, error) {
+	file := configFile{APIVersion: "v1", Kind: "Config", CurrentContext: c.CurrentContext}
+
+	for _, name := range sortedKeys(c.Clusters) {
+		file.Clusters = append(file.Clusters, namedCluster{Name: name, Cluster: *c.Clusters[name]})
+	}
+
+	for _, name := range sortedKeys(c.AuthInfos) {
+		file.AuthInfos = append(file.AuthInfos, namedAuthInfo{Name: name, AuthInfo: *c.AuthInfos[name]})
+	}
+
+	for _, name := range sortedKeys(c.Contexts) {
+		file.Contexts = append(file.Contexts, namedContext{Name: name, Context: *c.Contexts[name]})
+	}
+
+	return file, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	var file configFile
+	if err := value.Decode(&file); err != nil {
+		return err
+	}
+
+	*c = *NewConfig()
+	c.CurrentContext = file.CurrentContext
+
+	for _, n := range file.Clusters {
+		if err := checkName("cluster", n.Name, c.Clusters); err != nil {
+			return err
+		}
+
+		cluster := n.Cluster
+		c.Clusters[n.Name] = &cluster
+	}
+
+	for _, n := range file.AuthInfos {
+		if err := checkName("user", n.Name, c.AuthInfos); err != nil {
+			return err
+		}
+
+		authInfo := n.AuthInfo
+		c.AuthInfos[n.Name] = &authInfo
+	}
+
+	for _, n := range file.Contexts {
+		if err := checkName("context", n.Name, c.Contexts); err != nil {
+			return err
+		}
+
+		context := n.Context
+		c.Contexts[n.Name] = &context
+	}
+
+	return nil
+}
+
+// checkName returns an error when name is empty or already in m.
+func checkName[V any](kind, name string, m map[string]V) error {
+	if len(name) == 0 {
+		return fmt.Errorf("%s without name", kind)
+	}
+
+	if _, ok := m[name]; ok {
+		return fmt.Errorf("duplicate %s %q", kind, name)
+	}
+
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
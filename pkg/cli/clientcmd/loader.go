@@ -0,0 +1,185 @@
+package clientcmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// RecommendedConfigPathEnvVar is the environment variable listing the
+	// config files to merge, separated like $PATH.
+	RecommendedConfigPathEnvVar = "FLORACONFIG"
+	// RecommendedHomeDir is the directory of the config file in the home
+	// directory of the user.
+	RecommendedHomeDir = ".flora"
+	// RecommendedFileName is the name of the config file.
+	RecommendedFileName = "config"
+)
+
+// RecommendedHomeFile returns the path of the config file, ~/.flora/config.
+func RecommendedHomeFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(RecommendedHomeDir, RecommendedFileName)
+	}
+
+	return filepath.Join(home, RecommendedHomeDir, RecommendedFileName)
+}
+
+// ClientConfigLoadingRules tells the files the config is loaded from.
+type ClientConfigLoadingRules struct {
+	// ExplicitPath is the file set on the command line. When set, it must
+	// exist and is the only file loaded.
+	ExplicitPath string
+	// Precedence is the list of files merged when ExplicitPath is empty.
+	// Missing files are skipped.
+	Precedence []string
+}
+
+// NewDefaultClientConfigLoadingRules returns the loading rules merging the
+// files listed in $FLORACONFIG, or reading ~/.flora/config when it is not
+// set.
+func NewDefaultClientConfigLoadingRules() *ClientConfigLoadingRules {
+	var precedence []string
+
+	for _, path := range filepath.SplitList(os.Getenv(RecommendedConfigPathEnvVar)) {
+		if len(path) != 0 {
+			precedence = append(precedence, path)
+		}
+	}
+
+	if len(precedence) == 0 {
+		precedence = []string{RecommendedHomeFile()}
+	}
+
+	return &ClientConfigLoadingRules{Precedence: precedence}
+}
+
+// Files returns the files the config is loaded from.
+func (r *ClientConfigLoadingRules) Files() []string {
+	if len(r.ExplicitPath) != 0 {
+		return []string{r.ExplicitPath}
+	}
+
+	return r.Precedence
+}
+
+// Load reads and merges the config files. The first file setting the
+// current context or an entry wins: a cluster defined in two files is the
+// one of the first file.
+func (r *ClientConfigLoadingRules) Load() (*Config, error) {
+	if len(r.ExplicitPath) != 0 {
+		return LoadFromFile(r.ExplicitPath)
+	}
+
+	var (
+		configs []*Config
+		errs    []error
+	)
+
+	for _, path := range r.Precedence {
+		config, err := LoadFromFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		configs = append(configs, config)
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return Merge(configs...), nil
+}
+
+// Merge merges configs, the first config setting a value wins.
+func Merge(configs ...*Config) *Config {
+	merged := NewConfig()
+
+	for _, config := range configs {
+		if len(merged.CurrentContext) == 0 {
+			merged.CurrentContext = config.CurrentContext
+		}
+
+		mergeMap(merged.Clusters, config.Clusters)
+		mergeMap(merged.AuthInfos, config.AuthInfos)
+		mergeMap(merged.Contexts, config.Contexts)
+	}
+
+	return merged
+}
+
+func mergeMap[V any](dst, src map[string]V) {
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+}
+
+// Load parses the content of a config file.
+func Load(data []byte) (*Config, error) {
+	config := NewConfig()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadFromFile reads the config file at path. The entries are marked with
+// their origin and their relative paths are resolved against the directory
+// of the file.
+func LoadFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client config %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+
+	for _, cluster := range config.Clusters {
+		cluster.LocationOfOrigin = path
+		resolvePath(dir, &cluster.CertificateAuthority)
+	}
+
+	for _, authInfo := range config.AuthInfos {
+		authInfo.LocationOfOrigin = path
+		resolvePath(dir, &authInfo.ClientCertificate)
+		resolvePath(dir, &authInfo.ClientKey)
+
+		if authInfo.Exec != nil && strings.ContainsRune(authInfo.Exec.Command, filepath.Separator) {
+			// A command without a directory is looked up in the PATH.
+			resolvePath(dir, &authInfo.Exec.Command)
+		}
+	}
+
+	for _, context := range config.Contexts {
+		context.LocationOfOrigin = path
+	}
+
+	return config, nil
+}
+
+func resolvePath(dir string, path *string) {
+	if len(*path) != 0 && !filepath.IsAbs(*path) {
+		*path = filepath.Join(dir, *path)
+	}
+}
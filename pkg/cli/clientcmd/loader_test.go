@@ -0,0 +1,159 @@
+package clientcmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadingRulesMerge(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+
+	writeFile(t, first, `
+clusters:
+- name: dev
+  cluster:
+    server: https://dev-first
+    certificate-authority: ca.pem
+contexts:
+- name: dev
+  context: {cluster: dev, user: dev}
+`)
+	writeFile(t, second, `
+current-context: prod
+clusters:
+- name: dev
+  cluster:
+    server: https://dev-second
+- name: prod
+  cluster:
+    server: https://prod
+users:
+- name: dev
+  user:
+    token: secret
+- name: plugin
+  user:
+    exec:
+      command: bin/plugin
+- name: path-plugin
+  user:
+    exec:
+      command: plugin
+`)
+
+	t.Setenv(RecommendedConfigPathEnvVar, first+string(os.PathListSeparator)+filepath.Join(dir, "missing")+
+		string(os.PathListSeparator)+second)
+
+	config, err := NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.CurrentContext != "prod" {
+		t.Errorf("CurrentContext = %q, want prod", config.CurrentContext)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"first file wins", config.Clusters["dev"].Server, "https://dev-first"},
+		{"merged cluster", config.Clusters["prod"].Server, "https://prod"},
+		{"merged user", config.AuthInfos["dev"].Token, "secret"},
+		{"relative path", config.Clusters["dev"].CertificateAuthority, filepath.Join(dir, "ca.pem")},
+		{"relative command", config.AuthInfos["plugin"].Exec.Command, filepath.Join(dir, "bin/plugin")},
+		{"command in the path", config.AuthInfos["path-plugin"].Exec.Command, "plugin"},
+		{"origin", config.Clusters["prod"].LocationOfOrigin, second},
+		{"context", config.Contexts["dev"].AuthInfo, "dev"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if _, err := (&ClientConfigLoadingRules{ExplicitPath: filepath.Join(dir, "missing")}).Load(); err == nil {
+		t.Errorf("Load() of a missing explicit file succeeded, want an error")
+	}
+}
+
+func TestConfigYAML(t *testing.T) {
+	config := NewConfig()
+	config.CurrentContext = "dev"
+	config.Clusters["dev"] = &Cluster{Server: "https://dev", LocationOfOrigin: "ignored"}
+	config.AuthInfos["admin"] = &AuthInfo{SecretID: "id", SecretKey: "key"}
+	config.Contexts["dev"] = &Context{Cluster: "dev", AuthInfo: "admin"}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.CurrentContext != "dev" || got.Clusters["dev"].Server != "https://dev" ||
+		got.AuthInfos["admin"].SecretKey != "key" || got.Contexts["dev"].AuthInfo != "admin" {
+		t.Errorf("Load(Marshal()) = %+v, want %+v", got, config)
+	}
+
+	if got.Clusters["dev"].LocationOfOrigin != "" {
+		t.Errorf("LocationOfOrigin was marshaled")
+	}
+
+	if _, err := Load([]byte("clusters:\n- name: a\n- name: a\n")); err == nil {
+		t.Errorf("Load() of duplicate clusters succeeded, want an error")
+	}
+}
+
+func TestClientConfigContext(t *testing.T) {
+	config := NewConfig()
+	config.CurrentContext = "dev"
+	config.Clusters["dev"] = &Cluster{Server: "https://dev"}
+	config.AuthInfos["admin"] = &AuthInfo{Token: "token"}
+	config.Contexts["dev"] = &Context{Cluster: "dev", AuthInfo: "admin"}
+	config.Contexts["no-user"] = &Context{Cluster: "dev", AuthInfo: "missing"}
+	config.Contexts["empty"] = &Context{}
+
+	tests := []struct {
+		name     string
+		context  string
+		wantHost string
+		wantErr  bool
+	}{
+		{"current context", "", "https://dev", false},
+		{"missing context", "prod", "", true},
+		{"missing user", "no-user", "", true},
+		{"context without server", "empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := NewClientConfig(config, ConfigOverrides{CurrentContext: tt.context}, nil).ClientConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ClientConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if err == nil && rc.Host != tt.wantHost {
+				t.Errorf("Host = %q, want %q", rc.Host, tt.wantHost)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package clientcmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// lockSuffix is the suffix of the lock file of a config file.
+	lockSuffix = ".lock"
+	// lockTimeout is how long to wait for a config file locked by another
+	// process.
+	lockTimeout = 10 * time.Second
+)
+
+// lockFile creates the lock file of path, waiting while another process
+// holds it, and returns the function removing it.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	lock := path + lockSuffix
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_ = f.Close()
+
+			return func() { _ = os.Remove(lock) }, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process, remove %s if no other floractl is running",
+				path, lock)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Modify locks the config files, loads them, lets modify change the merged
+// config and writes the changes back. A changed entry is written to the
+// file it was loaded from, and a new one to the destination file: the
+// explicit file or the first of the precedence list. Removed entries are
+// removed from the file they were loaded from.
+func (r *ClientConfigLoadingRules) Modify(modify func(config *Config) error) error {
+	files := r.Files()
+	if len(files) == 0 {
+		return errors.New("no config file to modify")
+	}
+
+	paths := append([]string(nil), files...)
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		unlock, err := lockFile(path)
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+	}
+
+	// The explicit file is created when it does not exist yet.
+	start, err := r.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		start, err = NewConfig(), nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	config := start.DeepCopy()
+	if err := modify(config); err != nil {
+		return err
+	}
+
+	// Load each file on its own to write it back with its entries only,
+	// keeping the relative paths of the unchanged ones.
+	fileConfigs := map[string]*Config{}
+
+	for _, path := range files {
+		c := NewConfig()
+
+		data, err := os.ReadFile(path)
+		if err == nil {
+			c, err = Load(data)
+		}
+
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		fileConfigs[path] = c
+	}
+
+	dest := files[0]
+	dirty := map[string]bool{}
+
+	modifyEntries(fileConfigs, dirty, dest, start.Clusters, config.Clusters,
+		func(c *Config) map[string]*Cluster { return c.Clusters })
+	modifyEntries(fileConfigs, dirty, dest, start.AuthInfos, config.AuthInfos,
+		func(c *Config) map[string]*AuthInfo { return c.AuthInfos })
+	modifyEntries(fileConfigs, dirty, dest, start.Contexts, config.Contexts,
+		func(c *Config) map[string]*Context { return c.Contexts })
+
+	if config.CurrentContext != start.CurrentContext {
+		// The current context is written to the file it was set in.
+		path := dest
+
+		for _, f := range files {
+			if len(fileConfigs[f].CurrentContext) != 0 {
+				path = f
+				break
+			}
+		}
+
+		fileConfigs[path].CurrentContext = config.CurrentContext
+		dirty[path] = true
+	}
+
+	for _, path := range files {
+		if dirty[path] {
+			if err := WriteToFile(*fileConfigs[path], path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// entry is a cluster, a user or a context.
+type entry[T any] interface {
+	*T
+	origin() string
+}
+
+func (c *Cluster) origin() string  { return c.LocationOfOrigin }
+func (a *AuthInfo) origin() string { return a.LocationOfOrigin }
+func (c *Context) origin() string  { return c.LocationOfOrigin }
+
+// modifyEntries applies the changes between the start and the modified
+// entries to the files.
+func modifyEntries[T any, P entry[T]](files map[string]*Config, dirty map[string]bool, dest string,
+	start, modified map[string]P, entries func(*Config) map[string]P,
+) {
+	fileOf := func(e P) string {
+		if _, ok := files[e.origin()]; ok {
+			return e.origin()
+		}
+
+		return dest
+	}
+
+	for name, e := range start {
+		if _, ok := modified[name]; !ok {
+			delete(entries(files[fileOf(e)]), name)
+			dirty[fileOf(e)] = true
+		}
+	}
+
+	for name, e := range modified {
+		if old, ok := start[name]; ok && reflect.DeepEqual(old, e) {
+			continue
+		}
+
+		entries(files[fileOf(e)])[name] = e
+		dirty[fileOf(e)] = true
+	}
+}
+
+// Write returns the content of the config file of config.
+func Write(config Config) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteToFile writes config to path, replacing it atomically.
+func WriteToFile(config Config, path string) error {
+	data, err := Write(config)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,130 @@
+package clientcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestModify(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+
+	writeFile(t, first, `
+clusters:
+- name: dev
+  cluster:
+    server: https://dev
+    certificate-authority: ca.pem
+`)
+	writeFile(t, second, `
+current-context: dev
+clusters:
+- name: prod
+  cluster:
+    server: https://prod
+contexts:
+- name: dev
+  context: {cluster: dev}
+`)
+
+	rules := &ClientConfigLoadingRules{Precedence: []string{first, second}}
+
+	err := rules.Modify(func(config *Config) error {
+		config.Clusters["prod"].Server = "https://prod-2"
+		config.AuthInfos["admin"] = &AuthInfo{Token: "token"}
+		config.CurrentContext = "prod"
+		config.Contexts["prod"] = &Context{Cluster: "prod", AuthInfo: "admin"}
+		delete(config.Contexts, "dev")
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstConfig, err := LoadFromFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondConfig, err := LoadFromFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"unchanged file entry kept", firstConfig.Clusters["dev"] != nil},
+		{"changed entry written to its file", secondConfig.Clusters["prod"].Server == "https://prod-2"},
+		{"new entries written to the first file", firstConfig.AuthInfos["admin"] != nil && firstConfig.Contexts["prod"] != nil},
+		{"removed entry removed from its file", len(secondConfig.Contexts) == 0},
+		{"current context written where it was set", secondConfig.CurrentContext == "prod" && firstConfig.CurrentContext == ""},
+	}
+
+	for _, tt := range tests {
+		if !tt.ok {
+			t.Errorf("%s: first = %+v, second = %+v", tt.name, firstConfig, secondConfig)
+		}
+	}
+
+	// The relative paths of the entries not changed are kept.
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ca := raw.Clusters["dev"].CertificateAuthority; ca != "ca.pem" {
+		t.Errorf("certificate-authority = %q, want ca.pem", ca)
+	}
+
+	if _, err := os.Stat(first + lockSuffix); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestModifyConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	rules := &ClientConfigLoadingRules{ExplicitPath: path}
+
+	writeFile(t, path, "")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			err := rules.Modify(func(config *Config) error {
+				config.Clusters[fmt.Sprint(i)] = &Cluster{Server: fmt.Sprintf("https://%d", i)}
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Clusters) != 10 {
+		t.Errorf("clusters = %d, want 10: concurrent modifications were lost", len(config.Clusters))
+	}
+}
@@ -0,0 +1,397 @@
+// Package jsonpath implements the JSONPath templates of floractl's
+// -o jsonpath=... and --sort-by, e.g.
+//
+//	{range .items[*]}{.metadata.name}{"\t"}{.spec.holderIdentity}{"\n"}{end}
+//
+// Templates are text with actions in braces. An action is a path ($ is the
+// root, @ the current object), a "string literal", or a range over the
+// results of a path up to {end}. Paths support .field, ['field'], ..field,
+// *, [index], [start:end:step] and [?(@.field op value)] filters.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed template.
+type JSONPath struct {
+	name             string
+	nodes            []node
+	allowMissingKeys bool
+}
+
+// New returns an empty template named name, the name is used in errors.
+func New(name string) *JSONPath {
+	return &JSONPath{name: name}
+}
+
+// AllowMissingKeys makes missing fields and out of range indexes select
+// nothing instead of failing.
+func (j *JSONPath) AllowMissingKeys(allow bool) *JSONPath {
+	j.allowMissingKeys = allow
+	return j
+}
+
+// Parse parses the template text.
+func (j *JSONPath) Parse(text string) error {
+	nodes, err := parse(text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", j.name, err)
+	}
+
+	j.nodes = nodes
+
+	return nil
+}
+
+// Execute writes the template applied to data, a value decoded from JSON.
+func (j *JSONPath) Execute(w io.Writer, data interface{}) error {
+	var b strings.Builder
+	if err := j.walk(&b, data, data, j.nodes); err != nil {
+		return fmt.Errorf("%s: %w", j.name, err)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// FindResults returns the results of each path of the template applied to
+// data, text and literals are skipped.
+func (j *JSONPath) FindResults(data interface{}) ([][]interface{}, error) {
+	var results [][]interface{}
+
+	for _, n := range j.nodes {
+		expr, ok := n.(exprNode)
+		if !ok {
+			continue
+		}
+
+		values, err := j.eval(data, data, expr.steps)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", j.name, err)
+		}
+
+		results = append(results, values)
+	}
+
+	return results, nil
+}
+
+// RelaxedExpression wraps a bare path such as .metadata.name into an action
+// so that both "{.metadata.name}" and ".metadata.name" are accepted.
+func RelaxedExpression(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		return path
+	}
+
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "$") && !strings.HasPrefix(path, "@") {
+		path = "." + path
+	}
+
+	return "{" + path + "}"
+}
+
+func (j *JSONPath) walk(w *strings.Builder, root, cur interface{}, nodes []node) error {
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case textNode:
+			w.WriteString(t.text)
+		case exprNode:
+			values, err := j.eval(root, cur, t.steps)
+			if err != nil {
+				return err
+			}
+
+			for i, v := range values {
+				if i > 0 {
+					w.WriteByte(' ')
+				}
+
+				text, err := format(v)
+				if err != nil {
+					return err
+				}
+
+				w.WriteString(text)
+			}
+		case rangeNode:
+			values, err := j.eval(root, cur, t.steps)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range values {
+				if err := j.walk(w, root, v, t.body); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// eval applies the steps of a path to cur.
+func (j *JSONPath) eval(root, cur interface{}, steps []step) ([]interface{}, error) {
+	values := []interface{}{cur}
+
+	for _, st := range steps {
+		var next []interface{}
+
+		for _, v := range values {
+			selected, err := j.evalStep(root, v, st)
+			if err != nil {
+				return nil, err
+			}
+
+			next = append(next, selected...)
+		}
+
+		values = next
+	}
+
+	return values, nil
+}
+
+func (j *JSONPath) evalStep(root, v interface{}, st step) ([]interface{}, error) {
+	switch st.kind {
+	case rootStep:
+		return []interface{}{root}, nil
+	case fieldStep:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return j.missing("%s is not found: %s is not an object", st.name, typeOf(v))
+		}
+
+		value, ok := m[st.name]
+		if !ok {
+			return j.missing("%s is not found", st.name)
+		}
+
+		return []interface{}{value}, nil
+	case recursiveStep:
+		return descendants(v, st.name), nil
+	case wildcardStep:
+		return children(v), nil
+	case indexStep:
+		list, ok := v.([]interface{})
+		if !ok {
+			return j.missing("cannot index %s", typeOf(v))
+		}
+
+		index := st.index
+		if index < 0 {
+			index += len(list)
+		}
+
+		if index < 0 || index >= len(list) {
+			return j.missing("array index out of bounds: index %d, length %d", st.index, len(list))
+		}
+
+		return []interface{}{list[index]}, nil
+	case sliceStep:
+		list, ok := v.([]interface{})
+		if !ok {
+			return j.missing("cannot slice %s", typeOf(v))
+		}
+
+		return slice(list, st.slice), nil
+	case filterStep:
+		var selected []interface{}
+
+		for _, item := range children(v) {
+			ok, err := j.match(root, item, st.filter)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				selected = append(selected, item)
+			}
+		}
+
+		return selected, nil
+	}
+
+	return nil, fmt.Errorf("unknown step %d", st.kind)
+}
+
+func (j *JSONPath) missing(format string, args ...interface{}) ([]interface{}, error) {
+	if j.allowMissingKeys {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf(format, args...)
+}
+
+// match returns true if item satisfies the filter.
+func (j *JSONPath) match(root, item interface{}, f *filter) (bool, error) {
+	// Elements without the field never match, whatever allowMissingKeys.
+	lenient := &JSONPath{allowMissingKeys: true}
+
+	values, err := lenient.eval(root, item, f.left)
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+
+	if len(f.op) == 0 {
+		return true, nil
+	}
+
+	for _, v := range values {
+		if compare(v, f.op, f.right) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// compare compares numbers numerically and other values by equality of
+// their text.
+func compare(left interface{}, op string, right interface{}) bool {
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+
+	if lok && rok {
+		switch op {
+		case "==":
+			return l == r
+		case "!=":
+			return l != r
+		case "<":
+			return l < r
+		case "<=":
+			return l <= r
+		case ">":
+			return l > r
+		case ">=":
+			return l >= r
+		}
+	}
+
+	ls, _ := format(left)
+	rs, _ := format(right)
+
+	switch op {
+	case "==":
+		return ls == rs
+	case "!=":
+		return ls != rs
+	}
+
+	return false
+}
+
+// children returns the elements of a list or the values of an object,
+// sorted by key.
+func children(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		values := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, t[key])
+		}
+
+		return values
+	}
+
+	return nil
+}
+
+// descendants returns the values of the field name of v and all its
+// descendants, in depth first order.
+func descendants(v interface{}, name string) []interface{} {
+	var values []interface{}
+
+	if m, ok := v.(map[string]interface{}); ok {
+		if name == "*" {
+			values = append(values, children(m)...)
+		} else if value, ok := m[name]; ok {
+			values = append(values, value)
+		}
+	}
+
+	for _, child := range children(v) {
+		values = append(values, descendants(child, name)...)
+	}
+
+	return values
+}
+
+// slice returns list[start:end:step] with Python semantics: negative bounds
+// count from the end and bounds are clamped.
+func slice(list []interface{}, bounds [3]*int) []interface{} {
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+
+		v := *p
+		if v < 0 {
+			v += len(list)
+		}
+
+		return min(max(v, 0), len(list))
+	}
+
+	start, end, stride := bound(bounds[0], 0), bound(bounds[1], len(list)), 1
+	if bounds[2] != nil {
+		stride = *bounds[2]
+	}
+
+	var values []interface{}
+	for i := start; i < end; i += stride {
+		values = append(values, list[i])
+	}
+
+	return values
+}
+
+// format returns the text of a result: strings are printed as is, numbers
+// without exponent and objects and lists as JSON.
+func format(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool, json.Number:
+		return fmt.Sprint(t), nil
+	}
+
+	data, err := json.Marshal(v)
+
+	return string(data), err
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,108 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const leases = `{
+	"kind": "List",
+	"items": [
+		{"metadata": {"name": "a", "labels": {"app.kubernetes.io/name": "web"}}, "spec": {"holderIdentity": "x", "leaseTransitions": 3}},
+		{"metadata": {"name": "b"}, "spec": {"holderIdentity": "y", "leaseTransitions": 1}},
+		{"metadata": {"name": "c"}, "spec": {"leaseTransitions": 7}}
+	]
+}`
+
+func TestExecute(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(leases), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"{.kind}", "List", false},
+		{"kind is {.kind}", "kind is List", false},
+		{"{$.kind}", "List", false},
+		{"{.items[*].metadata.name}", "a b c", false},
+		{"{.items[0].metadata.name}", "a", false},
+		{"{.items[-1].metadata.name}", "c", false},
+		{"{.items[1:].metadata.name}", "b c", false},
+		{"{.items[::2].metadata.name}", "a c", false},
+		{"{.items[0]['metadata']['name']}", "a", false},
+		{`{.items[0].metadata.labels.app\.kubernetes\.io/name}`, "web", false},
+		{"{..holderIdentity}", "x y", false},
+		{"{.items[0].spec.leaseTransitions}", "3", false},
+		{"{.items[0].metadata.labels}", `{"app.kubernetes.io/name":"web"}`, false},
+		{`{.items[?(@.spec.leaseTransitions>2)].metadata.name}`, "a c", false},
+		{`{.items[?(@.spec.holderIdentity=="y")].metadata.name}`, "b", false},
+		{`{.items[?(@.spec.holderIdentity)].metadata.name}`, "a b", false},
+		{`{range .items[*]}{.metadata.name}{"\t"}{.spec.leaseTransitions}{"\n"}{end}`, "a\t3\nb\t1\nc\t7\n", false},
+		{`{range .items[*]}{.metadata.name}:{$.kind} {end}`, "a:List b:List c:List ", false},
+		{"{.items[0].spec.holderIdentity}", "x", false},
+		{"{.items[2].spec.holderIdentity}", "", true},
+		{"{.items[5]}", "", true},
+		{"{.kind.name}", "", true},
+		{"{.items[0]", "", true},
+		{"{range .items[*]}{.kind}", "", true},
+		{"{end}", "", true},
+		{"{.items[a]}", "", true},
+	}
+
+	for _, tt := range tests {
+		j := New("test")
+
+		err := j.Parse(tt.template)
+		if err == nil {
+			var out strings.Builder
+
+			err = j.Execute(&out, data)
+			if err == nil && out.String() != tt.want {
+				t.Errorf("%s = %q, want %q", tt.template, out.String(), tt.want)
+			}
+		}
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", tt.template, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAllowMissingKeys(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(leases), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	j := New("test").AllowMissingKeys(true)
+	if err := j.Parse("{.items[*].spec.holderIdentity}{.missing}{.items[9]}"); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := j.Execute(&out, data); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "x y" {
+		t.Errorf("Execute() = %q, want %q", out.String(), "x y")
+	}
+}
+
+func TestRelaxedExpression(t *testing.T) {
+	for in, want := range map[string]string{
+		".metadata.name":   "{.metadata.name}",
+		"metadata.name":    "{.metadata.name}",
+		"{.metadata.name}": "{.metadata.name}",
+		"$.kind":           "{$.kind}",
+	} {
+		if got := RelaxedExpression(in); got != want {
+			t.Errorf("RelaxedExpression(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
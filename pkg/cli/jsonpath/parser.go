@@ -0,0 +1,359 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// node is an element of a parsed template.
+type node interface{}
+
+// textNode is text printed as is.
+type textNode struct {
+	text string
+}
+
+// exprNode prints the results of a path, e.g. {.items[*].metadata.name}.
+type exprNode struct {
+	steps []step
+}
+
+// rangeNode executes body for each result of a path:
+// {range .items[*]}...{end}.
+type rangeNode struct {
+	steps []step
+	body  []node
+}
+
+type stepKind int
+
+const (
+	rootStep stepKind = iota
+	fieldStep
+	recursiveStep
+	wildcardStep
+	indexStep
+	sliceStep
+	filterStep
+)
+
+// step is a single selection of a path.
+type step struct {
+	kind stepKind
+
+	// name is the field of fieldStep and recursiveStep.
+	name string
+
+	// index is the index of indexStep, or the start, end and step of a
+	// sliceStep; nil bounds are unset.
+	index int
+	slice [3]*int
+
+	// filter is the condition of filterStep.
+	filter *filter
+}
+
+// filter is the condition of a [?(@.path op value)] step. Without op it
+// selects the elements where path exists.
+type filter struct {
+	left  []step
+	op    string
+	right interface{}
+}
+
+// parse parses a template of text and {actions}.
+func parse(text string) ([]node, error) {
+	var (
+		root  []node
+		stack []*rangeNode
+	)
+
+	appendNode := func(n node) {
+		if len(stack) == 0 {
+			root = append(root, n)
+			return
+		}
+
+		top := stack[len(stack)-1]
+		top.body = append(top.body, n)
+	}
+
+	for len(text) != 0 {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			appendNode(textNode{text: text})
+			break
+		}
+
+		if start > 0 {
+			appendNode(textNode{text: text[:start]})
+		}
+
+		end := closingIndex(text[start:], '{', '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed action in %q", text)
+		}
+
+		action := strings.TrimSpace(text[start+1 : start+end])
+		text = text[start+end+1:]
+
+		switch {
+		case action == "end":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("{end} without a matching {range}")
+			}
+
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			appendNode(*n)
+		case strings.HasPrefix(action, "range "):
+			steps, err := parsePath(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, err
+			}
+
+			stack = append(stack, &rangeNode{steps: steps})
+		case strings.HasPrefix(action, `"`):
+			literal, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string literal %s: %w", action, err)
+			}
+
+			appendNode(textNode{text: literal})
+		default:
+			steps, err := parsePath(action)
+			if err != nil {
+				return nil, err
+			}
+
+			appendNode(exprNode{steps: steps})
+		}
+	}
+
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("{range} without a matching {end}")
+	}
+
+	return root, nil
+}
+
+// closingIndex returns the index of the bracket closing the one s starts
+// with, ignoring the brackets in quoted strings.
+func closingIndex(s string, open, close byte) int {
+	depth := 0
+
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == open:
+			depth++
+		case c == close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// parsePath parses a path such as $.items[0].metadata.name or @.spec.
+func parsePath(s string) ([]step, error) {
+	path := s
+
+	var steps []step
+
+	switch {
+	case strings.HasPrefix(s, "$"):
+		steps = append(steps, step{kind: rootStep})
+		s = s[1:]
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	}
+
+	for len(s) != 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := fieldName(s[2:])
+			if len(name) == 0 {
+				return nil, fmt.Errorf("invalid path %q: missing the field after ..", path)
+			}
+
+			steps = append(steps, step{kind: recursiveStep, name: name})
+			s = rest
+		case s[0] == '.':
+			name, rest := fieldName(s[1:])
+
+			switch name {
+			case "":
+			case "*":
+				steps = append(steps, step{kind: wildcardStep})
+			default:
+				steps = append(steps, step{kind: fieldStep, name: name})
+			}
+
+			s = rest
+		case s[0] == '[':
+			end := closingIndex(s, '[', ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed [", path)
+			}
+
+			st, err := parseBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+
+			steps = append(steps, st)
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, s)
+		}
+	}
+
+	return steps, nil
+}
+
+// fieldName reads a field name up to the next . or [, a backslash escapes
+// the next character, e.g. labels.app\.kubernetes\.io/name.
+func fieldName(s string) (string, string) {
+	var name strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				name.WriteByte(s[i])
+			}
+		case '.', '[':
+			return name.String(), s[i:]
+		default:
+			name.WriteByte(c)
+		}
+	}
+
+	return name.String(), ""
+}
+
+// parseBracket parses the content of [...]: *, an index, a slice, a quoted
+// field name or a filter.
+func parseBracket(s string) (step, error) {
+	switch {
+	case s == "*":
+		return step{kind: wildcardStep}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		f, err := parseFilter(strings.TrimSpace(s[2 : len(s)-1]))
+		return step{kind: filterStep, filter: f}, err
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		name, err := unquote(s)
+		return step{kind: fieldStep, name: name}, err
+	case strings.Contains(s, ":"):
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return step{}, fmt.Errorf("invalid slice [%s]", s)
+		}
+
+		st := step{kind: sliceStep}
+
+		for i, part := range parts {
+			if part = strings.TrimSpace(part); len(part) == 0 {
+				continue
+			}
+
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return step{}, fmt.Errorf("invalid slice [%s]", s)
+			}
+
+			st.slice[i] = &v
+		}
+
+		if st.slice[2] != nil && *st.slice[2] <= 0 {
+			return step{}, fmt.Errorf("invalid slice [%s]: the step must be positive", s)
+		}
+
+		return st, nil
+	}
+
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return step{}, fmt.Errorf("invalid array index [%s]", s)
+	}
+
+	return step{kind: indexStep, index: index}, nil
+}
+
+// filterOperators are the comparisons of filters, longest first.
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses "@.path op value" or "@.path".
+func parseFilter(s string) (*filter, error) {
+	for _, op := range filterOperators {
+		left, right, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+
+		steps, err := parsePath(strings.TrimSpace(left))
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := parseLiteral(strings.TrimSpace(right))
+		if err != nil {
+			return nil, err
+		}
+
+		return &filter{left: steps, op: op, right: value}, nil
+	}
+
+	steps, err := parsePath(s)
+
+	return &filter{left: steps}, err
+}
+
+// parseLiteral parses the quoted string, number or boolean of a filter.
+func parseLiteral(s string) (interface{}, error) {
+	if strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`) {
+		return unquote(s)
+	}
+
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter value %q", s)
+	}
+
+	return f, nil
+}
+
+// unquote removes the single or double quotes around s.
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+
+	return v, nil
+}
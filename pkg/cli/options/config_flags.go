@@ -1,212 +1,420 @@
 package options
 
-// // Defines flag for floractl.
-// const (
-// 	FlagConfig        = "config"
-// 	FlagBearerToken   = "user.token"
-// 	FlagUsername      = "user.username"
-// 	FlagPassword      = "user.password"
-// 	FlagSecretID      = "user.secret-id"
-// 	FlagSecretKey     = "user.secret-key"
-// 	FlagCertFile      = "user.client-certificate"
-// 	FlagKeyFile       = "user.client-key"
-// 	FlagTLSServerName = "server.tls-server-name"
-// 	FlagInsecure      = "server.insecure-skip-tls-verify"
-// 	FlagCAFile        = "server.certificate-authority"
-// 	FlagAPIServer     = "server.address"
-// 	FlagTimeout       = "server.timeout"
-// 	FlagMaxRetries    = "server.max-retries"
-// 	FlagRetryInterval = "server.retry-interval"
-// )
-
-// // Config holds the common attributes that can be passed to a IAM client on
-// // initialization.
-// type Config struct {
-// 	Host    string
-// 	APIPath string
-// 	ContentConfig
-
-// 	// Server requires Basic authentication
-// 	Username string
-// 	Password string
-
-// 	SecretID  string
-// 	SecretKey string
-
-// 	// Server requires Bearer authentication. This client will not attempt to use
-// 	// refresh tokens for an OAuth2 flow.
-// 	BearerToken string
-
-// 	// Path to a file containing a BearerToken.
-// 	// If set, the contents are periodically read.
-// 	// The last successfully read value takes precedence over BearerToken.
-// 	BearerTokenFile string
-
-// 	// TLSClientConfig contains settings to enable transport layer security
-// 	TLSClientConfig
-
-// 	// UserAgent is an optional field that specifies the caller of this request.
-// 	UserAgent string
-// 	// The maximum length of time to wait before giving up on a server request. A value of zero means no timeout.
-// 	Timeout       time.Duration
-// 	MaxRetries    int
-// 	RetryInterval time.Duration
-// }
-
-// // ContentConfig defines config for content.
-// type ContentConfig struct {
-// 	ServiceName        string
-// 	AcceptContentTypes string
-// 	ContentType        string
-// }
-
-// // TLSClientConfig contains settings to enable transport layer security.
-// type TLSClientConfig struct {
-// 	// Server should be accessed without verifying the TLS certificate. For testing only.
-// 	Insecure bool
-// 	// ServerName is passed to the server for SNI and is used in the client to check server
-// 	// ceritificates against. If ServerName is empty, the hostname used to contact the
-// 	// server is used.
-// 	ServerName string
-
-// 	// Server requires TLS client certificate authentication
-// 	CertFile string
-// 	// Server requires TLS client certificate authentication
-// 	KeyFile string
-// 	// Trusted root certificates for server
-// 	CAFile string
-
-// 	// CertData holds PEM-encoded bytes (typically read from a client certificate file).
-// 	// CertData takes precedence over CertFile
-// 	CertData []byte
-// 	// KeyData holds PEM-encoded bytes (typically read from a client certificate key file).
-// 	// KeyData takes precedence over KeyFile
-// 	KeyData []byte
-// 	// CAData holds PEM-encoded bytes (typically read from a root certificates bundle).
-// 	// CAData takes precedence over CAFile
-// 	CAData []byte
-
-// 	// NextProtos is a list of supported application level protocols, in order of preference.
-// 	// Used to populate tls.Config.NextProtos.
-// 	// To indicate to the server http/1.1 is preferred over http/2, set to ["http/1.1", "h2"] (though the server is free
-// 	// to ignore that preference).
-// 	// To use only http/1.1, set to ["http/1.1"].
-// 	NextProtos []string
-// }
-
-// type RESTClientGetter interface {
-// 	ToRESTConfig() (*Config, error)
-// 	ToRawConfigLoader() ClientConfiger
-// }
-
-// var _ RESTClientGetter = &ConfigFlags{}
-
-// // ClientConfig is used to make it easy to get an api server client.
-// type ClientConfiger interface {
-// 	// ClientConfig returns a complete client config
-// 	ClientConfig() (*Config, error)
-// }
-
-// // ConfigFlags composes the set of values necessary
-// // for obtaining a REST client config.
-// type ConfigFlags struct {
-// 	Config *string
-
-// 	BearerToken *string
-// 	Username    *string
-// 	Password    *string
-// 	SecretID    *string
-// 	SecretKey   *string
-
-// 	Insecure      *bool
-// 	TLSServerName *string
-// 	CertFile      *string
-// 	KeyFile       *string
-// 	CAFile        *string
-
-// 	APIServer     *string
-// 	Timeout       *time.Duration
-// 	MaxRetries    *int
-// 	RetryInterval *time.Duration
-
-// 	clientConfig ClientConfiger
-// 	lock         sync.Mutex
-// 	// If set to true, will use persistent client config and
-// 	// propagate the config to the places that need it, rather than
-// 	// loading the config multiple times
-// 	usePersistentConfig bool
-// }
-
-// func (f *ConfigFlags) ToRESTConfig() (*Config, error) {
-// 	return f.ToRawConfigLoader().ClientConfig()
-// }
-
-// func (f *ConfigFlags) ToRawConfigLoader() ClientConfiger {
-// 	if f.usePersistentConfig {
-// 		return f.toRawPersistentConfigLoader()
-// 	}
-
-// 	return f.toRawConfigLoader()
-// }
-
-// func (f *ConfigFlags) toRawConfigLoader() ClientConfiger {
-// 	config := NewConfig()
-// 	if err := viper.Unmarshal(&config); err != nil {
-// 		panic(err)
-// 	}
-
-// 	return NewClientConfigFromConfig(config)
-// }
-
-// func (f *ConfigFlags) toRawPersistentConfigLoader() ClientConfiger {
-// 	f.lock.Lock()
-// 	defer f.lock.Unlock()
-
-// 	if f.clientConfig == nil {
-// 		f.clientConfig = f.toRawConfigLoader()
-// 	}
-
-// 	return f.clientConfig
-// }
-
-// func (f *ConfigFlags) WithDeprecatedPasswordFlag() *ConfigFlags {
-// 	f.Username = pointer.ToString("")
-// 	f.Password = pointer.ToString("")
-
-// 	return f
-// }
-
-// func (f *ConfigFlags) WithDeprecatedSecretFlag() *ConfigFlags {
-// 	f.SecretID = pointer.ToString("")
-// 	f.SecretKey = pointer.ToString("")
-
-// 	return f
-
-// }
-
-// func NewConfigFlags(usePersistentConfig bool) *ConfigFlags {
-// 	return &ConfigFlags{
-// 		Config: pointer.ToString(""),
-
-// 		BearerToken:   pointer.ToString(""),
-// 		Insecure:      pointer.ToBool(false),
-// 		TLSServerName: pointer.ToString(""),
-// 		CertFile:      pointer.ToString(""),
-// 		KeyFile:       pointer.ToString(""),
-// 		CAFile:        pointer.ToString(""),
-
-// 		APIServer:           pointer.ToString(""),
-// 		Timeout:             pointer.ToDuration(30 * time.Second),
-// 		MaxRetries:          pointer.ToInt(0),
-// 		RetryInterval:       pointer.ToDuration(1 * time.Second),
-// 		usePersistentConfig: usePersistentConfig,
-// 	}
-// }
-
-// func NewConfig() *Config {
-// 	return &Config{}
-// }
-
-// func NewClientConfigFromConfig(config *Config) ClientConfiger {
-// 	return &clientcmd.ClientConfig{}
-// }
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/hanzhuoxian/flora/pkg/cli/clientcmd"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// Defines flag for floractl.
+const (
+	FlagConfig        = "config"
+	FlagContext       = "context"
+	FlagBearerToken   = "user.token"
+	FlagUsername      = "user.username"
+	FlagPassword      = "user.password"
+	FlagSecretID      = "user.secret-id"
+	FlagSecretKey     = "user.secret-key"
+	FlagCertFile      = "user.client-certificate"
+	FlagKeyFile       = "user.client-key"
+	FlagTLSServerName = "server.tls-server-name"
+	FlagInsecure      = "server.insecure-skip-tls-verify"
+	FlagCAFile        = "server.certificate-authority"
+	FlagAPIServer     = "server.address"
+	FlagTimeout       = "server.timeout"
+	FlagMaxRetries    = "server.max-retries"
+	FlagRetryInterval = "server.retry-interval"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the
+// client config file.
+const EnvPrefix = "FLORA"
+
+// EnvName returns the environment variable of a flag: server.address is
+// set by FLORA_SERVER_ADDRESS.
+func EnvName(flag string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flag))
+}
+
+// RESTClientGetter is an interface that the ConfigFlags describe to provide
+// an easier way to mock for commands and eliminate the direct coupling to a
+// struct type.
+type RESTClientGetter interface {
+	// ToRESTConfig returns the rest.Config of the apiserver.
+	ToRESTConfig() (*rest.Config, error)
+	// ToRawConfigLoader returns the loader of the client config.
+	ToRawConfigLoader() ClientConfiger
+}
+
+var _ RESTClientGetter = &ConfigFlags{}
+
+// ClientConfiger is used to make it easy to get an api server client.
+type ClientConfiger interface {
+	// RawConfig returns the merged client config, without the overrides of
+	// the environment variables and the flags.
+	RawConfig() (clientcmd.Config, error)
+	// ContextName returns the name of the context in use.
+	ContextName() string
+	// ClientConfig returns a complete client config.
+	ClientConfig() (*rest.Config, error)
+}
+
+var _ ClientConfiger = &clientcmd.ClientConfig{}
+
+// ConfigFlags composes the set of values necessary for obtaining a REST
+// client config.
+//
+// The config is read from the file set by --config, or merged from the
+// files listed in $FLORACONFIG, or read from ~/.flora/config. The context
+// in use is the one set by --context, $FLORA_CONTEXT or the current context
+// of the config. Each value of its cluster and user is overridden by the
+// environment variable of its flag, e.g. $FLORA_SERVER_ADDRESS, which is
+// overridden by the flag itself when it is set on the command line.
+type ConfigFlags struct {
+	Config  *string
+	Context *string
+
+	BearerToken *string
+	Username    *string
+	Password    *string
+	SecretID    *string
+	SecretKey   *string
+
+	Insecure      *bool
+	TLSServerName *string
+	CertFile      *string
+	KeyFile       *string
+	CAFile        *string
+
+	APIServer     *string
+	Timeout       *time.Duration
+	MaxRetries    *int
+	RetryInterval *time.Duration
+
+	// flags is the flag set the flags were added to, it tells the flags set
+	// on the command line.
+	flags *pflag.FlagSet
+
+	clientConfig ClientConfiger
+	lock         sync.Mutex
+	// If set to true, will use persistent client config and
+	// propagate the config to the places that need it, rather than
+	// loading the config multiple times
+	usePersistentConfig bool
+}
+
+// NewConfigFlags returns ConfigFlags with default values set.
+func NewConfigFlags(usePersistentConfig bool) *ConfigFlags {
+	return &ConfigFlags{
+		Config:  stringPtr(""),
+		Context: stringPtr(""),
+
+		BearerToken:   stringPtr(""),
+		SecretID:      stringPtr(""),
+		SecretKey:     stringPtr(""),
+		Insecure:      boolPtr(false),
+		TLSServerName: stringPtr(""),
+		CertFile:      stringPtr(""),
+		KeyFile:       stringPtr(""),
+		CAFile:        stringPtr(""),
+
+		APIServer:           stringPtr(""),
+		Timeout:             durationPtr(clientcmd.DefaultTimeout),
+		MaxRetries:          intPtr(0),
+		RetryInterval:       durationPtr(clientcmd.DefaultRetryInterval),
+		usePersistentConfig: usePersistentConfig,
+	}
+}
+
+// WithDeprecatedPasswordFlag enables the username and password flags.
+func (f *ConfigFlags) WithDeprecatedPasswordFlag() *ConfigFlags {
+	f.Username = stringPtr("")
+	f.Password = stringPtr("")
+
+	return f
+}
+
+// AddFlags binds the flags of the non nil fields to the specified FlagSet.
+func (f *ConfigFlags) AddFlags(flags *pflag.FlagSet) {
+	f.flags = flags
+
+	if f.Config != nil {
+		flags.StringVar(f.Config, FlagConfig, *f.Config,
+			"Path to the floractl config file. Defaults to the files listed in $FLORACONFIG or ~/.flora/config.")
+	}
+
+	if f.Context != nil {
+		flags.StringVar(f.Context, FlagContext, *f.Context, "The name of the config context to use.")
+	}
+
+	if f.BearerToken != nil {
+		flags.StringVar(f.BearerToken, FlagBearerToken, *f.BearerToken,
+			"Bearer token for authentication to the API server.")
+	}
+
+	if f.Username != nil {
+		flags.StringVar(f.Username, FlagUsername, *f.Username, "Username for basic authentication to the API server.")
+	}
+
+	if f.Password != nil {
+		flags.StringVar(f.Password, FlagPassword, *f.Password, "Password for basic authentication to the API server.")
+	}
+
+	if f.SecretID != nil {
+		flags.StringVar(f.SecretID, FlagSecretID, *f.SecretID, "SecretID for JWT authentication to the API server.")
+	}
+
+	if f.SecretKey != nil {
+		flags.StringVar(f.SecretKey, FlagSecretKey, *f.SecretKey, "SecretKey for JWT authentication to the API server.")
+	}
+
+	if f.CertFile != nil {
+		flags.StringVar(f.CertFile, FlagCertFile, *f.CertFile, "Path to a client certificate file for TLS.")
+	}
+
+	if f.KeyFile != nil {
+		flags.StringVar(f.KeyFile, FlagKeyFile, *f.KeyFile, "Path to a client key file for TLS.")
+	}
+
+	if f.TLSServerName != nil {
+		flags.StringVar(f.TLSServerName, FlagTLSServerName, *f.TLSServerName,
+			"Server name to use for server certificate validation. "+
+				"If it is not provided, the hostname used to contact the server is used.")
+	}
+
+	if f.Insecure != nil {
+		flags.BoolVar(f.Insecure, FlagInsecure, *f.Insecure,
+			"If true, the server's certificate will not be checked for validity. "+
+				"This will make your HTTPS connections insecure.")
+	}
+
+	if f.CAFile != nil {
+		flags.StringVar(f.CAFile, FlagCAFile, *f.CAFile, "Path to a cert file for the certificate authority.")
+	}
+
+	if f.APIServer != nil {
+		flags.StringVarP(f.APIServer, FlagAPIServer, "s", *f.APIServer, "The address and port of the API server.")
+	}
+
+	if f.Timeout != nil {
+		flags.DurationVar(f.Timeout, FlagTimeout, *f.Timeout,
+			"The length of time to wait before giving up on a single server request. "+
+				"Non-zero values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+	}
+
+	if f.MaxRetries != nil {
+		flags.IntVar(f.MaxRetries, FlagMaxRetries, *f.MaxRetries,
+			"Maximum number of retries of the requests failing with a server error.")
+	}
+
+	if f.RetryInterval != nil {
+		flags.DurationVar(f.RetryInterval, FlagRetryInterval, *f.RetryInterval, "The interval between the retries.")
+	}
+}
+
+// ToRESTConfig implements RESTClientGetter.
+func (f *ConfigFlags) ToRESTConfig() (*rest.Config, error) {
+	return f.ToRawConfigLoader().ClientConfig()
+}
+
+// ToRawConfigLoader implements RESTClientGetter.
+func (f *ConfigFlags) ToRawConfigLoader() ClientConfiger {
+	if f.usePersistentConfig {
+		return f.toRawPersistentConfigLoader()
+	}
+
+	return f.toRawConfigLoader()
+}
+
+func (f *ConfigFlags) toRawConfigLoader() ClientConfiger {
+	config, err := f.loadConfig()
+	if err != nil {
+		return &errorConfig{err: err}
+	}
+
+	return config
+}
+
+func (f *ConfigFlags) toRawPersistentConfigLoader() ClientConfiger {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.clientConfig == nil {
+		f.clientConfig = f.toRawConfigLoader()
+	}
+
+	return f.clientConfig
+}
+
+// ToLoadingRules returns the files the config is loaded from: the one set
+// by --config, or the ones listed in $FLORACONFIG, or ~/.flora/config.
+func (f *ConfigFlags) ToLoadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if f.Config != nil {
+		rules.ExplicitPath = *f.Config
+	}
+
+	return rules
+}
+
+// loadConfig reads the config files. The environment variables and the
+// flags override the cluster and the user of the context in use.
+func (f *ConfigFlags) loadConfig() (*clientcmd.ClientConfig, error) {
+	rules := f.ToLoadingRules()
+
+	config, err := rules.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := clientcmd.ConfigOverrides{
+		CurrentContext: os.Getenv(EnvName(FlagContext)),
+		Apply:          f.apply,
+	}
+
+	if f.changed(FlagContext) {
+		overrides.CurrentContext = *f.Context
+	}
+
+	return clientcmd.NewClientConfig(config, overrides, rules), nil
+}
+
+// apply overrides the cluster and the user with the environment variables
+// and the flags set on the command line.
+func (f *ConfigFlags) apply(cluster *clientcmd.Cluster, authInfo *clientcmd.AuthInfo) error {
+	for _, o := range f.overrides(cluster, authInfo) {
+		if value, ok := os.LookupEnv(EnvName(o.flag)); ok {
+			if err := o.setString(value); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %w", value, EnvName(o.flag), err)
+			}
+		}
+
+		if f.changed(o.flag) {
+			o.set()
+		}
+	}
+
+	if _, ok := os.LookupEnv(EnvName(FlagBearerToken)); ok || f.changed(FlagBearerToken) {
+		// The refresh token of the config renews the token of the config only.
+		authInfo.RefreshToken, authInfo.TokenExpiry = "", time.Time{}
+	}
+
+	return nil
+}
+
+// changed returns whether the flag was set on the command line.
+func (f *ConfigFlags) changed(flag string) bool {
+	return f.flags != nil && f.flags.Changed(flag)
+}
+
+// override binds a flag to a field of the config.
+type override struct {
+	flag  string
+	value interface{}
+	field interface{}
+}
+
+func (f *ConfigFlags) overrides(cluster *clientcmd.Cluster, authInfo *clientcmd.AuthInfo) []override {
+	return []override{
+		{FlagBearerToken, f.BearerToken, &authInfo.Token},
+		{FlagUsername, f.Username, &authInfo.Username},
+		{FlagPassword, f.Password, &authInfo.Password},
+		{FlagSecretID, f.SecretID, &authInfo.SecretID},
+		{FlagSecretKey, f.SecretKey, &authInfo.SecretKey},
+		{FlagCertFile, f.CertFile, &authInfo.ClientCertificate},
+		{FlagKeyFile, f.KeyFile, &authInfo.ClientKey},
+		{FlagTLSServerName, f.TLSServerName, &cluster.TLSServerName},
+		{FlagInsecure, f.Insecure, &cluster.InsecureSkipTLSVerify},
+		{FlagCAFile, f.CAFile, &cluster.CertificateAuthority},
+		{FlagAPIServer, f.APIServer, &cluster.Server},
+		{FlagTimeout, f.Timeout, &cluster.Timeout},
+		{FlagMaxRetries, f.MaxRetries, &cluster.MaxRetries},
+		{FlagRetryInterval, f.RetryInterval, &cluster.RetryInterval},
+	}
+}
+
+// set copies the value of the flag to the field.
+func (o override) set() {
+	switch field := o.field.(type) {
+	case *string:
+		*field = *o.value.(*string)
+	case *bool:
+		*field = *o.value.(*bool)
+	case *int:
+		*field = *o.value.(*int)
+	case *time.Duration:
+		*field = *o.value.(*time.Duration)
+	}
+}
+
+// setString parses s into the field.
+func (o override) setString(s string) error {
+	switch field := o.field.(type) {
+	case *string:
+		*field = s
+	case *bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		*field = v
+	case *int:
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+
+		*field = v
+	case *time.Duration:
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		*field = v
+	}
+
+	return nil
+}
+
+// errorConfig is the ClientConfiger of a config that failed to load.
+type errorConfig struct {
+	err error
+}
+
+func (c *errorConfig) RawConfig() (clientcmd.Config, error) {
+	return clientcmd.Config{}, c.err
+}
+
+func (c *errorConfig) ContextName() string {
+	return ""
+}
+
+func (c *errorConfig) ClientConfig() (*rest.Config, error) {
+	return nil, c.err
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
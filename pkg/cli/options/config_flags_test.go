@@ -0,0 +1,201 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const testConfig = `current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: http://dev:8080
+    certificate-authority: ca.pem
+    timeout: 10s
+- name: prod
+  cluster:
+    server: http://prod:8080
+users:
+- name: admin
+  user:
+    secret-id: file-id
+    secret-key: file-key
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+`
+
+func TestConfigFlagsPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		env           map[string]string
+		args          []string
+		wantHost      string
+		wantTimeout   time.Duration
+		wantRetries   int
+		wantSecretID  string
+		wantSecretKey string
+	}{
+		{
+			name:          "file",
+			wantHost:      "http://dev:8080",
+			wantTimeout:   10 * time.Second,
+			wantSecretID:  "file-id",
+			wantSecretKey: "file-key",
+		},
+		{
+			name:          "context flag",
+			env:           map[string]string{"FLORA_CONTEXT": "dev"},
+			args:          []string{"--context=prod"},
+			wantHost:      "http://prod:8080",
+			wantTimeout:   30 * time.Second,
+			wantSecretID:  "file-id",
+			wantSecretKey: "file-key",
+		},
+		{
+			name:          "env over file",
+			env:           map[string]string{"FLORA_SERVER_ADDRESS": "http://env:8080", "FLORA_SERVER_MAX_RETRIES": "2"},
+			wantHost:      "http://env:8080",
+			wantTimeout:   10 * time.Second,
+			wantRetries:   2,
+			wantSecretID:  "file-id",
+			wantSecretKey: "file-key",
+		},
+		{
+			name: "flags over env",
+			env:  map[string]string{"FLORA_SERVER_ADDRESS": "http://env:8080", "FLORA_USER_SECRET_ID": "env-id"},
+			args: []string{
+				"-s", "http://flag:8080", "--server.timeout=5s", "--user.secret-id=flag-id", "--user.secret-key=flag-key",
+			},
+			wantHost:      "http://flag:8080",
+			wantTimeout:   5 * time.Second,
+			wantSecretID:  "flag-id",
+			wantSecretKey: "flag-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FLORACONFIG", path)
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			f := NewConfigFlags(false)
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			f.AddFlags(fs)
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := f.ToRESTConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if config.Host != tt.wantHost {
+				t.Errorf("Host = %q, want %q", config.Host, tt.wantHost)
+			}
+
+			if config.Timeout != tt.wantTimeout {
+				t.Errorf("Timeout = %v, want %v", config.Timeout, tt.wantTimeout)
+			}
+
+			if config.MaxRetries != tt.wantRetries {
+				t.Errorf("MaxRetries = %d, want %d", config.MaxRetries, tt.wantRetries)
+			}
+
+			if config.SecretID != tt.wantSecretID || config.SecretKey != tt.wantSecretKey {
+				t.Errorf("secret = %q/%q, want %q/%q", config.SecretID, config.SecretKey, tt.wantSecretID, tt.wantSecretKey)
+			}
+
+			if config.GroupVersion == nil || config.Negotiator == nil || len(config.UserAgent) == 0 {
+				t.Errorf("ToRESTConfig() = %v, want a config ready for a RESTClient", config)
+			}
+		})
+	}
+}
+
+func TestConfigFlagsWithoutFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("FLORACONFIG", "")
+
+	f := NewConfigFlags(false)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.AddFlags(fs)
+
+	if err := fs.Parse([]string{"--server.address=http://flag:8080", "--user.token=t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Host != "http://flag:8080" || config.BearerToken != "t" {
+		t.Errorf("ToRESTConfig() = %v, want the values of the flags", config)
+	}
+}
+
+func TestConfigFlagsErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{"missing explicit file", nil, []string{"--config", filepath.Join(dir, "missing")}},
+		{"missing context", nil, []string{"--config", path, "--context", "staging"}},
+		{"invalid env", map[string]string{"FLORA_SERVER_TIMEOUT": "soon"}, []string{"--config", path}},
+		{"no server", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("FLORACONFIG", "")
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			f := NewConfigFlags(false)
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			f.AddFlags(fs)
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := f.ToRESTConfig(); err == nil {
+				t.Errorf("ToRESTConfig() succeeded, want an error")
+			}
+		})
+	}
+}
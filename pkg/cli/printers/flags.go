@@ -0,0 +1,132 @@
+package printers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// The output formats selected with -o/--output. The template formats take
+// their template after "=", e.g. jsonpath={.metadata.name}.
+const (
+	OutputTable          = "table"
+	OutputJSON           = "json"
+	OutputYAML           = "yaml"
+	OutputName           = "name"
+	OutputWide           = "wide"
+	OutputJSONPath       = "jsonpath"
+	OutputJSONPathFile   = "jsonpath-file"
+	OutputGoTemplate     = "go-template"
+	OutputGoTemplateFile = "go-template-file"
+)
+
+// TablePrintFlags are the flags of the table output.
+type TablePrintFlags struct {
+	NoHeaders    bool
+	ShowLabels   bool
+	LabelColumns []string
+}
+
+// PrintFlags are the flags selecting how to print objects.
+type PrintFlags struct {
+	OutputFormat string
+
+	// TablePrintFlags are set when the command prints tables, the table is
+	// then the default output.
+	TablePrintFlags *TablePrintFlags
+}
+
+// NewPrintFlags returns the flags of commands printing objects, by default
+// as kind/name followed by operation.
+func NewPrintFlags() *PrintFlags {
+	return &PrintFlags{}
+}
+
+// NewTablePrintFlags returns the flags of commands printing tables of
+// objects by default, such as floractl get.
+func NewTablePrintFlags() *PrintFlags {
+	return &PrintFlags{TablePrintFlags: &TablePrintFlags{}}
+}
+
+// AllowedFormats returns the output formats accepted by ToPrinter.
+func (f *PrintFlags) AllowedFormats() []string {
+	formats := []string{
+		OutputJSON, OutputYAML, OutputName, OutputJSONPath, OutputJSONPathFile, OutputGoTemplate, OutputGoTemplateFile,
+	}
+
+	if f.TablePrintFlags != nil {
+		formats = append([]string{OutputTable, OutputWide}, formats...)
+	}
+
+	return formats
+}
+
+// AddFlags registers the flags on cmd.
+func (f *PrintFlags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.OutputFormat, "output", "o", f.OutputFormat,
+		fmt.Sprintf("Output format. One of: (%s).", strings.Join(f.AllowedFormats(), ", ")))
+
+	if f.TablePrintFlags != nil {
+		cmd.Flags().BoolVar(&f.TablePrintFlags.NoHeaders, "no-headers", f.TablePrintFlags.NoHeaders,
+			"When using the default or wide output format, don't print headers.")
+		cmd.Flags().BoolVar(&f.TablePrintFlags.ShowLabels, "show-labels", f.TablePrintFlags.ShowLabels,
+			"When printing, show all labels as the last column.")
+		cmd.Flags().StringSliceVarP(&f.TablePrintFlags.LabelColumns, "label-columns", "L",
+			f.TablePrintFlags.LabelColumns,
+			"Accepts a comma separated list of labels that are going to be presented as columns.")
+	}
+}
+
+// IsTable returns true if the objects are printed as a table.
+func (f *PrintFlags) IsTable() bool {
+	return f.TablePrintFlags != nil &&
+		(f.OutputFormat == "" || f.OutputFormat == OutputTable || f.OutputFormat == OutputWide)
+}
+
+// ToPrinter returns the printer of the output format, operation is printed
+// after the names of the objects by the name printer.
+func (f *PrintFlags) ToPrinter(operation string) (ResourcePrinter, error) {
+	if f.IsTable() {
+		return NewTablePrinter(PrintOptions{
+			NoHeaders:    f.TablePrintFlags.NoHeaders,
+			Wide:         f.OutputFormat == OutputWide,
+			ShowLabels:   f.TablePrintFlags.ShowLabels,
+			ColumnLabels: f.TablePrintFlags.LabelColumns,
+		}), nil
+	}
+
+	format, arg, hasArg := strings.Cut(f.OutputFormat, "=")
+
+	switch format {
+	case "", OutputName:
+		return &NamePrinter{Operation: operation}, nil
+	case OutputJSON:
+		return &JSONPrinter{}, nil
+	case OutputYAML:
+		return &YAMLPrinter{}, nil
+	case OutputJSONPath, OutputJSONPathFile, OutputGoTemplate, OutputGoTemplateFile:
+		if !hasArg || len(arg) == 0 {
+			return nil, fmt.Errorf("%s output format requires a template, e.g. -o %s=...", format, format)
+		}
+
+		if strings.HasSuffix(format, "-file") {
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("error reading template %s: %w", arg, err)
+			}
+
+			arg = string(data)
+		}
+
+		if strings.HasPrefix(format, OutputJSONPath) {
+			return NewJSONPathPrinter(arg)
+		}
+
+		return NewGoTemplatePrinter(arg)
+	}
+
+	return nil, fmt.Errorf("unable to match a printer suitable for the output format %q, allowed formats are: %s",
+		f.OutputFormat, strings.Join(f.AllowedFormats(), ","))
+}
@@ -0,0 +1,225 @@
+// Package printers prints the objects returned by the apiserver in the
+// output formats of floractl.
+//
+// Objects are the values decoded from the JSON of the apiserver, i.e.
+// map[string]interface{}, so that the printers work with every resource.
+// TablePrinter prints the metav1.Table built by the apiserver instead.
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hanzhuoxian/flora/pkg/cli/jsonpath"
+)
+
+// ResourcePrinter prints objects.
+type ResourcePrinter interface {
+	PrintObj(obj interface{}, w io.Writer) error
+}
+
+// ResourcePrinterFunc is a function that implements ResourcePrinter.
+type ResourcePrinterFunc func(obj interface{}, w io.Writer) error
+
+// PrintObj implements ResourcePrinter.
+func (fn ResourcePrinterFunc) PrintObj(obj interface{}, w io.Writer) error {
+	return fn(obj, w)
+}
+
+// JSONPrinter prints objects as indented JSON.
+type JSONPrinter struct{}
+
+// PrintObj implements ResourcePrinter.
+func (p *JSONPrinter) PrintObj(obj interface{}, w io.Writer) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+
+	return err
+}
+
+// YAMLPrinter prints objects as YAML documents separated by "---".
+type YAMLPrinter struct {
+	printCount int
+}
+
+// PrintObj implements ResourcePrinter.
+func (p *YAMLPrinter) PrintObj(obj interface{}, w io.Writer) error {
+	var buf bytes.Buffer
+
+	if p.printCount > 0 {
+		buf.WriteString("---\n")
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(obj); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	p.printCount++
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// NamePrinter prints "kind/name" for each object, followed by the operation
+// performed on it if set, e.g. "lease/leader created".
+type NamePrinter struct {
+	Operation string
+}
+
+// PrintObj implements ResourcePrinter.
+func (p *NamePrinter) PrintObj(obj interface{}, w io.Writer) error {
+	for _, item := range Items(obj) {
+		name := strings.ToLower(Kind(item)) + "/" + Name(item)
+		if len(p.Operation) != 0 {
+			name += " " + p.Operation
+		}
+
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JSONPathPrinter prints objects through a JSONPath template. Missing fields
+// print nothing.
+type JSONPathPrinter struct {
+	*jsonpath.JSONPath
+}
+
+// NewJSONPathPrinter parses template.
+func NewJSONPathPrinter(template string) (*JSONPathPrinter, error) {
+	j := jsonpath.New("output").AllowMissingKeys(true)
+	if err := j.Parse(template); err != nil {
+		return nil, err
+	}
+
+	return &JSONPathPrinter{JSONPath: j}, nil
+}
+
+// PrintObj implements ResourcePrinter.
+func (p *JSONPathPrinter) PrintObj(obj interface{}, w io.Writer) error {
+	data, err := toJSONValue(obj)
+	if err != nil {
+		return err
+	}
+
+	return p.Execute(w, data)
+}
+
+// GoTemplatePrinter prints objects through a Go template.
+type GoTemplatePrinter struct {
+	template *template.Template
+}
+
+// NewGoTemplatePrinter parses text.
+func NewGoTemplatePrinter(text string) (*GoTemplatePrinter, error) {
+	t, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %w", text, err)
+	}
+
+	return &GoTemplatePrinter{template: t}, nil
+}
+
+// PrintObj implements ResourcePrinter.
+func (p *GoTemplatePrinter) PrintObj(obj interface{}, w io.Writer) error {
+	data, err := toJSONValue(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := p.template.Execute(w, data); err != nil {
+		return fmt.Errorf("error executing template %s: %w", p.template.Root.String(), err)
+	}
+
+	return nil
+}
+
+// toJSONValue returns obj as the generic values of its JSON so that the
+// templates see the JSON field names.
+func toJSONValue(obj interface{}) (interface{}, error) {
+	switch obj.(type) {
+	case map[string]interface{}, []interface{}:
+		return obj, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+
+	return v, err
+}
+
+// Items returns the items of a list object, or the object itself.
+func Items(obj interface{}) []map[string]interface{} {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	items, ok := m["items"].([]interface{})
+	if !ok {
+		return []map[string]interface{}{m}
+	}
+
+	objs := make([]map[string]interface{}, 0, len(items))
+
+	for _, item := range items {
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			objs = append(objs, itemMap)
+		}
+	}
+
+	return objs
+}
+
+// Kind returns the kind of obj.
+func Kind(obj map[string]interface{}) string {
+	kind, _ := obj["kind"].(string)
+	return kind
+}
+
+// Name returns the metadata.name of obj.
+func Name(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+
+	return name
+}
+
+// Labels returns the metadata.labels of obj.
+func Labels(obj map[string]interface{}) map[string]string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+
+	out := make(map[string]string, len(labels))
+	for key, value := range labels {
+		out[key] = fmt.Sprint(value)
+	}
+
+	return out
+}
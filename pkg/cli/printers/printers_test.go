@@ -0,0 +1,167 @@
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+func newTable() *metav1.Table {
+	return &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name"},
+			{Name: "Holder"},
+			{Name: "Transitions", Priority: 1},
+		},
+		Rows: []metav1.TableRow{
+			{
+				Cells:  []interface{}{"b", "y", float64(1)},
+				Object: json.RawMessage(`{"metadata":{"name":"b"},"spec":{"leaseTransitions":1}}`),
+			},
+			{
+				Cells:  []interface{}{"a", nil, float64(12)},
+				Object: json.RawMessage(`{"metadata":{"name":"a","labels":{"env":"prod"}},"spec":{"leaseTransitions":12}}`),
+			},
+		},
+	}
+}
+
+func TestTablePrinter(t *testing.T) {
+	tests := []struct {
+		name    string
+		options PrintOptions
+		want    string
+	}{
+		{
+			name: "default",
+			want: "NAME   HOLDER\nb      y\na      <none>\n",
+		},
+		{
+			name:    "wide without headers",
+			options: PrintOptions{Wide: true, NoHeaders: true},
+			want:    "b     y        1\na     <none>   12\n",
+		},
+		{
+			name:    "labels",
+			options: PrintOptions{ShowLabels: true, ColumnLabels: []string{"env"}},
+			want:    "NAME   HOLDER   ENV    LABELS\nb      y               <none>\na      <none>   prod   env=prod\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := NewTablePrinter(tt.options).PrintObj(newTable(), &out); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, out.String(), tt.want)
+		}
+	}
+}
+
+func TestTablePrinterHeadersOnce(t *testing.T) {
+	var out bytes.Buffer
+
+	p := NewTablePrinter(PrintOptions{})
+	for i := 0; i < 2; i++ {
+		if err := p.PrintObj(newTable(), &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := strings.Count(out.String(), "NAME"); n != 1 {
+		t.Errorf("headers printed %d times, want once:\n%s", n, out.String())
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	tests := []struct {
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{field: ".metadata.name", want: "a"},
+		{field: "{.spec.leaseTransitions}", want: "b"},
+		{field: ".spec.missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		table := newTable()
+
+		err := SortRows(table, tt.field)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SortRows(%q) error = %v, want error %v", tt.field, err, tt.wantErr)
+			continue
+		}
+
+		if err == nil && table.Rows[0].Cells[0] != tt.want {
+			t.Errorf("SortRows(%q) first row = %v, want %s", tt.field, table.Rows[0].Cells[0], tt.want)
+		}
+	}
+}
+
+func TestPrintFlags(t *testing.T) {
+	list := map[string]interface{}{
+		"kind": "LeaseList",
+		"items": []interface{}{
+			map[string]interface{}{"kind": "Lease", "metadata": map[string]interface{}{"name": "a"}},
+			map[string]interface{}{"kind": "Lease", "metadata": map[string]interface{}{"name": "b"}},
+		},
+	}
+
+	tests := []struct {
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{output: "name", want: "lease/a deleted\nlease/b deleted\n"},
+		{output: "jsonpath={.items[*].metadata.name}", want: "a b"},
+		{output: "go-template={{range .items}}{{.metadata.name}};{{end}}", want: "a;b;"},
+		{output: "json", want: "\"kind\": \"LeaseList\""},
+		{output: "yaml", want: "kind: LeaseList\n"},
+		{output: "jsonpath", wantErr: true},
+		{output: "go-template={{", wantErr: true},
+		{output: "wide", wantErr: true},
+		{output: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p, err := (&PrintFlags{OutputFormat: tt.output}).ToPrinter("deleted")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("-o %s: error = %v, want error %v", tt.output, err, tt.wantErr)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		var out bytes.Buffer
+		if err := p.PrintObj(list, &out); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out.String(), tt.want) {
+			t.Errorf("-o %s = %q, want %q", tt.output, out.String(), tt.want)
+		}
+	}
+}
+
+func TestYAMLPrinterSeparatesDocuments(t *testing.T) {
+	var out bytes.Buffer
+
+	p := &YAMLPrinter{}
+	for _, name := range []string{"a", "b"} {
+		if err := p.PrintObj(map[string]interface{}{"name": name}, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := "name: a\n---\nname: b\n"; out.String() != want {
+		t.Errorf("PrintObj() = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,87 @@
+package printers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hanzhuoxian/flora/pkg/cli/jsonpath"
+)
+
+// SortObjects sorts objs by the results of a JSONPath expression such as
+// .metadata.name or {.spec.leaseTransitions}. Numbers are compared
+// numerically, other values by their text; objects without the field come
+// first.
+func SortObjects(objs []interface{}, field string) error {
+	keys, err := sortKeys(objs, field)
+	if err != nil {
+		return err
+	}
+
+	sort.Stable(&sorter{keys: keys, swap: func(i, j int) {
+		objs[i], objs[j] = objs[j], objs[i]
+	}})
+
+	return nil
+}
+
+// sortKeys returns the first result of field for each object.
+func sortKeys(objs []interface{}, field string) ([]interface{}, error) {
+	j := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := j.Parse(jsonpath.RelaxedExpression(field)); err != nil {
+		return nil, err
+	}
+
+	keys := make([]interface{}, len(objs))
+	found := false
+
+	for i, obj := range objs {
+		results, err := j.FindResults(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(results) != 0 && len(results[0]) != 0 {
+			keys[i] = results[0][0]
+			found = true
+		}
+	}
+
+	if !found && len(objs) != 0 {
+		return nil, fmt.Errorf("couldn't find any field with path %q in the list of objects", field)
+	}
+
+	return keys, nil
+}
+
+// sorter sorts the keys and swaps the sorted values along.
+type sorter struct {
+	keys []interface{}
+	swap func(i, j int)
+}
+
+func (s *sorter) Len() int { return len(s.keys) }
+
+func (s *sorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.swap(i, j)
+}
+
+func (s *sorter) Less(i, j int) bool {
+	a, b := s.keys[i], s.keys[j]
+
+	switch {
+	case a == nil:
+		return b != nil
+	case b == nil:
+		return false
+	}
+
+	af, aok := a.(float64)
+	bf, bok := b.(float64)
+
+	if aok && bok {
+		return af < bf
+	}
+
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
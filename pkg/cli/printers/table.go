@@ -0,0 +1,172 @@
+package printers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hanzhuoxian/flora/pkg/labels"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// PrintOptions controls how tables are printed.
+type PrintOptions struct {
+	// NoHeaders omits the header row.
+	NoHeaders bool
+
+	// Wide shows the columns of every priority, not only priority 0.
+	Wide bool
+
+	// ShowLabels adds a LABELS column with all the labels of the objects.
+	ShowLabels bool
+
+	// ColumnLabels adds a column with the value of each label.
+	ColumnLabels []string
+}
+
+// TablePrinter prints the rows of metav1.Table objects. The headers are
+// printed once, so that the rows of the tables of watch events follow the
+// headers of the first table.
+type TablePrinter struct {
+	options        PrintOptions
+	printedHeaders bool
+}
+
+// NewTablePrinter returns a TablePrinter printing with options.
+func NewTablePrinter(options PrintOptions) *TablePrinter {
+	return &TablePrinter{options: options}
+}
+
+// PrintObj implements ResourcePrinter, obj must be a *metav1.Table.
+func (p *TablePrinter) PrintObj(obj interface{}, w io.Writer) error {
+	table, ok := obj.(*metav1.Table)
+	if !ok {
+		return fmt.Errorf("the table printer cannot print %T", obj)
+	}
+
+	var columns []int
+
+	for i, column := range table.ColumnDefinitions {
+		if column.Priority == 0 || p.options.Wide {
+			columns = append(columns, i)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 6, 4, 3, ' ', 0)
+
+	if !p.options.NoHeaders && !p.printedHeaders {
+		headers := make([]string, 0, len(columns)+len(p.options.ColumnLabels)+1)
+		for _, i := range columns {
+			headers = append(headers, strings.ToUpper(table.ColumnDefinitions[i].Name))
+		}
+
+		for _, label := range p.options.ColumnLabels {
+			headers = append(headers, strings.ToUpper(label))
+		}
+
+		if p.options.ShowLabels {
+			headers = append(headers, "LABELS")
+		}
+
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+		p.printedHeaders = true
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(columns)+len(p.options.ColumnLabels)+1)
+
+		for _, i := range columns {
+			if i < len(row.Cells) {
+				cells = append(cells, formatCell(row.Cells[i]))
+			} else {
+				cells = append(cells, "<none>")
+			}
+		}
+
+		if len(p.options.ColumnLabels) != 0 || p.options.ShowLabels {
+			rowLabels, err := objectLabels(row.Object)
+			if err != nil {
+				return err
+			}
+
+			for _, label := range p.options.ColumnLabels {
+				cells = append(cells, rowLabels[label])
+			}
+
+			if p.options.ShowLabels {
+				cells = append(cells, formatLabels(rowLabels))
+			}
+		}
+
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// formatCell returns the text of a cell, numbers are printed as decoded
+// from JSON without exponent.
+func formatCell(cell interface{}) string {
+	switch t := cell.(type) {
+	case nil:
+		return "<none>"
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(cell)
+}
+
+// formatLabels returns the labels as sorted key=value pairs.
+func formatLabels(set labels.Set) string {
+	if len(set) == 0 {
+		return "<none>"
+	}
+
+	return set.String()
+}
+
+// objectLabels returns the labels of the object of a row.
+func objectLabels(data json.RawMessage) (labels.Set, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var obj struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("unable to decode the object of the row: %w", err)
+	}
+
+	return obj.Metadata.Labels, nil
+}
+
+// SortRows sorts the rows of table by the results of a JSONPath expression
+// on their objects, see SortObjects.
+func SortRows(table *metav1.Table, field string) error {
+	objs := make([]interface{}, len(table.Rows))
+
+	for i, row := range table.Rows {
+		if err := json.Unmarshal(row.Object, &objs[i]); err != nil {
+			return fmt.Errorf("unable to decode the object of the row: %w", err)
+		}
+	}
+
+	keys, err := sortKeys(objs, field)
+	if err != nil {
+		return err
+	}
+
+	sort.Stable(&sorter{keys: keys, swap: func(i, j int) {
+		table.Rows[i], table.Rows[j] = table.Rows[j], table.Rows[i]
+	}})
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// Info is a resource and the name of one of its objects. An empty name
+// stands for all the objects of the resource.
+type Info struct {
+	Resource metav1.APIResource
+	Name     string
+
+	// Source and Object are set for the objects read from manifests, Source
+	// is the file or URL the object comes from.
+	Source string
+	Object map[string]interface{}
+}
+
+// String returns the resource and the name, e.g. "leases/leader".
+func (i Info) String() string {
+	return i.Resource.Name + "/" + i.Name
+}
+
+// ParseArgs resolves command line arguments in the form "TYPE [NAME...]"
+// or "TYPE/NAME...".
+func (m *Mapper) ParseArgs(args []string) ([]Info, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must specify the type of resource, valid resource types are: %s", m.resourceNames())
+	}
+
+	if strings.Contains(args[0], "/") {
+		infos := make([]Info, 0, len(args))
+
+		for _, arg := range args {
+			typ, name, ok := strings.Cut(arg, "/")
+			if !ok || len(typ) == 0 || len(name) == 0 {
+				return nil, fmt.Errorf("arguments in resource/name form must have a single resource and name, got %q", arg)
+			}
+
+			r, err := m.ResourceFor(typ)
+			if err != nil {
+				return nil, err
+			}
+
+			infos = append(infos, Info{Resource: r, Name: name})
+		}
+
+		return infos, nil
+	}
+
+	r, err := m.ResourceFor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 1 {
+		return []Info{{Resource: r}}, nil
+	}
+
+	infos := make([]Info, 0, len(args)-1)
+
+	for _, name := range args[1:] {
+		if strings.Contains(name, "/") {
+			return nil, fmt.Errorf("there is no need to specify a resource type as a separate argument when passing arguments in resource/name form")
+		}
+
+		infos = append(infos, Info{Resource: r, Name: name})
+	}
+
+	return infos, nil
+}
+
+func (m *Mapper) resourceNames() string {
+	names := make([]string, 0, len(m.resources))
+	for _, r := range m.resources {
+		names = append(names, r.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,269 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// manifestExtensions are the extensions of the files read from directories.
+var manifestExtensions = []string{".json", ".yaml", ".yml"}
+
+// FilenameOptions are the manifests named with -f/--filename.
+type FilenameOptions struct {
+	Filenames []string
+	Recursive bool
+}
+
+// AddFlags registers -f/--filename and -R/--recursive on cmd, usage tells
+// what the manifests are used for.
+func (o *FilenameOptions) AddFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames,
+		fmt.Sprintf("Filename, directory, or URL to files %s. Use - to read from stdin.", usage))
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "R", o.Recursive,
+		"Process the directory used in -f, --filename recursively.")
+}
+
+// Validate checks that manifests are named.
+func (o *FilenameOptions) Validate() error {
+	if len(o.Filenames) == 0 {
+		return errors.New("must specify one of -f or --filename")
+	}
+
+	return nil
+}
+
+// Infos reads the objects of the manifests, in is read for "-". The objects
+// are mapped to their resources by mapper.
+func (o *FilenameOptions) Infos(ctx context.Context, mapper *Mapper, in io.Reader) ([]Info, error) {
+	var infos []Info
+
+	for _, filename := range o.Filenames {
+		sources, err := o.read(ctx, filename, in)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range sources {
+			objs, err := Decode(s.data)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %s: %w", s.name, err)
+			}
+
+			for _, obj := range objs {
+				info, err := mapper.InfoForObject(obj)
+				if err != nil {
+					return nil, fmt.Errorf("unable to recognize %q: %w", s.name, err)
+				}
+
+				info.Source = s.name
+				infos = append(infos, info)
+			}
+		}
+	}
+
+	if len(infos) == 0 {
+		return nil, errors.New("no objects passed to the command")
+	}
+
+	return infos, nil
+}
+
+// source is the content of a manifest.
+type source struct {
+	name string
+	data []byte
+}
+
+// read reads the manifests named by filename: stdin, a URL, a file or the
+// files of a directory.
+func (o *FilenameOptions) read(ctx context.Context, filename string, in io.Reader) ([]source, error) {
+	switch {
+	case filename == "-":
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return nil, fmt.Errorf("error reading stdin: %w", err)
+		}
+
+		return []source{{name: "STDIN", data: data}}, nil
+	case strings.HasPrefix(filename, "http://") || strings.HasPrefix(filename, "https://"):
+		data, err := readURL(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		return []source{{name: filename, data: data}}, nil
+	}
+
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("the path %q does not exist", filename)
+	}
+
+	if !stat.IsDir() {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		return []source{{name: filename, data: data}}, nil
+	}
+
+	var sources []source
+
+	err = filepath.WalkDir(filename, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != filename && !o.Recursive {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !hasManifestExtension(path) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		sources = append(sources, source{name: path, data: data})
+
+		return nil
+	})
+
+	return sources, err
+}
+
+// readURL downloads a manifest.
+func readURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read URL %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to read URL %q, server reported %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func hasManifestExtension(path string) bool {
+	ext := filepath.Ext(path)
+	for _, e := range manifestExtensions {
+		if ext == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Decode decodes the YAML documents or JSON objects of data. Empty
+// documents are skipped and the items of lists are returned in place of the
+// lists.
+func Decode(data []byte) ([]map[string]interface{}, error) {
+	var objs []map[string]interface{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+
+			return nil, err
+		}
+
+		if doc == nil {
+			continue
+		}
+
+		// Round trip through JSON so that the values are those decoded from
+		// the responses of the server.
+		encoded, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		var obj map[string]interface{}
+		if err := json.Unmarshal(encoded, &obj); err != nil {
+			return nil, errors.New("the document is not an object")
+		}
+
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(kind(obj), "List") {
+			for _, item := range items {
+				itemObj, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, errors.New("the items of a list must be objects")
+				}
+
+				objs = append(objs, itemObj)
+			}
+
+			continue
+		}
+
+		objs = append(objs, obj)
+	}
+}
+
+// InfoForObject returns the resource and the name of obj, which must set
+// apiVersion, kind and metadata.name.
+func (m *Mapper) InfoForObject(obj map[string]interface{}) (Info, error) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	if len(apiVersion) == 0 {
+		return Info{}, errors.New("apiVersion not set")
+	}
+
+	if len(kind(obj)) == 0 {
+		return Info{}, errors.New("kind not set")
+	}
+
+	r, err := m.ResourceForKind(scheme.FromAPIVersionAndKind(apiVersion, kind(obj)))
+	if err != nil {
+		return Info{}, err
+	}
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+
+	name, _ := metadata["name"].(string)
+	if len(name) == 0 {
+		return Info{}, fmt.Errorf("the %s has no metadata.name", r.SingularName)
+	}
+
+	return Info{Resource: r, Name: name, Object: obj}, nil
+}
+
+func kind(obj map[string]interface{}) string {
+	kind, _ := obj["kind"].(string)
+	return kind
+}
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{data: "kind: Lease\nmetadata:\n  name: a\n", want: []string{"a"}},
+		{data: "---\nkind: Lease\nmetadata:\n  name: a\n---\n---\nkind: Lease\nmetadata:\n  name: b\n", want: []string{"a", "b"}},
+		{data: `{"kind": "Lease", "metadata": {"name": "a"}}`, want: []string{"a"}},
+		{data: "kind: List\nitems:\n- kind: Lease\n  metadata:\n    name: a\n- kind: Lease\n  metadata:\n    name: b\n", want: []string{"a", "b"}},
+		{data: "", want: nil},
+		{data: "- a\n- b\n", wantErr: true},
+		{data: "kind: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		objs, err := Decode([]byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Decode(%q) error = %v, want error %v", tt.data, err, tt.wantErr)
+			continue
+		}
+
+		var got []string
+
+		for _, obj := range objs {
+			metadata, _ := obj["metadata"].(map[string]interface{})
+			got = append(got, metadata["name"].(string))
+		}
+
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Decode(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestFilenameOptionsInfos(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, data := range map[string]string{
+		"a.yaml":        "apiVersion: apiserver/v1\nkind: Lease\nmetadata:\n  name: a\n",
+		"b.json":        `{"apiVersion": "apiserver/v1", "kind": "Lease", "metadata": {"name": "b"}}`,
+		"README.md":     "not a manifest",
+		"sub/c.yml":     "apiVersion: apiserver/v1\nkind: Lease\nmetadata:\n  name: c\n",
+		"bad/kind.yaml": "apiVersion: apiserver/v1\nkind: User\nmetadata:\n  name: d\n",
+		"bad/name.yaml": "apiVersion: apiserver/v1\nkind: Lease\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mapper := NewMapper(scheme.GroupVersion{Group: "apiserver", Version: "v1"}, []metav1.APIResource{
+		{Name: "leases", SingularName: "lease", Kind: "Lease"},
+	})
+	stdin := "apiVersion: apiserver/v1\nkind: Lease\nmetadata:\n  name: stdin\n"
+
+	tests := []struct {
+		opts    FilenameOptions
+		want    []string
+		wantErr bool
+	}{
+		{opts: FilenameOptions{Filenames: []string{dir}}, want: []string{"leases/a", "leases/b"}},
+		{opts: FilenameOptions{Filenames: []string{filepath.Join(dir, "sub")}}, want: []string{"leases/c"}},
+		{opts: FilenameOptions{Filenames: []string{"-", filepath.Join(dir, "a.yaml")}}, want: []string{"leases/stdin", "leases/a"}},
+		{opts: FilenameOptions{Filenames: []string{filepath.Join(dir, "sub")}, Recursive: true}, want: []string{"leases/c"}},
+		{opts: FilenameOptions{Filenames: []string{dir}, Recursive: true}, wantErr: true},
+		{opts: FilenameOptions{Filenames: []string{filepath.Join(dir, "bad", "name.yaml")}}, wantErr: true},
+		{opts: FilenameOptions{Filenames: []string{filepath.Join(dir, "README.md")}}, wantErr: true},
+		{opts: FilenameOptions{Filenames: []string{filepath.Join(dir, "missing.yaml")}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		infos, err := tt.opts.Infos(context.Background(), mapper, strings.NewReader(stdin))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Infos(%+v) error = %v, want error %v", tt.opts, err, tt.wantErr)
+			continue
+		}
+
+		got := make([]string, 0, len(infos))
+		for _, info := range infos {
+			got = append(got, info.String())
+		}
+
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Infos(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
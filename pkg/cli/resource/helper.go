@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// DryRunStrategy tells whether and where the changes of a command are only
+// simulated.
+type DryRunStrategy int
+
+const (
+	// DryRunNone sends the changes to the server.
+	DryRunNone DryRunStrategy = iota
+	// DryRunClient prints the objects that would be sent without sending
+	// them.
+	DryRunClient
+	// DryRunServer sends the changes to the server, which validates them
+	// without persisting them.
+	DryRunServer
+)
+
+// AddDryRunFlag registers --dry-run on cmd.
+func AddDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().String("dry-run", "none",
+		`Must be "none", "server", or "client". If client strategy, only print the object that would be sent, `+
+			`without sending it. If server strategy, submit server-side request without persisting the resource.`)
+}
+
+// GetDryRunStrategy returns the strategy selected with --dry-run.
+func GetDryRunStrategy(cmd *cobra.Command) (DryRunStrategy, error) {
+	dryRun, err := cmd.Flags().GetString("dry-run")
+	if err != nil {
+		return DryRunNone, err
+	}
+
+	switch dryRun {
+	case "", "none":
+		return DryRunNone, nil
+	case "client":
+		return DryRunClient, nil
+	case "server":
+		return DryRunServer, nil
+	}
+
+	return DryRunNone, fmt.Errorf(`invalid dry-run value (%v). Must be "none", "server", or "client"`, dryRun)
+}
+
+// Suffix returns the text appended to the operations printed by the
+// commands, e.g. "created (dry run)".
+func (s DryRunStrategy) Suffix() string {
+	switch s {
+	case DryRunClient:
+		return " (dry run)"
+	case DryRunServer:
+		return " (server dry run)"
+	}
+
+	return ""
+}
+
+// Helper gets and changes the objects of a resource as generic objects.
+type Helper struct {
+	client   rest.Interface
+	resource string
+	dryRun   bool
+}
+
+// NewHelper returns a Helper for the objects of resource r.
+func NewHelper(client rest.Interface, r metav1.APIResource) *Helper {
+	return &Helper{client: client, resource: r.Name}
+}
+
+// DryRun makes the server validate the changes without persisting them.
+func (h *Helper) DryRun(dryRun bool) *Helper {
+	h.dryRun = dryRun
+	return h
+}
+
+// Get returns the object named name.
+func (h *Helper) Get(ctx context.Context, name string) (map[string]interface{}, error) {
+	return into(h.client.Get().Resource(h.resource).Name(name).Do(ctx))
+}
+
+// Create creates obj.
+func (h *Helper) Create(ctx context.Context, obj map[string]interface{}) (map[string]interface{}, error) {
+	return into(h.client.Post().Resource(h.resource).VersionedParams(h.createOptions()).Body(obj).Do(ctx))
+}
+
+// Replace replaces the object named name with obj. The update fails with a
+// conflict if the resource version of obj is stale.
+func (h *Helper) Replace(ctx context.Context, name string, obj map[string]interface{}) (map[string]interface{}, error) {
+	return into(h.client.Put().Resource(h.resource).Name(name).VersionedParams(h.updateOptions()).Body(obj).Do(ctx))
+}
+
+// Delete deletes the object named name and returns its last state.
+func (h *Helper) Delete(ctx context.Context, name string) (map[string]interface{}, error) {
+	return into(h.client.Delete().Resource(h.resource).Name(name).VersionedParams(h.deleteOptions()).Do(ctx))
+}
+
+func (h *Helper) createOptions() *metav1.CreateOptions {
+	return &metav1.CreateOptions{DryRun: h.dryRunValue()}
+}
+
+func (h *Helper) updateOptions() *metav1.UpdateOptions {
+	return &metav1.UpdateOptions{DryRun: h.dryRunValue()}
+}
+
+func (h *Helper) deleteOptions() *metav1.DeleteOptions {
+	return &metav1.DeleteOptions{DryRun: h.dryRunValue()}
+}
+
+func (h *Helper) dryRunValue() []string {
+	if h.dryRun {
+		return []string{metav1.DryRunAll}
+	}
+
+	return nil
+}
+
+func into(result rest.Result) (map[string]interface{}, error) {
+	data, err := result.Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
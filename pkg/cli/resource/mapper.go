@@ -0,0 +1,89 @@
+// Package resource resolves the resources named on the command line of
+// floractl, e.g. "leases", "lease" or "Lease", through the discovery of the
+// resources served by the apiserver.
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// Mapper maps resource arguments and kinds to the resources served by the
+// apiserver.
+type Mapper struct {
+	groupVersion scheme.GroupVersion
+	resources    []metav1.APIResource
+}
+
+// NewMapper returns a Mapper of the resources of groupVersion.
+func NewMapper(groupVersion scheme.GroupVersion, resources []metav1.APIResource) *Mapper {
+	return &Mapper{groupVersion: groupVersion, resources: resources}
+}
+
+// Discover asks the apiserver for the resources it serves.
+func Discover(ctx context.Context, client rest.Interface) (*Mapper, error) {
+	list := &metav1.APIResourceList{}
+	if err := client.Get().Do(ctx).Into(list); err != nil {
+		return nil, fmt.Errorf("unable to discover the resources of the server: %w", err)
+	}
+
+	gv, err := scheme.ParseGroupVersion(list.GroupVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMapper(gv, list.APIResources), nil
+}
+
+// Resources returns the resources served by the apiserver.
+func (m *Mapper) Resources() []metav1.APIResource {
+	return m.resources
+}
+
+// ResourceFor returns the resource named by arg, which is matched case
+// insensitively against the plural name, the singular name, the short
+// names and the kind of the resources. arg may be qualified with the group,
+// e.g. leases.apiserver.
+func (m *Mapper) ResourceFor(arg string) (metav1.APIResource, error) {
+	name := strings.ToLower(arg)
+
+	if gvr, gr := scheme.ParseResourceArg(name); gr.Group == m.groupVersion.Group {
+		name = gr.Resource
+	} else if gvr != nil && gvr.GroupVersion() == m.groupVersion {
+		name = gvr.Resource
+	}
+
+	for _, r := range m.resources {
+		if name == r.Name || name == r.SingularName || strings.EqualFold(name, r.Kind) {
+			return r, nil
+		}
+
+		for _, short := range r.ShortNames {
+			if name == short {
+				return r, nil
+			}
+		}
+	}
+
+	return metav1.APIResource{}, fmt.Errorf("the server doesn't have a resource type %q", arg)
+}
+
+// ResourceForKind returns the resource of the objects of gvk.
+func (m *Mapper) ResourceForKind(gvk scheme.GroupVersionKind) (metav1.APIResource, error) {
+	if gvk.GroupVersion() != m.groupVersion {
+		return metav1.APIResource{}, fmt.Errorf("no matches for kind %q in version %q", gvk.Kind, gvk.GroupVersion())
+	}
+
+	for _, r := range m.resources {
+		if r.Kind == gvk.Kind {
+			return r, nil
+		}
+	}
+
+	return metav1.APIResource{}, fmt.Errorf("no matches for kind %q in version %q", gvk.Kind, gvk.GroupVersion())
+}
@@ -0,0 +1,90 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+func TestMapper(t *testing.T) {
+	gv := scheme.GroupVersion{Group: "apiserver", Version: "v1"}
+	m := NewMapper(gv, []metav1.APIResource{
+		{Name: "leases", SingularName: "lease", Kind: "Lease"},
+		{Name: "policies", SingularName: "policy", Kind: "Policy", ShortNames: []string{"pol"}},
+	})
+
+	tests := []struct {
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{arg: "leases", want: "leases"},
+		{arg: "lease", want: "leases"},
+		{arg: "Lease", want: "leases"},
+		{arg: "LEASES", want: "leases"},
+		{arg: "leases.apiserver", want: "leases"},
+		{arg: "leases.v1.apiserver", want: "leases"},
+		{arg: "pol", want: "policies"},
+		{arg: "leases.other", wantErr: true},
+		{arg: "users", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r, err := m.ResourceFor(tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResourceFor(%q) error = %v, want error %v", tt.arg, err, tt.wantErr)
+			continue
+		}
+
+		if r.Name != tt.want {
+			t.Errorf("ResourceFor(%q) = %q, want %q", tt.arg, r.Name, tt.want)
+		}
+	}
+
+	if r, err := m.ResourceForKind(gv.WithKind("Policy")); err != nil || r.Name != "policies" {
+		t.Errorf("ResourceForKind(Policy) = %q, %v, want policies", r.Name, err)
+	}
+
+	if _, err := m.ResourceForKind(scheme.GroupVersionKind{Version: "v2", Kind: "Lease"}); err == nil {
+		t.Errorf("ResourceForKind(v2 Lease) succeeded, want an error")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	m := NewMapper(scheme.GroupVersion{Group: "apiserver", Version: "v1"}, []metav1.APIResource{
+		{Name: "leases", SingularName: "lease", Kind: "Lease"},
+	})
+
+	tests := []struct {
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{args: []string{"leases"}, want: []string{"leases/"}},
+		{args: []string{"lease", "a", "b"}, want: []string{"leases/a", "leases/b"}},
+		{args: []string{"lease/a", "leases/b"}, want: []string{"leases/a", "leases/b"}},
+		{args: nil, wantErr: true},
+		{args: []string{"lease/"}, wantErr: true},
+		{args: []string{"lease", "lease/a"}, wantErr: true},
+		{args: []string{"users", "a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		infos, err := m.ParseArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseArgs(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			continue
+		}
+
+		got := make([]string, 0, len(infos))
+		for _, info := range infos {
+			got = append(got, info.Resource.Name+"/"+info.Name)
+		}
+
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("ParseArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package resource
+
+import "reflect"
+
+// ThreeWayMerge returns current with the changes made from original to
+// modified, so that the changes made by others since original are kept:
+//
+//   - A field of original missing from modified is removed.
+//   - A field of modified with the same value as in original is left as it
+//     is in current.
+//   - A null field of modified removes the field.
+//   - An object of modified is merged field by field into the object of
+//     current; any other value, lists included, replaces the value of
+//     current.
+//
+// A nil original, e.g. an object applied for the first time, removes
+// nothing and sets every field of modified.
+func ThreeWayMerge(original, modified, current map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(current))
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	for key := range original {
+		if _, ok := modified[key]; !ok {
+			delete(merged, key)
+		}
+	}
+
+	for key, value := range modified {
+		if originalValue, ok := original[key]; ok && reflect.DeepEqual(value, originalValue) {
+			continue
+		}
+
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		valueMap, isMap := value.(map[string]interface{})
+		currentMap, currentIsMap := merged[key].(map[string]interface{})
+
+		if isMap && currentIsMap {
+			originalMap, _ := original[key].(map[string]interface{})
+			merged[key] = ThreeWayMerge(originalMap, valueMap, currentMap)
+
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	tests := []struct {
+		original, modified, current string
+		want                        string
+	}{
+		{
+			// Fields set by others are kept.
+			original: `{"spec": {"a": 1}}`,
+			modified: `{"spec": {"a": 2}}`,
+			current:  `{"spec": {"a": 1, "b": 1}, "status": 1}`,
+			want:     `{"spec": {"a": 2, "b": 1}, "status": 1}`,
+		},
+		{
+			// Fields changed by others and left unchanged in modified are
+			// kept.
+			original: `{"spec": {"a": 1, "b": 1}}`,
+			modified: `{"spec": {"a": 2, "b": 1}}`,
+			current:  `{"spec": {"a": 1, "b": 3}}`,
+			want:     `{"spec": {"a": 2, "b": 3}}`,
+		},
+		{
+			// Fields removed from modified are removed.
+			original: `{"spec": {"a": 1, "b": 1}, "c": 1}`,
+			modified: `{"spec": {"a": 1}}`,
+			current:  `{"spec": {"a": 1, "b": 1, "c": 1}, "c": 2, "d": 1}`,
+			want:     `{"spec": {"a": 1, "c": 1}, "d": 1}`,
+		},
+		{
+			// Lists are replaced and null removes a field.
+			original: `{"spec": {"l": [1, 2], "c": 1}}`,
+			modified: `{"spec": {"l": [3], "c": null, "d": null}}`,
+			current:  `{"spec": {"l": [1, 2], "c": 1, "d": 1}}`,
+			want:     `{"spec": {"l": [3]}}`,
+		},
+		{
+			// An object replacing another value is set as a whole.
+			original: `{"spec": 1}`,
+			modified: `{"spec": {"a": 1}}`,
+			current:  `{"spec": 2}`,
+			want:     `{"spec": {"a": 1}}`,
+		},
+		{
+			// Without original nothing is removed.
+			original: `null`,
+			modified: `{"spec": {"a": 2}}`,
+			current:  `{"spec": {"a": 1, "b": 1}}`,
+			want:     `{"spec": {"a": 2, "b": 1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		var original, modified, current, want map[string]interface{}
+
+		for _, v := range []struct {
+			data string
+			obj  *map[string]interface{}
+		}{{tt.original, &original}, {tt.modified, &modified}, {tt.current, &current}, {tt.want, &want}} {
+			if err := json.Unmarshal([]byte(v.data), v.obj); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got := ThreeWayMerge(original, modified, current); !reflect.DeepEqual(got, want) {
+			t.Errorf("ThreeWayMerge(%s, %s, %s) = %v, want %s", tt.original, tt.modified, tt.current, got, tt.want)
+		}
+	}
+}
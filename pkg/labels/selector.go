@@ -0,0 +1,272 @@
+// Package labels implements the label selectors used to filter the objects
+// of list and watch calls, e.g. "env=prod,tier in (web,api),!canary".
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Set is a map of label keys to values.
+type Set map[string]string
+
+// String returns the labels of the set as a sorted selector string.
+func (s Set) String() string {
+	pairs := make([]string, 0, len(s))
+	for key, value := range s {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Operator is the comparison of a requirement.
+type Operator string
+
+// The operators of a requirement.
+const (
+	Equals       Operator = "="
+	DoubleEquals Operator = "=="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Requirement is a single condition on a label, e.g. "env=prod".
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Matches returns true if the labels satisfy the requirement.
+func (r Requirement) Matches(labels Set) bool {
+	value, ok := labels[r.Key]
+
+	switch r.Operator {
+	case Equals, DoubleEquals, In:
+		return ok && r.hasValue(value)
+	case NotEquals, NotIn:
+		return !ok || !r.hasValue(value)
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	}
+
+	return false
+}
+
+func (r Requirement) hasValue(value string) bool {
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the requirement in the selector syntax.
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case In, NotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	}
+
+	return r.Key + string(r.Operator) + r.Values[0]
+}
+
+// Selector is a conjunction of requirements. The zero value selects
+// everything.
+type Selector []Requirement
+
+// Everything returns a selector that matches all labels.
+func Everything() Selector {
+	return nil
+}
+
+// SelectorFromSet returns a selector that requires every label of the set.
+func SelectorFromSet(set Set) Selector {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	selector := make(Selector, 0, len(keys))
+	for _, key := range keys {
+		selector = append(selector, Requirement{Key: key, Operator: Equals, Values: []string{set[key]}})
+	}
+
+	return selector
+}
+
+// Matches returns true if the labels satisfy every requirement.
+func (s Selector) Matches(labels Set) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Empty returns true if the selector matches everything.
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// String returns the selector in the syntax accepted by Parse.
+func (s Selector) String() string {
+	requirements := make([]string, 0, len(s))
+	for _, r := range s {
+		requirements = append(requirements, r.String())
+	}
+
+	return strings.Join(requirements, ",")
+}
+
+// Parse parses a comma separated list of requirements. Each requirement is
+// one of:
+//
+//	key=value, key==value, key!=value
+//	key in (value1,value2), key notin (value1,value2)
+//	key, !key
+func Parse(selector string) (Selector, error) {
+	var s Selector
+
+	for _, part := range splitRequirements(selector) {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		r, err := parseRequirement(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+		}
+
+		s = append(s, r)
+	}
+
+	return s, nil
+}
+
+// splitRequirements splits the selector on the commas outside parentheses.
+func splitRequirements(selector string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, selector[start:])
+}
+
+func parseRequirement(s string) (Requirement, error) {
+	if strings.HasPrefix(s, "!") && !strings.HasPrefix(s, "!=") {
+		key := strings.TrimSpace(s[1:])
+
+		return Requirement{Key: key, Operator: DoesNotExist}, validateKey(key)
+	}
+
+	for _, op := range []Operator{NotEquals, DoubleEquals, Equals} {
+		if key, value, ok := strings.Cut(s, string(op)); ok {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if err := validateKey(key); err != nil {
+				return Requirement{}, err
+			}
+
+			if err := validateValue(value); err != nil {
+				return Requirement{}, err
+			}
+
+			return Requirement{Key: key, Operator: op, Values: []string{value}}, nil
+		}
+	}
+
+	if key, rest, ok := strings.Cut(s, " "); ok {
+		return parseSetRequirement(key, strings.TrimSpace(rest))
+	}
+
+	return Requirement{Key: s, Operator: Exists}, validateKey(s)
+}
+
+// parseSetRequirement parses the "in (a,b)" or "notin (a,b)" part of a
+// requirement on key.
+func parseSetRequirement(key, s string) (Requirement, error) {
+	if err := validateKey(key); err != nil {
+		return Requirement{}, err
+	}
+
+	op, values, _ := strings.Cut(s, "(")
+	operator := Operator(strings.TrimSpace(op))
+
+	if operator != In && operator != NotIn {
+		return Requirement{}, fmt.Errorf("unknown operator %q, expected one of =, ==, !=, in, notin", op)
+	}
+
+	values = strings.TrimSpace(values)
+	if !strings.HasSuffix(values, ")") {
+		return Requirement{}, fmt.Errorf("the values of %s %s must be enclosed in parentheses", key, operator)
+	}
+
+	r := Requirement{Key: key, Operator: operator}
+
+	for _, value := range strings.Split(strings.TrimSuffix(values, ")"), ",") {
+		value = strings.TrimSpace(value)
+		if err := validateValue(value); err != nil {
+			return Requirement{}, err
+		}
+
+		r.Values = append(r.Values, value)
+	}
+
+	return r, nil
+}
+
+func validateKey(key string) error {
+	if len(key) == 0 {
+		return fmt.Errorf("empty label key")
+	}
+
+	if strings.ContainsAny(key, " !=(),") {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+
+	return nil
+}
+
+func validateValue(value string) error {
+	if strings.ContainsAny(value, " !=(),") {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package labels
+
+import "testing"
+
+func TestSelector(t *testing.T) {
+	labels := Set{"env": "prod", "tier": "web"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env==prod", true},
+		{"env=dev", false},
+		{"env!=dev", true},
+		{"canary!=true", true},
+		{"env=prod,tier=api", false},
+		{"tier in (web,api)", true},
+		{"tier in (api)", false},
+		{"tier notin (web, api)", false},
+		{"env, !canary", true},
+		{"!env", false},
+		{"env=", false},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.selector)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.selector, err)
+			continue
+		}
+
+		if got := s.Matches(labels); got != tt.want {
+			t.Errorf("Parse(%q).Matches(%v) = %v, want %v", tt.selector, labels, got, tt.want)
+		}
+
+		// The string form parses to the same selector.
+		if again, err := Parse(s.String()); err != nil || again.String() != s.String() {
+			t.Errorf("Parse(%q) = %q, want %q, error %v", s.String(), again, s, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, selector := range []string{
+		"=prod",
+		"env=a=b",
+		"tier in web",
+		"tier like (web)",
+		"env in (a b)",
+		"!",
+	} {
+		if _, err := Parse(selector); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", selector)
+		}
+	}
+}
+
+func TestSelectorFromSet(t *testing.T) {
+	s := SelectorFromSet(Set{"tier": "web", "env": "prod"})
+	if got := s.String(); got != "env=prod,tier=web" {
+		t.Errorf("SelectorFromSet() = %q, want env=prod,tier=web", got)
+	}
+}
@@ -0,0 +1,387 @@
+// Package leaderelection implements leader election of a set of endpoints.
+// It uses a lease stored in flora-apiserver as the lock, so that only one of
+// the replicas of a component is active at a time.
+//
+// A client only acts on timestamps captured locally to infer the state of the
+// leader election. The client does not consider timestamps in the leader
+// election record to be accurate because these timestamps may not have been
+// produced by a local clock. The implementation does not depend on their
+// accuracy and only uses their change to indicate that another client has
+// renewed the leader lease. Thus the implementation is tolerant to arbitrary
+// clock skew, but is not tolerant to arbitrary clock skew rate.
+package leaderelection
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	rl "github.com/hanzhuoxian/flora/pkg/leaderelection/resourcelock"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// JitterFactor is multiplied by RetryPeriod to spread the retries of the candidates.
+const JitterFactor = 1.2
+
+// LeaderElectionConfig configures a LeaderElector.
+type LeaderElectionConfig struct {
+	// Lock is the resource that will be used for locking
+	Lock rl.Interface
+
+	// LeaseDuration is the duration that non-leader candidates will
+	// wait to force acquire leadership. This is measured against time of
+	// last observed ack.
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration that the acting master will retry
+	// refreshing leadership before giving up.
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration the LeaderElector clients should wait
+	// between tries of actions.
+	RetryPeriod time.Duration
+
+	// Callbacks are callbacks that are triggered during certain lifecycle
+	// events of the LeaderElector
+	Callbacks LeaderCallbacks
+
+	// ReleaseOnCancel should be set true if the lock should be released
+	// when the run context is cancelled. If you set this to true, you must
+	// ensure all code guarded by this lease has successfully completed
+	// prior to cancelling the context, or you may have two processes
+	// simultaneously acting on the critical path.
+	ReleaseOnCancel bool
+
+	// Name is the name of the resource lock for debugging
+	Name string
+}
+
+// LeaderCallbacks are callbacks that are triggered during certain
+// lifecycle events of the LeaderElector. These are invoked asynchronously.
+//
+// possible future callbacks:
+//   - OnChallenge()
+type LeaderCallbacks struct {
+	// OnStartedLeading is called when a LeaderElector client starts leading
+	OnStartedLeading func(context.Context)
+	// OnStoppedLeading is called when a LeaderElector client stops leading
+	OnStoppedLeading func()
+	// OnNewLeader is called when the client observes a leader that is
+	// not the previously observed leader. This includes the first observed
+	// leader when the client starts.
+	OnNewLeader func(identity string)
+}
+
+// NewLeaderElector creates a LeaderElector from a LeaderElectionConfig.
+func NewLeaderElector(lec LeaderElectionConfig) (*LeaderElector, error) {
+	if lec.LeaseDuration <= lec.RenewDeadline {
+		return nil, errors.New("leaseDuration must be greater than renewDeadline")
+	}
+
+	if lec.RenewDeadline <= time.Duration(JitterFactor*float64(lec.RetryPeriod)) {
+		return nil, errors.New("renewDeadline must be greater than retryPeriod*JitterFactor")
+	}
+
+	if lec.LeaseDuration < 1 {
+		return nil, errors.New("leaseDuration must be greater than zero")
+	}
+
+	if lec.RenewDeadline < 1 {
+		return nil, errors.New("renewDeadline must be greater than zero")
+	}
+
+	if lec.RetryPeriod < 1 {
+		return nil, errors.New("retryPeriod must be greater than zero")
+	}
+
+	if lec.Callbacks.OnStartedLeading == nil {
+		return nil, errors.New("OnStartedLeading callback must not be nil")
+	}
+
+	if lec.Callbacks.OnStoppedLeading == nil {
+		return nil, errors.New("OnStoppedLeading callback must not be nil")
+	}
+
+	if lec.Lock == nil {
+		return nil, errors.New("lock must not be nil")
+	}
+
+	if len(lec.Lock.Identity()) == 0 {
+		return nil, errors.New("lock identity is empty")
+	}
+
+	return &LeaderElector{config: lec}, nil
+}
+
+// LeaderElector is a leader election client.
+type LeaderElector struct {
+	config LeaderElectionConfig
+	// internal bookkeeping
+	observedRecord    rl.LeaderElectionRecord
+	observedRawRecord []byte
+	observedTime      time.Time
+	// used to implement OnNewLeader(), may lag slightly from the
+	// value observedRecord.HolderIdentity if the transition has
+	// not yet been reported.
+	reportedLeader string
+
+	// used to lock the observedRecord
+	observedRecordLock sync.Mutex
+}
+
+// Run starts the leader election loop. Run will not return
+// before leader election loop is stopped by ctx or it has
+// stopped holding the leader lease.
+func (le *LeaderElector) Run(ctx context.Context) {
+	defer le.config.Callbacks.OnStoppedLeading()
+
+	if !le.acquire(ctx) {
+		return // ctx signalled done
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go le.config.Callbacks.OnStartedLeading(ctx)
+	le.renew(ctx)
+}
+
+// RunOrDie starts a client with the provided config or panics if the config
+// fails to validate. RunOrDie blocks until leader election loop is
+// stopped by ctx or it has stopped holding the leader lease.
+func RunOrDie(ctx context.Context, lec LeaderElectionConfig) {
+	le, err := NewLeaderElector(lec)
+	if err != nil {
+		panic(err)
+	}
+
+	le.Run(ctx)
+}
+
+// GetLeader returns the identity of the last observed leader or returns the empty string if
+// no leader has yet been observed.
+// This function is for informational purposes. (e.g. monitoring, logs, etc.).
+func (le *LeaderElector) GetLeader() string {
+	return le.getObservedRecord().HolderIdentity
+}
+
+// IsLeader returns true if the last observed leader was this client else returns false.
+func (le *LeaderElector) IsLeader() bool {
+	return le.getObservedRecord().HolderIdentity == le.config.Lock.Identity()
+}
+
+// acquire loops calling tryAcquireOrRenew and returns true immediately when tryAcquireOrRenew succeeds.
+// Returns false if ctx signals done.
+func (le *LeaderElector) acquire(ctx context.Context) bool {
+	desc := le.config.Lock.Describe()
+	log.Infof("Attempting to acquire leader lease %v...", desc)
+
+	for {
+		if le.tryAcquireOrRenew(ctx) {
+			log.Infof("Successfully acquired lease %v", desc)
+
+			return true
+		}
+
+		le.maybeReportTransition()
+
+		if !sleep(ctx, jitter(le.config.RetryPeriod, JitterFactor)) {
+			return false
+		}
+	}
+}
+
+// renew loops calling tryAcquireOrRenew and returns immediately when tryAcquireOrRenew fails or ctx signals done.
+func (le *LeaderElector) renew(ctx context.Context) {
+	defer le.maybeRelease()
+
+	for {
+		timeoutCtx, timeoutCancel := context.WithTimeout(ctx, le.config.RenewDeadline)
+		renewed := le.renewUntil(timeoutCtx)
+
+		timeoutCancel()
+
+		desc := le.config.Lock.Describe()
+		if !renewed {
+			if ctx.Err() == nil {
+				log.Infof("Failed to renew lease %v: timed out", desc)
+			}
+
+			return
+		}
+
+		le.maybeReportTransition()
+		log.V(1).Infof("Successfully renewed lease %v", desc)
+
+		if !sleep(ctx, le.config.RetryPeriod) {
+			return
+		}
+	}
+}
+
+// renewUntil retries tryAcquireOrRenew every RetryPeriod until it succeeds or ctx is done.
+func (le *LeaderElector) renewUntil(ctx context.Context) bool {
+	for {
+		if le.tryAcquireOrRenew(ctx) {
+			return true
+		}
+
+		if !sleep(ctx, le.config.RetryPeriod) {
+			return false
+		}
+	}
+}
+
+func (le *LeaderElector) maybeRelease() {
+	if le.config.ReleaseOnCancel {
+		le.release()
+	}
+}
+
+// release attempts to release the leader lease if we have acquired it.
+func (le *LeaderElector) release() bool {
+	if !le.IsLeader() {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), le.config.RenewDeadline)
+	defer cancel()
+
+	now := time.Now()
+	leaderElectionRecord := rl.LeaderElectionRecord{
+		LeaderTransitions:    le.getObservedRecord().LeaderTransitions,
+		LeaseDurationSeconds: 1,
+		RenewTime:            now,
+		AcquireTime:          now,
+	}
+
+	if err := le.config.Lock.Update(ctx, leaderElectionRecord); err != nil {
+		log.Errorf("Failed to release lock: %v", err)
+
+		return false
+	}
+
+	le.setObservedRecord(&leaderElectionRecord)
+
+	return true
+}
+
+// tryAcquireOrRenew tries to acquire a leader lease if it is not already acquired,
+// else it tries to renew the lease if it has already been acquired. Returns true
+// on success else returns false.
+func (le *LeaderElector) tryAcquireOrRenew(ctx context.Context) bool {
+	now := time.Now()
+	leaderElectionRecord := rl.LeaderElectionRecord{
+		HolderIdentity:       le.config.Lock.Identity(),
+		LeaseDurationSeconds: int(le.config.LeaseDuration / time.Second),
+		RenewTime:            now,
+		AcquireTime:          now,
+	}
+
+	// 1. obtain or create the ElectionRecord
+	oldLeaderElectionRecord, oldLeaderElectionRawRecord, err := le.config.Lock.Get(ctx)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Errorf("Error retrieving resource lock %v: %v", le.config.Lock.Describe(), err)
+
+			return false
+		}
+
+		if err = le.config.Lock.Create(ctx, leaderElectionRecord); err != nil {
+			log.Errorf("Error initially creating leader election record: %v", err)
+
+			return false
+		}
+
+		le.setObservedRecord(&leaderElectionRecord)
+
+		return true
+	}
+
+	// 2. Record obtained, check the Identity & Time
+	if !bytes.Equal(le.observedRawRecord, oldLeaderElectionRawRecord) {
+		le.setObservedRecord(oldLeaderElectionRecord)
+
+		le.observedRawRecord = oldLeaderElectionRawRecord
+	}
+
+	if len(oldLeaderElectionRecord.HolderIdentity) > 0 &&
+		le.observedTime.Add(time.Second*time.Duration(oldLeaderElectionRecord.LeaseDurationSeconds)).After(now) &&
+		!le.IsLeader() {
+		log.V(1).Infof("Lock is held by %v and has not yet expired", oldLeaderElectionRecord.HolderIdentity)
+
+		return false
+	}
+
+	// 3. We're going to try to update. The leaderElectionRecord is set to it's default
+	// here. Let's correct it before updating.
+	if le.IsLeader() {
+		leaderElectionRecord.AcquireTime = oldLeaderElectionRecord.AcquireTime
+		leaderElectionRecord.LeaderTransitions = oldLeaderElectionRecord.LeaderTransitions
+	} else {
+		leaderElectionRecord.LeaderTransitions = oldLeaderElectionRecord.LeaderTransitions + 1
+	}
+
+	// update the lock itself
+	if err = le.config.Lock.Update(ctx, leaderElectionRecord); err != nil {
+		log.Errorf("Failed to update lock: %v", err)
+
+		return false
+	}
+
+	le.setObservedRecord(&leaderElectionRecord)
+
+	return true
+}
+
+func (le *LeaderElector) maybeReportTransition() {
+	if le.observedRecord.HolderIdentity == le.reportedLeader {
+		return
+	}
+
+	le.reportedLeader = le.observedRecord.HolderIdentity
+
+	if le.config.Callbacks.OnNewLeader != nil {
+		go le.config.Callbacks.OnNewLeader(le.reportedLeader)
+	}
+}
+
+// setObservedRecord will set a new observedRecord and update observedTime to the current time.
+// Protect critical sections with lock.
+func (le *LeaderElector) setObservedRecord(observedRecord *rl.LeaderElectionRecord) {
+	le.observedRecordLock.Lock()
+	defer le.observedRecordLock.Unlock()
+
+	le.observedRecord = *observedRecord
+	le.observedTime = time.Now()
+}
+
+// getObservedRecord returns observersRecord.
+// Protect critical sections with lock.
+func (le *LeaderElector) getObservedRecord() rl.LeaderElectionRecord {
+	le.observedRecordLock.Lock()
+	defer le.observedRecordLock.Unlock()
+
+	return le.observedRecord
+}
+
+// jitter returns a time.Duration between duration and duration + maxFactor * duration.
+func jitter(duration time.Duration, maxFactor float64) time.Duration {
+	//nolint: gosec // no need for a cryptographically secure jitter
+	return duration + time.Duration(rand.Float64()*maxFactor*float64(duration))
+}
+
+// sleep waits for d and returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
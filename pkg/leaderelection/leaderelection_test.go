@@ -0,0 +1,209 @@
+package leaderelection
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	rl "github.com/hanzhuoxian/flora/pkg/leaderelection/resourcelock"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Init(log.NewOptions())
+	os.Exit(m.Run())
+}
+
+// fakeStore holds a single record shared by several fakeLocks.
+type fakeStore struct {
+	lock    sync.Mutex
+	record  *rl.LeaderElectionRecord
+	version int
+}
+
+// fakeLock implements rl.Interface with the same optimistic concurrency as a
+// Lease stored in the apiserver.
+type fakeLock struct {
+	store    *fakeStore
+	identity string
+	version  int
+}
+
+func (f *fakeLock) Get(ctx context.Context) (*rl.LeaderElectionRecord, []byte, error) {
+	f.store.lock.Lock()
+	defer f.store.lock.Unlock()
+
+	if f.store.record == nil {
+		return nil, nil, apierrors.NewNotFound(v1.Resource("leases"), "test")
+	}
+
+	f.version = f.store.version
+	record := *f.store.record
+	raw, _ := json.Marshal(record)
+
+	return &record, raw, nil
+}
+
+func (f *fakeLock) Create(ctx context.Context, ler rl.LeaderElectionRecord) error {
+	f.store.lock.Lock()
+	defer f.store.lock.Unlock()
+
+	if f.store.record != nil {
+		return apierrors.NewAlreadyExists(v1.Resource("leases"), "test")
+	}
+
+	f.store.record = &ler
+	f.store.version++
+	f.version = f.store.version
+
+	return nil
+}
+
+func (f *fakeLock) Update(ctx context.Context, ler rl.LeaderElectionRecord) error {
+	f.store.lock.Lock()
+	defer f.store.lock.Unlock()
+
+	if f.version != f.store.version {
+		return apierrors.NewConflict(v1.Resource("leases"), "test", nil)
+	}
+
+	f.store.record = &ler
+	f.store.version++
+	f.version = f.store.version
+
+	return nil
+}
+
+func (f *fakeLock) Identity() string { return f.identity }
+func (f *fakeLock) Describe() string { return "leases/test" }
+
+func newElector(t *testing.T, store *fakeStore, identity string, started chan<- string, stopped chan<- string) *LeaderElector {
+	t.Helper()
+
+	le, err := NewLeaderElector(LeaderElectionConfig{
+		Lock:            &fakeLock{store: store, identity: identity},
+		LeaseDuration:   time.Second,
+		RenewDeadline:   500 * time.Millisecond,
+		RetryPeriod:     100 * time.Millisecond,
+		ReleaseOnCancel: true,
+		Callbacks: LeaderCallbacks{
+			OnStartedLeading: func(context.Context) { started <- identity },
+			OnStoppedLeading: func() { stopped <- identity },
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLeaderElector() error = %v", err)
+	}
+
+	return le
+}
+
+func TestLeaderElection(t *testing.T) {
+	store := &fakeStore{}
+	started := make(chan string, 2)
+	stopped := make(chan string, 2)
+
+	first := newElector(t, store, "first", started, stopped)
+	second := newElector(t, store, "second", started, stopped)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+
+	defer cancelSecond()
+
+	go first.Run(firstCtx)
+
+	if leader := <-started; leader != "first" {
+		t.Fatalf("leader = %q, want first", leader)
+	}
+
+	go second.Run(secondCtx)
+
+	select {
+	case leader := <-started:
+		t.Fatalf("%q started leading while the lease was held", leader)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if second.IsLeader() || second.GetLeader() != "first" {
+		t.Errorf("second observes leader %q, want first", second.GetLeader())
+	}
+
+	// Releasing on cancel lets the other candidate take over without waiting
+	// for the lease to expire.
+	cancelFirst()
+
+	if identity := <-stopped; identity != "first" {
+		t.Fatalf("stopped = %q, want first", identity)
+	}
+
+	select {
+	case leader := <-started:
+		if leader != "second" {
+			t.Fatalf("leader = %q, want second", leader)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second never acquired the released lease")
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if transitions := store.record.LeaderTransitions; transitions != 1 {
+		t.Errorf("LeaderTransitions = %d, want 1", transitions)
+	}
+}
+
+func TestNewLeaderElectorValidation(t *testing.T) {
+	callbacks := LeaderCallbacks{
+		OnStartedLeading: func(context.Context) {},
+		OnStoppedLeading: func() {},
+	}
+	lock := &fakeLock{store: &fakeStore{}, identity: "test"}
+
+	tests := []struct {
+		name   string
+		config LeaderElectionConfig
+	}{
+		{
+			name: "lease duration not greater than renew deadline",
+			config: LeaderElectionConfig{
+				Lock: lock, LeaseDuration: time.Second, RenewDeadline: time.Second, RetryPeriod: 100 * time.Millisecond,
+				Callbacks: callbacks,
+			},
+		},
+		{
+			name: "renew deadline too short for retry period",
+			config: LeaderElectionConfig{
+				Lock: lock, LeaseDuration: 2 * time.Second, RenewDeadline: time.Second, RetryPeriod: time.Second,
+				Callbacks: callbacks,
+			},
+		},
+		{
+			name: "missing callbacks",
+			config: LeaderElectionConfig{
+				Lock: lock, LeaseDuration: 2 * time.Second, RenewDeadline: time.Second, RetryPeriod: 100 * time.Millisecond,
+			},
+		},
+		{
+			name: "missing lock",
+			config: LeaderElectionConfig{
+				LeaseDuration: 2 * time.Second, RenewDeadline: time.Second, RetryPeriod: 100 * time.Millisecond,
+				Callbacks: callbacks,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLeaderElector(tt.config); err == nil {
+				t.Error("NewLeaderElector() error = nil, want an error")
+			}
+		})
+	}
+}
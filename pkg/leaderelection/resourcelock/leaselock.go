@@ -0,0 +1,131 @@
+package resourcelock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/rest"
+)
+
+// LeaseLock is a lock stored in a Lease resource of flora-apiserver.
+type LeaseLock struct {
+	// LeaseName is the name of the Lease object.
+	LeaseName  string
+	Client     rest.Interface
+	LockConfig ResourceLockConfig
+	lease      *v1.Lease
+}
+
+var _ Interface = &LeaseLock{}
+
+// New returns a LeaseLock named name that talks to the apiserver through client.
+func New(name string, client rest.Interface, rlc ResourceLockConfig) *LeaseLock {
+	return &LeaseLock{
+		LeaseName:  name,
+		Client:     client,
+		LockConfig: rlc,
+	}
+}
+
+// Get returns the election record from a Lease spec.
+func (ll *LeaseLock) Get(ctx context.Context) (*LeaderElectionRecord, []byte, error) {
+	lease := &v1.Lease{}
+	if err := ll.Client.Get().Resource("leases").Name(ll.LeaseName).Do(ctx).Into(lease); err != nil {
+		return nil, nil, err
+	}
+
+	ll.lease = lease
+	record := LeaseSpecToLeaderElectionRecord(&lease.Spec)
+
+	recordByte, err := json.Marshal(*record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, recordByte, nil
+}
+
+// Create attempts to create a Lease.
+func (ll *LeaseLock) Create(ctx context.Context, ler LeaderElectionRecord) error {
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: ll.LeaseName},
+		Spec:       LeaderElectionRecordToLeaseSpec(&ler),
+	}
+
+	created := &v1.Lease{}
+	if err := ll.Client.Post().Resource("leases").Body(lease).Do(ctx).Into(created); err != nil {
+		return err
+	}
+
+	ll.lease = created
+
+	return nil
+}
+
+// Update will update an existing Lease spec. The update is rejected by the
+// apiserver if the lease changed since it was last read.
+func (ll *LeaseLock) Update(ctx context.Context, ler LeaderElectionRecord) error {
+	if ll.lease == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+
+	lease := *ll.lease
+	lease.Spec = LeaderElectionRecordToLeaseSpec(&ler)
+
+	updated := &v1.Lease{}
+	if err := ll.Client.Put().Resource("leases").Name(ll.LeaseName).Body(&lease).Do(ctx).Into(updated); err != nil {
+		return err
+	}
+
+	ll.lease = updated
+
+	return nil
+}
+
+// Describe is used to convert details on current resource lock
+// into a string.
+func (ll *LeaseLock) Describe() string {
+	return "leases/" + ll.LeaseName
+}
+
+// Identity returns the Identity of the lock.
+func (ll *LeaseLock) Identity() string {
+	return ll.LockConfig.Identity
+}
+
+// LeaseSpecToLeaderElectionRecord converts a Lease spec into an election record.
+func LeaseSpecToLeaderElectionRecord(spec *v1.LeaseSpec) *LeaderElectionRecord {
+	r := &LeaderElectionRecord{
+		HolderIdentity:       spec.HolderIdentity,
+		LeaseDurationSeconds: int(spec.LeaseDurationSeconds),
+		LeaderTransitions:    int(spec.LeaseTransitions),
+	}
+
+	if spec.AcquireTime != nil {
+		r.AcquireTime = *spec.AcquireTime
+	}
+
+	if spec.RenewTime != nil {
+		r.RenewTime = *spec.RenewTime
+	}
+
+	return r
+}
+
+// LeaderElectionRecordToLeaseSpec converts an election record into a Lease spec.
+func LeaderElectionRecordToLeaseSpec(ler *LeaderElectionRecord) v1.LeaseSpec {
+	acquireTime := ler.AcquireTime.Truncate(time.Microsecond)
+	renewTime := ler.RenewTime.Truncate(time.Microsecond)
+
+	return v1.LeaseSpec{
+		HolderIdentity:       ler.HolderIdentity,
+		LeaseDurationSeconds: int32(ler.LeaseDurationSeconds),
+		AcquireTime:          &acquireTime,
+		RenewTime:            &renewTime,
+		LeaseTransitions:     int32(ler.LeaderTransitions),
+	}
+}
@@ -1,6 +1,11 @@
 package log
 
-import "context"
+import (
+	"context"
+
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+	"github.com/hanzhuoxian/flora/pkg/trace"
+)
 
 type key int
 
@@ -8,18 +13,139 @@ const (
 	logContextKey key = iota
 )
 
+// contextLogger is the logger stored in a context: logger is base with the
+// IDs of that context.
+type contextLogger struct {
+	base   Logger
+	logger Logger
+}
+
+// contextIDs are the request_id carried by a context and the trace_id and
+// span_id of its current span.
+type contextIDs struct {
+	requestID string
+	traceID   string
+	spanID    string
+}
+
+// WithContext returns a copy of ctx holding the logger, see NewContext.
 func (z *zapLogger) WithContext(ctx context.Context) context.Context {
-	return context.WithValue(ctx, logContextKey, z)
+	return NewContext(ctx, z)
 }
 
+// NewContext returns a copy of ctx holding l. The loggers returned by
+// FromContext add the request_id and the trace_id and span_id of the current
+// span to l, so l must not carry them.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, logContextKey, &contextLogger{base: l, logger: withIDs(l, idsFrom(ctx))})
+}
+
+// FromContext returns the logger stored in ctx by NewContext or WithContext,
+// or a logger named Unknown-Context, with the request_id carried by ctx and
+// the trace_id and span_id of its current span. A span started after the
+// logger was stored has its own IDs logged.
 func FromContext(ctx context.Context) Logger {
+	ids := idsFrom(ctx)
+
+	if ctx != nil {
+		if cl, ok := ctx.Value(logContextKey).(*contextLogger); ok {
+			// The stored logger is reused if it carries the IDs, e.g. unless
+			// a span started since. Its fields can't be replaced, the IDs are
+			// added to the base logger instead.
+			if kvs, _ := missingIDs(cl.logger, ids); len(kvs) == 0 {
+				return cl.logger
+			}
+
+			return withIDs(cl.base, ids)
+		}
+	}
+
+	return withIDs(WithName("Unknown-Context"), ids)
+}
+
+// idsFrom returns the IDs of ctx.
+func idsFrom(ctx context.Context) contextIDs {
+	var ids contextIDs
+
 	if ctx == nil {
-		return WithName("Unknown-Context")
+		return ids
+	}
+
+	if id, ok := requestid.FromContext(ctx); ok {
+		ids.requestID = id
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		ids.traceID, ids.spanID = sc.TraceID.String(), sc.SpanID.String()
+	}
+
+	return ids
+}
+
+// withIDs returns l with the fields of the IDs set in ids it does not carry
+// yet, and records them.
+func withIDs(l Logger, ids contextIDs) Logger {
+	kvs, carried := missingIDs(l, ids)
+	if len(kvs) == 0 {
+		return l
 	}
-	logger := ctx.Value(logContextKey)
-	if logger != nil {
-		return logger.(Logger)
+
+	switch l := l.WithValues(kvs...).(type) {
+	case *zapLogger:
+		l.ids = carried
+		return l
+	case *slogLogger:
+		l.ids = carried
+		return l
+	default:
+		return l
 	}
+}
+
+// missingIDs returns the IDs set in ids that l does not carry as key-value
+// pairs, and the IDs l carries once they are added.
+func missingIDs(l Logger, ids contextIDs) ([]interface{}, contextIDs) {
+	var carried contextIDs
+
+	switch l := l.(type) {
+	case *zapLogger:
+		carried = l.ids
+	case *slogLogger:
+		carried = l.ids
+	}
+
+	var kvs []interface{}
+
+	if len(ids.requestID) != 0 && ids.requestID != carried.requestID {
+		kvs = append(kvs, "request_id", ids.requestID)
+		carried.requestID = ids.requestID
+	}
+
+	if len(ids.spanID) != 0 && ids.spanID != carried.spanID {
+		kvs = append(kvs, "trace_id", ids.traceID, "span_id", ids.spanID)
+		carried.traceID, carried.spanID = ids.traceID, ids.spanID
+	}
+
+	return kvs, carried
+}
+
+// keysAndValues returns the IDs set in ids as key-value pairs.
+func (ids contextIDs) keysAndValues() []interface{} {
+	var kvs []interface{}
+
+	if len(ids.requestID) != 0 {
+		kvs = append(kvs, "request_id", ids.requestID)
+	}
+
+	if len(ids.traceID) != 0 {
+		kvs = append(kvs, "trace_id", ids.traceID, "span_id", ids.spanID)
+	}
+
+	return kvs
+}
 
-	return WithName("Unknown-Context")
+// contextValues returns the request_id carried by ctx and the trace_id and
+// span_id of its current span as key-value pairs.
+func contextValues(ctx context.Context) []interface{} {
+	return idsFrom(ctx).keysAndValues()
 }
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+	"github.com/hanzhuoxian/flora/pkg/trace"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(allLevels)
+	l := NewLogger(zap.New(core))
+
+	parent, err := trace.ParseTraceparent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := requestid.WithRequestID(context.Background(), "req-1")
+	ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	ctx = NewContext(ctx, l)
+
+	// The span starts after the logger was stored.
+	childCtx, child := trace.Start(ctx, "storage")
+	defer child.End()
+
+	FromContext(ctx).Info("parent")
+	FromContext(childCtx).Info("child")
+	// A logger stored again does not get the IDs it carries twice.
+	FromContext(NewContext(childCtx, FromContext(childCtx))).Info("stored again")
+
+	for _, tt := range []struct {
+		msg    string
+		spanID string
+	}{
+		{"parent", parent.SpanID.String()},
+		{"child", child.SpanContext().SpanID.String()},
+		{"stored again", child.SpanContext().SpanID.String()},
+	} {
+		entries := logs.FilterMessage(tt.msg).All()
+		if len(entries) != 1 {
+			t.Fatalf("%s: entries = %v, want one", tt.msg, entries)
+		}
+
+		fields := entries[0].ContextMap()
+		if len(entries[0].Context) != 3 || fields["request_id"] != "req-1" ||
+			fields["trace_id"] != parent.TraceID.String() || fields["span_id"] != tt.spanID {
+			t.Errorf("%s: fields = %v, want request_id, trace_id and span_id %s once", tt.msg, entries[0].Context, tt.spanID)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levels holds the level of a logger tree: a global level and overrides for
+// named loggers. An override applies to the logger with that name and its
+// descendants, e.g. "storage" also applies to "storage.bolt"; the longest
+// matching name wins.
+type levels struct {
+	global zap.AtomicLevel
+
+	lock      sync.RWMutex
+	overrides map[string]zapcore.Level
+	// hasOverrides lets the common case skip the lock.
+	hasOverrides atomic.Bool
+
+	// vmodule, if set, enables V levels above the level per file.
+	vmodule atomic.Pointer[vmodule]
+}
+
+func newLevels(l zapcore.Level) *levels {
+	return &levels{global: zap.NewAtomicLevelAt(l), overrides: map[string]zapcore.Level{}}
+}
+
+// levelFor returns the level of the logger named name.
+func (l *levels) levelFor(name string) zapcore.Level {
+	if !l.hasOverrides.Load() {
+		return l.global.Level()
+	}
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	for n := name; ; {
+		if lvl, ok := l.overrides[n]; ok {
+			return lvl
+		}
+
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			return l.global.Level()
+		}
+
+		n = n[:i]
+	}
+}
+
+// minLevel returns the lowest level enabled for any logger.
+func (l *levels) minLevel() zapcore.Level {
+	lvl := l.global.Level()
+	if !l.hasOverrides.Load() {
+		return lvl
+	}
+
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	for _, o := range l.overrides {
+		if o < lvl {
+			lvl = o
+		}
+	}
+
+	return lvl
+}
+
+func (l *levels) setOverride(name string, lvl zapcore.Level) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.overrides[name] = lvl
+	l.hasOverrides.Store(true)
+}
+
+func (l *levels) deleteOverride(name string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.overrides, name)
+	l.hasOverrides.Store(len(l.overrides) != 0)
+}
+
+func (l *levels) snapshot() map[string]zapcore.Level {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	out := make(map[string]zapcore.Level, len(l.overrides))
+	for name, lvl := range l.overrides {
+		out[name] = lvl
+	}
+
+	return out
+}
+
+// allLevels enables every entry, the filtering is done by levelFilterCore.
+const allLevels = zapcore.Level(math.MinInt8)
+
+// levelFilterCore drops the entries below the level of their logger.
+type levelFilterCore struct {
+	zapcore.Core
+
+	levels *levels
+}
+
+// Enabled returns whether any logger writes the entries at lvl, the core does
+// not know the name of the logger. Use enabled for a given logger.
+func (c *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.levels.minLevel()
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelFilterCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.levels.levelFor(entry.LoggerName) {
+		return ce
+	}
+
+	return c.Core.Check(entry, ce)
+}
+
+// enabled returns whether l writes the entries at lvl, according to the level
+// of its name rather than the lowest level of all the loggers.
+func enabled(l *zap.Logger, lvl zapcore.Level) bool {
+	if c, ok := l.Core().(*levelFilterCore); ok {
+		return lvl >= c.levels.levelFor(l.Name())
+	}
+
+	return l.Core().Enabled(lvl)
+}
+
+// globalLevels are the levels of the logger created by Init.
+var globalLevels = newLevels(zapcore.InfoLevel)
+
+// SetLevel changes the level of the global logger at runtime.
+func SetLevel(lvl Level) {
+	globalLevels.global.SetLevel(lvl)
+}
+
+// GetLevel returns the level of the global logger.
+func GetLevel() Level {
+	return globalLevels.global.Level()
+}
+
+// SetLoggerLevel overrides the level of the loggers named name and their
+// descendants, e.g. SetLoggerLevel("storage", DebugLevel).
+func SetLoggerLevel(name string, lvl Level) {
+	globalLevels.setOverride(name, lvl)
+}
+
+// ResetLoggerLevel removes the override of the loggers named name.
+func ResetLoggerLevel(name string) {
+	globalLevels.deleteOverride(name)
+}
+
+// GetLoggerLevels returns the overridden levels by logger name.
+func GetLoggerLevels() map[string]Level {
+	return globalLevels.snapshot()
+}
+
+// levelPayload is the body of the requests and responses of LevelHandler.
+type levelPayload struct {
+	Level   string            `json:"level,omitempty"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// LevelHandler returns an HTTP handler to view and change the levels:
+//
+//	GET                          returns {"level":"info","loggers":{"storage":"debug"}}
+//	PUT {"level":"debug"}        sets the global level
+//	PUT ?logger=storage {"level":"debug"}
+//	                             overrides the level of the storage loggers
+//	DELETE ?logger=storage       removes the override
+//
+// It does not authorize the requests, it must only be served to the
+// administrators.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("logger")
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelPayload
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+				writeLevelError(w, http.StatusBadRequest, fmt.Errorf("request body must be {\"level\":\"<level>\"}: %w", err))
+				return
+			}
+
+			var lvl zapcore.Level
+			if err := lvl.UnmarshalText([]byte(req.Level)); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			if len(name) == 0 {
+				SetLevel(lvl)
+			} else {
+				SetLoggerLevel(name, lvl)
+			}
+		case http.MethodDelete:
+			if len(name) == 0 {
+				writeLevelError(w, http.StatusBadRequest, fmt.Errorf("the logger query parameter is required"))
+				return
+			}
+
+			ResetLoggerLevel(name)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+
+			return
+		}
+
+		resp := levelPayload{Level: GetLevel().String(), Loggers: map[string]string{}}
+
+		overrides := GetLoggerLevels()
+		names := make([]string, 0, len(overrides))
+
+		for n := range overrides {
+			names = append(names, n)
+		}
+
+		sort.Strings(names)
+
+		for _, n := range names {
+			resp.Loggers[n] = overrides[n].String()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,105 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevelFilterCore(t *testing.T) {
+	lv := newLevels(zapcore.InfoLevel)
+	lv.setOverride("storage", zapcore.DebugLevel)
+	lv.setOverride("storage.bolt", zapcore.ErrorLevel)
+
+	core, logs := observer.New(allLevels)
+	l := zap.New(&levelFilterCore{Core: core, levels: lv})
+
+	l.Debug("root debug")
+	l.Info("root info")
+	l.Named("storage").Debug("storage debug")
+	l.Named("storage").Named("memory").Debug("storage.memory debug")
+	l.Named("storage").Named("bolt").Warn("storage.bolt warn")
+	l.Named("storage").Named("bolt").Error("storage.bolt error")
+	l.Named("storagex").Debug("storagex debug")
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+
+	want := "root info,storage debug,storage.memory debug,storage.bolt error"
+	if strings.Join(got, ",") != want {
+		t.Errorf("logged %v, want %v", got, want)
+	}
+
+	lv.deleteOverride("storage")
+	lv.deleteOverride("storage.bolt")
+
+	if lv.minLevel() != zapcore.InfoLevel {
+		t.Errorf("minLevel() = %v, want info", lv.minLevel())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLevel(GetLevel())
+	defer ResetLoggerLevel("storage")
+
+	handler := LevelHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		body   string
+		code   int
+		want   levelPayload
+	}{
+		{"set global", http.MethodPut, "", `{"level":"warn"}`, http.StatusOK, levelPayload{Level: "warn"}},
+		{"set logger", http.MethodPut, "?logger=storage", `{"level":"debug"}`, http.StatusOK,
+			levelPayload{Level: "warn", Loggers: map[string]string{"storage": "debug"}}},
+		{"get", http.MethodGet, "", "", http.StatusOK,
+			levelPayload{Level: "warn", Loggers: map[string]string{"storage": "debug"}}},
+		{"reset logger", http.MethodDelete, "?logger=storage", "", http.StatusOK, levelPayload{Level: "warn"}},
+		{"invalid level", http.MethodPut, "", `{"level":"loud"}`, http.StatusBadRequest, levelPayload{}},
+		{"delete without logger", http.MethodDelete, "", "", http.StatusBadRequest, levelPayload{}},
+		{"invalid method", http.MethodPost, "", "", http.StatusMethodNotAllowed, levelPayload{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/debug/loglevel"+tt.query, bytes.NewBufferString(tt.body))
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got levelPayload
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Level != tt.want.Level || len(got.Loggers) != len(tt.want.Loggers) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			for n, l := range tt.want.Loggers {
+				if got.Loggers[n] != l {
+					t.Errorf("logger %s level = %s, want %s", n, got.Loggers[n], l)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package log
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -54,7 +56,7 @@ type infoLogger struct {
 }
 
 func (i *infoLogger) Enabled() bool {
-	return true
+	return enabled(i.log, i.level)
 }
 
 func (i *infoLogger) Info(msg string, keysAndValues ...interface{}) {
@@ -64,11 +66,135 @@ func (i *infoLogger) Info(msg string, keysAndValues ...interface{}) {
 }
 
 func (i *infoLogger) Infof(format string, args ...interface{}) {
-	i.log.Sugar().Infof(format, args...)
+	if checkEntry := i.log.Check(i.level, fmt.Sprintf(format, args...)); checkEntry != nil {
+		checkEntry.Write()
+	}
 }
 
 func Init(opts *Options) {
+	var zapLevel zapcore.Level
+	if err := zapLevel.UnmarshalText([]byte(opts.Level)); err != nil {
+		zapLevel = zap.InfoLevel
+	}
+
+	// The verbosity enables V levels below log.level.
+	if v := verbosityLevel(opts.Verbosity); v < zapLevel {
+		zapLevel = v
+	}
+
+	SetLevel(zapLevel)
+
+	vm, err := parseVModule(opts.VModule)
+	if err != nil {
+		panic(err)
+	}
+
+	globalLevels.vmodule.Store(vm)
+
+	l, closeOutputs, err := build(opts, true, globalLevels)
+	if err != nil {
+		panic(err)
+	}
+
+	prevLogger, prevClose := logger, closeSinks
+
 	options = opts
+	logger = &zapLogger{
+		zapLogger: l,
+		infoLogger: infoLogger{
+			log:   l,
+			level: zap.InfoLevel,
+		},
+	}
+	closeSinks = closeOutputs
+
+	// The outputs of the replaced logger are not written anymore.
+	prevLogger.Flush()
+	prevClose()
+}
+
+// New creates a logger from opts without replacing the global logger. It is
+// meant for dedicated outputs such as audit logs, so its entries are never
+// sampled. The returned function closes the outputs of the logger, which must
+// not be used afterwards.
+func New(opts *Options) (Logger, func(), error) {
+	var zapLevel zapcore.Level
+	if err := zapLevel.UnmarshalText([]byte(opts.Level)); err != nil {
+		zapLevel = zap.InfoLevel
+	}
+
+	l, closeOutputs, err := build(opts, false, newLevels(zapLevel))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewLogger(l), closeOutputs, nil
+}
+
+// build creates a zap logger from opts whose levels are controlled by lv.
+// The entries are sampled if sampling is set and opts enables it. The
+// returned function closes the outputs and the error outputs of the logger.
+func build(opts *Options, sampling bool, lv *levels) (*zap.Logger, func(), error) {
+	sinks := opts.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkOptions{{Format: opts.Format, EnableColor: opts.EnableColor}}
+	}
+
+	var (
+		cores  []zapcore.Core
+		closes []func()
+	)
+
+	closeAll := func() {
+		for _, c := range closes {
+			c()
+		}
+	}
+
+	for _, sink := range sinks {
+		paths := opts.OutputPaths
+		if len(sink.Path) != 0 {
+			paths = []string{sink.Path}
+		}
+
+		core, closeSink, err := newSinkCore(opts, sink, paths)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+
+		cores = append(cores, core)
+		closes = append(closes, closeSink)
+	}
+
+	errSink, closeErrSink, err := openSinks(opts.ErrorOutputPaths, opts.Rotation)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	closes = append(closes, closeErrSink)
+
+	core := zapcore.NewTee(cores...)
+	if s := opts.Sampling; sampling && s.Enabled {
+		core = zapcore.NewSamplerWithOptions(core, s.Tick, s.Initial, s.Thereafter)
+	}
+
+	zapOpts := []zap.Option{
+		zap.ErrorOutput(errSink),
+		zap.AddStacktrace(zapcore.PanicLevel),
+		zap.AddCallerSkip(1),
+	}
+	if opts.EnableCaller {
+		zapOpts = append(zapOpts, zap.AddCaller())
+	}
+
+	return zap.New(&levelFilterCore{Core: core, levels: lv}, zapOpts...), closeAll, nil
+}
+
+// newSinkCore returns the core writing the entries at or above the level of
+// sink to paths in its format.
+func newSinkCore(opts *Options, sink SinkOptions, paths []string) (zapcore.Core, func(), error) {
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
@@ -83,43 +209,96 @@ func Init(opts *Options) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	if opts.EnableColor {
+	if sink.EnableColor {
 		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(opts.Level)); err != nil {
-		zapLevel = zap.InfoLevel
+	encoderConfig.EncodeLevel = levelEncoder(encoderConfig.EncodeLevel)
+
+	format := sink.Format
+	if len(format) == 0 {
+		format = opts.Format
 	}
 
-	loggerConfig := &zap.Config{
-		Level:             zap.NewAtomicLevelAt(zapLevel),
-		Development:       false,
-		DisableCaller:     !opts.EnableCaller,
-		DisableStacktrace: false,
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		Encoding:         opts.Format,
-		EncoderConfig:    encoderConfig,
-		OutputPaths:      opts.OutputPaths,
-		ErrorOutputPaths: opts.ErrorOutputPaths,
+	var encoder zapcore.Encoder
+	if strings.ToLower(format) == jsonFormat {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	}
 
-	var err error
-	l, err := loggerConfig.Build(zap.AddStacktrace(zapcore.PanicLevel), zap.AddCallerSkip(1))
+	// The level of the sink filters further the entries enabled by the
+	// level of the logger.
+	var enabler zapcore.LevelEnabler = allLevels
+	if len(sink.Level) != 0 {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(sink.Level)); err != nil {
+			return nil, nil, err
+		}
+
+		enabler = lvl
+	}
+
+	out, closeOut, err := openSinks(paths, opts.Rotation)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 
-	logger = &zapLogger{
-		zapLogger: l,
-		infoLogger: infoLogger{
-			log:   l,
-			level: zap.InfoLevel,
-		},
+	core := zapcore.NewCore(encoder, out, enabler)
+	if r := newRedactor(opts.RedactKeys); r != nil {
+		core = &redactCore{Core: core, redactor: r}
 	}
+
+	return core, closeOut, nil
+}
+
+// openSinks opens the outputs in paths. Files rotate when rotation is
+// enabled, other paths such as stdout are opened by zap.Open.
+func openSinks(paths []string, rotation RotationOptions) (zapcore.WriteSyncer, func(), error) {
+	if !rotation.Enabled() {
+		return zap.Open(paths...)
+	}
+
+	var (
+		syncers []zapcore.WriteSyncer
+		files   []*rotatingFile
+		others  []string
+	)
+
+	for _, path := range paths {
+		if path == "stdout" || path == "stderr" || strings.Contains(path, "://") {
+			others = append(others, path)
+			continue
+		}
+
+		f := newRotatingFile(path, rotation)
+		files = append(files, f)
+		syncers = append(syncers, zapcore.AddSync(f))
+	}
+
+	closeFiles := func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}
+
+	closeAll := closeFiles
+
+	if len(others) != 0 {
+		sink, closeOthers, err := zap.Open(others...)
+		if err != nil {
+			closeFiles()
+			return nil, nil, err
+		}
+
+		syncers = append(syncers, sink)
+		closeAll = func() {
+			closeFiles()
+			closeOthers()
+		}
+	}
+
+	return zap.CombineWriteSyncers(syncers...), closeAll, nil
 }
 
 func handleFields(l *zap.Logger, args []interface{}, additional ...zap.Field) []zap.Field {
@@ -160,13 +339,43 @@ func handleFields(l *zap.Logger, args []interface{}, additional ...zap.Field) []
 type zapLogger struct {
 	zapLogger *zap.Logger
 	infoLogger
+
+	// ids are the IDs of a context logged by the fields of the logger.
+	ids contextIDs
 }
 
 var (
-	logger  *zapLogger
-	options *Options
+	logger  = newDefaultLogger()
+	options = NewOptions()
+
+	// closeSinks closes the outputs of the logger set by Init. The default
+	// logger writes to the standard streams, which are not closed.
+	closeSinks = func() {}
 )
 
+// newDefaultLogger returns the global logger used until Init is called, so
+// that logging before Init does not panic.
+func newDefaultLogger() *zapLogger {
+	l, _, err := build(NewOptions(), true, globalLevels)
+	if err != nil {
+		l = zap.NewNop()
+	}
+
+	return NewLogger(l).(*zapLogger)
+}
+
+// ReplaceGlobals replaces the global logger with l and returns a function
+// restoring the previous one. It is meant for tests, see pkg/log/logtest,
+// and must not be called concurrently with logging.
+func ReplaceGlobals(l *zap.Logger) func() {
+	prevLogger, prevOptions := logger, options
+	logger = NewLogger(l).(*zapLogger)
+
+	return func() {
+		logger, options = prevLogger, prevOptions
+	}
+}
+
 func StdErrLogger() *log.Logger {
 	if logger == nil {
 		return nil
@@ -201,21 +410,14 @@ func (z *zapLogger) Errorf(format string, args ...interface{}) {
 	z.log.Sugar().Errorf(format, args...)
 }
 
-func V(level int) InfoLogger { return logger.V(level) }
+// V returns an InfoLogger writing at the verbosity level, which is enabled
+// when level is at most the verbosity set by -v or by -vmodule for the file
+// of the caller. V(0) logs at info level and V(1) at debug level.
+func V(level int) InfoLogger { return logger.v(level, 1) }
 
+// V returns an InfoLogger writing at the verbosity level.
 func (z *zapLogger) V(level int) InfoLogger {
-	if level < 0 || level > 1 {
-		panic("valid log level is [0, 1]")
-	}
-	l := zapcore.Level(-1 * level)
-	if z.zapLogger.Core().Enabled(l) {
-		return &infoLogger{
-			log:   z.zapLogger,
-			level: l,
-		}
-	}
-
-	return disabledInfoLogger
+	return z.v(level, 1)
 }
 
 func (z *zapLogger) Write(p []byte) (n int, err error) {
@@ -225,9 +427,10 @@ func (z *zapLogger) Write(p []byte) (n int, err error) {
 }
 
 func (z *zapLogger) WithValues(keysAndValues ...interface{}) Logger {
-	newLogger := z.zapLogger.With(handleFields(z.zapLogger, keysAndValues)...)
+	newLogger := NewLogger(z.zapLogger.With(handleFields(z.zapLogger, keysAndValues)...)).(*zapLogger)
+	newLogger.ids = z.ids
 
-	return NewLogger(newLogger)
+	return newLogger
 }
 
 func WithValues(keysAndValues ...interface{}) Logger {
@@ -235,9 +438,10 @@ func WithValues(keysAndValues ...interface{}) Logger {
 }
 
 func (l *zapLogger) WithName(name string) Logger {
-	newLogger := l.zapLogger.Named(name)
+	newLogger := NewLogger(l.zapLogger.Named(name)).(*zapLogger)
+	newLogger.ids = l.ids
 
-	return NewLogger(newLogger)
+	return newLogger
 }
 
 func WithName(name string) Logger {
@@ -266,17 +470,10 @@ func NewLogger(l *zap.Logger) Logger {
 	}
 }
 
-// message at the specified level is enabled.
+// CheckIntLevel returns whether the messages at the verbosity level are
+// enabled, as V(level).Enabled() does.
 func CheckIntLevel(level int32) bool {
-	var lvl zapcore.Level
-	if level < 5 {
-		lvl = zapcore.InfoLevel
-	} else {
-		lvl = zapcore.DebugLevel
-	}
-	checkEntry := logger.zapLogger.Check(lvl, "")
-
-	return checkEntry != nil
+	return logger.v(int(level), 1).Enabled()
 }
 
 // Debug method output debug level log.
@@ -0,0 +1,24 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+// TestWithoutInit checks that the package functions do not panic before Init.
+func TestWithoutInit(t *testing.T) {
+	restore := ReplaceGlobals(newDefaultLogger().zapLogger)
+	defer restore()
+
+	Debugw("debug", "k", "v")
+	Infof("info %d", 1)
+	V(4).Info("verbose")
+	WithName("storage").WithValues("k", "v").Info("named")
+	FromContext(context.Background()).Info("from context")
+	_ = CheckIntLevel(2)
+	Flush()
+
+	if GetOptions() == nil {
+		t.Errorf("GetOptions() = nil before Init")
+	}
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+)
+
+// NewLogr returns a logr.Logger writing to l, for the libraries logging
+// through logr. logr's V levels are the V levels of l.
+func NewLogr(l Logger) logr.Logger {
+	return logr.New(NewLogSink(l))
+}
+
+// NewLogSink returns a logr.LogSink writing to l.
+func NewLogSink(l Logger) logr.LogSink {
+	return &logSink{logger: l}
+}
+
+// logSink is a logr.LogSink writing to a Logger.
+type logSink struct {
+	logger Logger
+	// depth is the number of frames between the caller of logr and the
+	// methods of logSink.
+	depth int
+}
+
+var (
+	_ logr.LogSink          = &logSink{}
+	_ logr.CallDepthLogSink = &logSink{}
+)
+
+func (s *logSink) Init(info logr.RuntimeInfo) {
+	s.logger = addCallerSkip(s.logger, info.CallDepth+1)
+	s.depth = info.CallDepth + 1
+}
+
+func (s *logSink) Enabled(level int) bool {
+	return s.v(level).Enabled()
+}
+
+func (s *logSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.v(level).Info(msg, keysAndValues...)
+}
+
+func (s *logSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.logger.Error(err, msg, keysAndValues...)
+}
+
+func (s *logSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logSink{logger: s.logger.WithValues(keysAndValues...), depth: s.depth}
+}
+
+func (s *logSink) WithName(name string) logr.LogSink {
+	return &logSink{logger: s.logger.WithName(name), depth: s.depth}
+}
+
+func (s *logSink) WithCallDepth(depth int) logr.LogSink {
+	return &logSink{logger: addCallerSkip(s.logger, depth), depth: s.depth + depth}
+}
+
+// v returns the InfoLogger of the level, vmodule applying to the caller of
+// logr.
+func (s *logSink) v(level int) InfoLogger {
+	if z, ok := s.logger.(*zapLogger); ok {
+		return z.v(level, s.depth+1)
+	}
+
+	return s.logger.V(level)
+}
+
+// addCallerSkip returns l reporting the caller skip frames higher.
+func addCallerSkip(l Logger, skip int) Logger {
+	if z, ok := l.(*zapLogger); ok && skip != 0 {
+		return NewLogger(z.zapLogger.WithOptions(zap.AddCallerSkip(skip)))
+	}
+
+	return l
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogr(t *testing.T) {
+	vm, err := parseVModule("logr_test=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lv := newLevels(zapcore.InfoLevel)
+	lv.vmodule.Store(vm)
+
+	core, logs := observer.New(allLevels)
+	zl := zap.New(&levelFilterCore{Core: core, levels: lv}, zap.AddCaller(), zap.AddCallerSkip(1))
+	l := NewLogr(NewLogger(zl)).WithName("controller").WithValues("name", "lease-a")
+
+	l.Info("synced")
+	l.V(3).Info("enabled by vmodule")
+	l.V(4).Info("disabled")
+	l.Error(errors.New("boom"), "failed", "attempt", 2)
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	want := []struct {
+		level zapcore.Level
+		msg   string
+	}{{zapcore.InfoLevel, "synced"}, {verbosityLevel(3), "enabled by vmodule"}, {zapcore.ErrorLevel, "failed"}}
+
+	for i, w := range want {
+		e := entries[i]
+		if e.Level != w.level || e.Message != w.msg || e.LoggerName != "controller" {
+			t.Errorf("entry %d = %v %q %q, want %v %q controller", i, e.Level, e.Message, e.LoggerName, w.level, w.msg)
+		}
+
+		if e.ContextMap()["name"] != "lease-a" {
+			t.Errorf("entry %d fields = %v, want name", i, e.ContextMap())
+		}
+
+		if !strings.HasSuffix(e.Caller.File, "logr_test.go") {
+			t.Errorf("entry %d caller = %s, want logr_test.go", i, e.Caller.File)
+		}
+	}
+
+	if entries[2].ContextMap()["error"] != "boom" {
+		t.Errorf("error = %v, want boom", entries[2].ContextMap()["error"])
+	}
+}
@@ -0,0 +1,166 @@
+// Package logtest provides a logger capturing its entries, to assert on the
+// logs written by the code under test.
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// Logs holds the entries captured by a test logger with their fields.
+type Logs struct {
+	*observer.ObservedLogs
+}
+
+// New returns a logger capturing the entries at or above level in Logs.
+// V(n) writes at level -n, e.g. New(-4) captures up to V(4).
+func New(level log.Level) (log.Logger, *Logs) {
+	core, logs := observer.New(level)
+
+	return log.NewLogger(newZapLogger(core)), &Logs{ObservedLogs: logs}
+}
+
+// Install replaces the global logger of pkg/log with a logger capturing the
+// entries at or above level until the end of the test. Tests installing a
+// logger must not run in parallel.
+func Install(t testing.TB, level log.Level) *Logs {
+	t.Helper()
+
+	core, logs := observer.New(level)
+	t.Cleanup(log.ReplaceGlobals(newZapLogger(core)))
+
+	return &Logs{ObservedLogs: logs}
+}
+
+func newZapLogger(core zapcore.Core) *zap.Logger {
+	// The logger of pkg/log skips its own frame when reporting the caller.
+	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+}
+
+// Messages returns the messages of the captured entries.
+func (l *Logs) Messages() []string {
+	entries := l.All()
+
+	msgs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		msgs = append(msgs, e.Message)
+	}
+
+	return msgs
+}
+
+// ContainsMessage returns whether an entry has a message containing msg.
+func (l *Logs) ContainsMessage(msg string) bool {
+	return l.FilterMessageSnippet(msg).Len() != 0
+}
+
+// HasField returns whether an entry has the field key with value. Values are
+// compared as written by the logger, e.g. ints as int64 and errors as their
+// message.
+func (l *Logs) HasField(key string, value interface{}) bool {
+	for _, e := range l.All() {
+		if v, ok := e.ContextMap()[key]; ok && equal(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AssertContainsMessage fails the test unless an entry has a message
+// containing msg.
+func (l *Logs) AssertContainsMessage(t testing.TB, msg string) {
+	t.Helper()
+
+	if !l.ContainsMessage(msg) {
+		t.Errorf("no log message contains %q, logged:\n%s", msg, l)
+	}
+}
+
+// AssertNotContainsMessage fails the test if an entry has a message
+// containing msg.
+func (l *Logs) AssertNotContainsMessage(t testing.TB, msg string) {
+	t.Helper()
+
+	if l.ContainsMessage(msg) {
+		t.Errorf("a log message contains %q, logged:\n%s", msg, l)
+	}
+}
+
+// AssertHasField fails the test unless an entry has the field key with
+// value.
+func (l *Logs) AssertHasField(t testing.TB, key string, value interface{}) {
+	t.Helper()
+
+	if !l.HasField(key, value) {
+		t.Errorf("no log entry has %s=%v, logged:\n%s", key, value, l)
+	}
+}
+
+// String returns the captured entries, one per line.
+func (l *Logs) String() string {
+	var b strings.Builder
+
+	for _, e := range l.All() {
+		fmt.Fprintf(&b, "%s\t%s", e.Level, e.Message)
+
+		if len(e.LoggerName) != 0 {
+			fmt.Fprintf(&b, "\tlogger=%s", e.LoggerName)
+		}
+
+		fields := e.ContextMap()
+
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Fprintf(&b, "\t%s=%v", k, fields[k])
+		}
+
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// equal compares a captured value with an expected one, converting the
+// expected value the way the logger does.
+func equal(got, want interface{}) bool {
+	if reflect.DeepEqual(got, want) {
+		return true
+	}
+
+	switch w := want.(type) {
+	case error:
+		want = w.Error()
+	case fmt.Stringer:
+		want = w.String()
+	}
+
+	rw := reflect.ValueOf(want)
+	rg := reflect.ValueOf(got)
+
+	switch {
+	case rw.CanInt() && rg.CanInt():
+		return rw.Int() == rg.Int()
+	case rw.CanUint() && rg.CanUint():
+		return rw.Uint() == rg.Uint()
+	case rw.CanFloat() && rg.CanFloat():
+		return rw.Float() == rg.Float()
+	}
+
+	return reflect.DeepEqual(got, want)
+}
@@ -0,0 +1,72 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hanzhuoxian/flora/pkg/log"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+)
+
+func TestInstall(t *testing.T) {
+	before := log.ZapLogger()
+
+	t.Run("install", func(t *testing.T) {
+		logs := Install(t, log.DebugLevel)
+
+		log.Infow("created lease", "name", "lease-a", "rv", 3)
+		log.WithName("storage").Error(errors.New("boom"), "write failed")
+		log.V(1).Infof("debug %d", 1)
+		log.V(2).Info("V(2) is not captured")
+		ctx := log.WithName("handler").WithContext(requestid.WithRequestID(context.Background(), "req-1"))
+		// A logger stored again does not get the fields of the context twice.
+		log.FromContext(log.NewContext(ctx, log.FromContext(ctx))).Info("handled")
+
+		logs.AssertContainsMessage(t, "created")
+		logs.AssertContainsMessage(t, "debug 1")
+		logs.AssertNotContainsMessage(t, "V(2)")
+		logs.AssertHasField(t, "name", "lease-a")
+		logs.AssertHasField(t, "rv", 3)
+		logs.AssertHasField(t, "error", errors.New("boom"))
+		logs.AssertHasField(t, "request_id", "req-1")
+
+		if fields := logs.FilterMessage("handled").All()[0].Context; len(fields) != 1 {
+			t.Errorf("fields = %v, want the request_id only", fields)
+		}
+
+		if logs.HasField("name", "lease-b") || logs.HasField("missing", "x") {
+			t.Errorf("HasField matched a field that was not logged")
+		}
+
+		if got := strings.Join(logs.Messages(), ","); got != "created lease,write failed,debug 1,handled" {
+			t.Errorf("Messages() = %s", got)
+		}
+
+		if e := logs.FilterMessage("write failed").All(); len(e) != 1 || e[0].LoggerName != "storage" {
+			t.Errorf("entries = %v, want one from the storage logger", e)
+		}
+
+		if e := logs.All()[0]; !strings.HasSuffix(e.Caller.File, "logtest_test.go") {
+			t.Errorf("caller = %s, want logtest_test.go", e.Caller.File)
+		}
+	})
+
+	if log.ZapLogger() != before {
+		t.Errorf("the global logger was not restored")
+	}
+}
+
+func TestNew(t *testing.T) {
+	l, logs := New(-4)
+
+	l.WithValues("controller", "lease").V(4).Info("sync", "count", 2)
+
+	logs.AssertHasField(t, "controller", "lease")
+	logs.AssertHasField(t, "count", 2)
+
+	if !strings.Contains(logs.String(), "sync\tcontroller=lease\tcount=2") {
+		t.Errorf("String() = %q", logs.String())
+	}
+}
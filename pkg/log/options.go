@@ -4,18 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"go.uber.org/zap/zapcore"
 )
 
 const (
-	flagLevel            = "log.level"
-	flagFormat           = "log.format"
-	flagEnableColor      = "log.enable-color"
-	flagEnableCaller     = "log.enable-caller"
-	flagOutputPaths      = "log.output-paths"
-	flagErrorOutputPaths = "log.error-output-paths"
+	flagLevel              = "log.level"
+	flagFormat             = "log.format"
+	flagEnableColor        = "log.enable-color"
+	flagEnableCaller       = "log.enable-caller"
+	flagOutputPaths        = "log.output-paths"
+	flagErrorOutputPaths   = "log.error-output-paths"
+	flagMaxSize            = "log.rotation.max-size"
+	flagInterval           = "log.rotation.interval"
+	flagMaxBackups         = "log.rotation.max-backups"
+	flagMaxAge             = "log.rotation.max-age"
+	flagCompress           = "log.rotation.compress"
+	flagSymlink            = "log.rotation.symlink"
+	flagRedactKeys         = "log.redact-keys"
+	flagVerbosity          = "log.verbosity"
+	flagVModule            = "log.vmodule"
+	flagSampling           = "log.sampling"
+	flagSamplingInitial    = "log.sampling.initial"
+	flagSamplingThereafter = "log.sampling.thereafter"
+	flagSamplingTick       = "log.sampling.tick"
+	flagSink               = "log.sink"
 
 	consoleFormat = "console"
 	jsonFormat    = "json"
@@ -29,6 +44,48 @@ type Options struct {
 	EnableCaller     bool     `json:"enable-caller"      mapstructure:"enable-caller"`
 	OutputPaths      []string `json:"output-paths"       mapstructure:"output-paths"`
 	ErrorOutputPaths []string `json:"error-output-paths" mapstructure:"error-output-paths"`
+	RedactKeys       []string `json:"redact-keys"        mapstructure:"redact-keys"`
+	Verbosity        int      `json:"verbosity"          mapstructure:"verbosity"`
+	VModule          string   `json:"vmodule"            mapstructure:"vmodule"`
+
+	Rotation RotationOptions `json:"rotation" mapstructure:"rotation"`
+	Sampling SamplingOptions `json:"sampling" mapstructure:"sampling"`
+	// Sinks replace OutputPaths, Format and EnableColor when set.
+	Sinks []SinkOptions `json:"sinks,omitempty" mapstructure:"sinks"`
+}
+
+// SamplingOptions contains the configuration for the sampling of the global
+// logger: every Tick, the first Initial entries with the same level and
+// message are written, then every Thereafter-th one. Dedicated loggers
+// created by New are never sampled.
+type SamplingOptions struct {
+	Enabled    bool          `json:"enabled"    mapstructure:"enabled"`
+	Initial    int           `json:"initial"    mapstructure:"initial"`
+	Thereafter int           `json:"thereafter" mapstructure:"thereafter"`
+	Tick       time.Duration `json:"tick"       mapstructure:"tick"`
+}
+
+// RotationOptions contains the configuration for the rotation of the log
+// files in OutputPaths and ErrorOutputPaths. Files rotate when they reach
+// MaxSize or every Interval, rotation is disabled when both are zero.
+type RotationOptions struct {
+	// MaxSize is the size in megabytes of a log file before it rotates.
+	MaxSize int `json:"max-size" mapstructure:"max-size"`
+	// Interval is the time between two rotations.
+	Interval time.Duration `json:"interval" mapstructure:"interval"`
+	// MaxBackups is the number of rotated files to keep, 0 keeps all.
+	MaxBackups int `json:"max-backups" mapstructure:"max-backups"`
+	// MaxAge is how long rotated files are kept, 0 keeps them forever.
+	MaxAge time.Duration `json:"max-age" mapstructure:"max-age"`
+	// Compress gzips the rotated files.
+	Compress bool `json:"compress" mapstructure:"compress"`
+	// Symlink makes the output path a symlink to the current log file.
+	Symlink bool `json:"symlink" mapstructure:"symlink"`
+}
+
+// Enabled returns whether the log files rotate.
+func (o *RotationOptions) Enabled() bool {
+	return o.MaxSize > 0 || o.Interval > 0
 }
 
 // NewOptions returns a new Options instance.
@@ -40,6 +97,16 @@ func NewOptions() *Options {
 		EnableCaller:     false,
 		OutputPaths:      []string{"stdout"},
 		ErrorOutputPaths: []string{"stderr"},
+		RedactKeys:       append([]string(nil), DefaultRedactKeys...),
+		Rotation: RotationOptions{
+			Symlink: true,
+		},
+		Sampling: SamplingOptions{
+			Enabled:    true,
+			Initial:    100,
+			Thereafter: 100,
+			Tick:       time.Second,
+		},
 	}
 }
 
@@ -57,21 +124,140 @@ func (o *Options) Validate() []error {
 		errs = append(errs, fmt.Errorf("not a valid log format: %q", o.Format))
 	}
 
+	if o.Verbosity < 0 || o.Verbosity > MaxVerbosity {
+		errs = append(errs, fmt.Errorf("--%s must be between 0 and %d", flagVerbosity, MaxVerbosity))
+	}
+
+	if _, err := parseVModule(o.VModule); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, o.Rotation.validate()...)
+	errs = append(errs, o.Sampling.validate()...)
+
+	for _, sink := range o.Sinks {
+		errs = append(errs, sink.validate()...)
+	}
+
 	return errs
 }
 
 // AddFlags adds the flags for Options.
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.Level, flagLevel, o.Level, "Minimum log output `LEVEL`.")
+	fs.IntVarP(&o.Verbosity, flagVerbosity, "v", o.Verbosity,
+		"Number for the log verbosity, V(n) messages are written when n is at most the verbosity.")
+	fs.StringVar(&o.VModule, flagVModule, o.VModule,
+		"Comma separated list of pattern=N setting the verbosity of the matching files, e.g. server=2,storage/*=4.")
 	fs.StringVar(&o.Format, flagFormat, o.Format, "Log output `FORMAT`, support plain or json format.")
 	fs.BoolVar(&o.EnableColor, flagEnableColor, o.EnableColor, "Enable output ansi colors in plain format logs.")
 	fs.BoolVar(&o.EnableCaller, flagEnableCaller, o.EnableCaller, "Enable adding caller info in the logs.")
 	fs.StringSliceVar(&o.OutputPaths, flagOutputPaths, o.OutputPaths, "Output paths of log.")
 	fs.StringSliceVar(&o.ErrorOutputPaths, flagErrorOutputPaths, o.ErrorOutputPaths, "Error output paths of log.")
+	fs.StringSliceVar(&o.RedactKeys, flagRedactKeys, o.RedactKeys,
+		"Key names whose values are redacted in the logs, matched ignoring case and separators. "+
+			"An empty list disables redaction.")
+	fs.BoolVar(&o.Sampling.Enabled, flagSampling, o.Sampling.Enabled,
+		"Sample the logs written with the same level and message, to bound the cost of logging.")
+	fs.IntVar(&o.Sampling.Initial, flagSamplingInitial, o.Sampling.Initial,
+		"Number of logs with the same level and message written every tick before sampling.")
+	fs.IntVar(&o.Sampling.Thereafter, flagSamplingThereafter, o.Sampling.Thereafter,
+		"Once sampling, only every Nth log with the same level and message is written in the tick.")
+	fs.DurationVar(&o.Sampling.Tick, flagSamplingTick, o.Sampling.Tick, "Period of the sampling counters.")
+	fs.Var(newSinksValue(&o.Sinks), flagSink,
+		"Log sink as path=PATH[,level=LEVEL][,format=json|console][,color=true], e.g. "+
+			"path=/var/log/flora.json,format=json,level=debug. It may be repeated, "+
+			"the sinks replace the output paths. The level of a sink applies on top of --"+flagLevel+".")
+	fs.IntVar(&o.Rotation.MaxSize, flagMaxSize, o.Rotation.MaxSize,
+		"Size in megabytes of a log file before it rotates, 0 disables rotation by size.")
+	fs.DurationVar(&o.Rotation.Interval, flagInterval, o.Rotation.Interval,
+		"Interval between two rotations of the log files, e.g. 24h, 0 disables rotation by time.")
+	fs.IntVar(&o.Rotation.MaxBackups, flagMaxBackups, o.Rotation.MaxBackups,
+		"Number of rotated log files to keep, 0 keeps all of them.")
+	fs.DurationVar(&o.Rotation.MaxAge, flagMaxAge, o.Rotation.MaxAge,
+		"Time rotated log files are kept, 0 keeps them forever.")
+	fs.BoolVar(&o.Rotation.Compress, flagCompress, o.Rotation.Compress, "Gzip the rotated log files.")
+	fs.BoolVar(&o.Rotation.Symlink, flagSymlink, o.Rotation.Symlink,
+		"Make the log output paths symlinks to the current log files when rotation is enabled.")
 }
 
+func (o *SamplingOptions) validate() []error {
+	if !o.Enabled {
+		return nil
+	}
+
+	var errs []error
+
+	if o.Initial < 1 {
+		errs = append(errs, fmt.Errorf("--%s must be at least 1", flagSamplingInitial))
+	}
+
+	if o.Thereafter < 0 {
+		errs = append(errs, fmt.Errorf("--%s must not be negative", flagSamplingThereafter))
+	}
+
+	if o.Tick <= 0 {
+		errs = append(errs, fmt.Errorf("--%s must be positive", flagSamplingTick))
+	}
+
+	return errs
+}
+
+func (o *RotationOptions) validate() []error {
+	var errs []error
+
+	if o.MaxSize < 0 {
+		errs = append(errs, fmt.Errorf("--%s must not be negative", flagMaxSize))
+	}
+
+	if o.Interval < 0 {
+		errs = append(errs, fmt.Errorf("--%s must not be negative", flagInterval))
+	}
+
+	if o.Interval > 0 && o.Interval < time.Minute {
+		errs = append(errs, fmt.Errorf("--%s must be at least 1m", flagInterval))
+	}
+
+	if o.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("--%s must not be negative", flagMaxBackups))
+	}
+
+	if o.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("--%s must not be negative", flagMaxAge))
+	}
+
+	if !o.Enabled() && (o.MaxBackups > 0 || o.MaxAge > 0 || o.Compress) {
+		errs = append(errs, fmt.Errorf("--%s, --%s and --%s require --%s or --%s",
+			flagMaxBackups, flagMaxAge, flagCompress, flagMaxSize, flagInterval))
+	}
+
+	return errs
+}
+
+// String returns the JSON representation of the Options with the credentials
+// of the output URLs redacted.
 func (o *Options) String() string {
-	data, err := json.Marshal(o)
+	r := newRedactor(o.RedactKeys)
+	redact := func(paths []string) []string {
+		out := make([]string, 0, len(paths))
+		for _, p := range paths {
+			out = append(out, redactURL(r, p))
+		}
+
+		return out
+	}
+
+	cp := *o
+	cp.OutputPaths = redact(o.OutputPaths)
+	cp.ErrorOutputPaths = redact(o.ErrorOutputPaths)
+	cp.Sinks = make([]SinkOptions, 0, len(o.Sinks))
+
+	for _, sink := range o.Sinks {
+		sink.Path = redactURL(r, sink.Path)
+		cp.Sinks = append(cp.Sinks, sink)
+	}
+
+	data, err := json.Marshal(&cp)
 	if err != nil {
 		return ""
 	}
@@ -0,0 +1,259 @@
+package log
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the sensitive values in the logs.
+const Redacted = "--- REDACTED ---"
+
+// DefaultRedactKeys are the key names whose values are redacted by default.
+// A key matches when it contains one of them, ignoring case and the
+// separators, e.g. "password" matches "db-password" and "PasswordHash".
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secretkey", "token", "authorization", "apikey", "privatekey",
+}
+
+// Sensitive is a string whose value is never written in the logs: it prints
+// and marshals as Redacted.
+//
+//	log.Infow("Logged in", "user", name, "session", log.Sensitive(session))
+type Sensitive string
+
+// String implements fmt.Stringer.
+func (s Sensitive) String() string {
+	return Redacted
+}
+
+// GoString implements fmt.GoStringer.
+func (s Sensitive) GoString() string {
+	return strconv.Quote(Redacted)
+}
+
+// LogValue implements slog.LogValuer.
+func (s Sensitive) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// redactor redacts the values of the sensitive keys in fields and in
+// key=value pairs of messages.
+type redactor struct {
+	keys []string
+	// pairs matches key=value, key: value, "key":"value" and
+	// Authorization: Bearer value.
+	pairs *regexp.Regexp
+}
+
+// newRedactor returns a redactor for keys, or nil when keys is empty.
+func newRedactor(keys []string) *redactor {
+	r := &redactor{}
+
+	var alternatives []string
+
+	for _, k := range keys {
+		k = normalizeKey(k)
+		if len(k) == 0 {
+			continue
+		}
+
+		r.keys = append(r.keys, k)
+
+		chars := make([]string, 0, len(k))
+		for _, c := range k {
+			chars = append(chars, regexp.QuoteMeta(string(c)))
+		}
+
+		alternatives = append(alternatives, strings.Join(chars, `[-_.]?`))
+	}
+
+	if len(r.keys) == 0 {
+		return nil
+	}
+
+	r.pairs = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(alternatives, "|") + `)[\w.-]*"?\s*[=:]\s*)` +
+		`((?:bearer|basic)\s+[^\s,;&"']+|"[^"]*"|'[^']*'|[^\s,;&"'}]+)`)
+
+	return r
+}
+
+func normalizeKey(key string) string {
+	return strings.Map(func(c rune) rune {
+		switch c {
+		case '-', '_', '.', ' ':
+			return -1
+		}
+
+		return c
+	}, strings.ToLower(key))
+}
+
+// sensitive returns whether the value of key is redacted.
+func (r *redactor) sensitive(key string) bool {
+	key = normalizeKey(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactString redacts the values of the sensitive key=value pairs of s.
+func (r *redactor) redactString(s string) string {
+	if !strings.ContainsAny(s, "=:") {
+		return s
+	}
+
+	return r.pairs.ReplaceAllStringFunc(s, func(pair string) string {
+		m := r.pairs.FindStringSubmatch(pair)
+
+		value := Redacted
+		if q := m[2][0]; q == '"' || q == '\'' {
+			value = string(q) + Redacted + string(q)
+		}
+
+		return m[1] + value
+	})
+}
+
+// redactFields returns fields with the sensitive values redacted. fields is
+// copied on the first change.
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	out, copied := fields, false
+
+	for i, f := range fields {
+		redacted, changed := r.redactField(f)
+		if !changed {
+			continue
+		}
+
+		if !copied {
+			out, copied = append([]zapcore.Field(nil), fields...), true
+		}
+
+		out[i] = redacted
+	}
+
+	return out
+}
+
+func (r *redactor) redactField(f zapcore.Field) (zapcore.Field, bool) {
+	if r.sensitive(f.Key) {
+		if f.Type == zapcore.NamespaceType || f.Type == zapcore.SkipType {
+			return f, false
+		}
+
+		return zap.String(f.Key, Redacted), true
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		if s := r.redactString(f.String); s != f.String {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ReflectType:
+		var (
+			m       map[string]interface{}
+			changed bool
+		)
+
+		switch v := f.Interface.(type) {
+		case map[string]string:
+			m, changed = redactMap(r, v)
+		case map[string][]string:
+			m, changed = redactMap(r, v)
+		case http.Header:
+			m, changed = redactMap(r, v)
+		case map[string]interface{}:
+			m, changed = redactMap(r, v)
+		}
+
+		if changed {
+			return zap.Any(f.Key, m), true
+		}
+	}
+
+	return f, false
+}
+
+// redactMap returns a copy of m with the values of the sensitive keys
+// replaced by Redacted, and whether m has any sensitive key.
+func redactMap[M ~map[string]V, V any](r *redactor, m M) (map[string]interface{}, bool) {
+	changed := false
+
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if r.sensitive(k) {
+			out[k], changed = Redacted, true
+		} else {
+			out[k] = v
+		}
+	}
+
+	return out, changed
+}
+
+// redactURL redacts the password and the sensitive query parameters of s
+// when it is a URL.
+func redactURL(r *redactor, s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		if r == nil {
+			return s
+		}
+
+		return r.redactString(s)
+	}
+
+	if _, ok := u.User.Password(); ok {
+		s = strings.Replace(s, u.User.String()+"@", u.User.Username()+":"+Redacted+"@", 1)
+	}
+
+	if r != nil {
+		s = r.redactString(s)
+	}
+
+	return s
+}
+
+// redactCore redacts the fields and the messages of the entries written to
+// an output core. It must wrap the output directly since Check does not
+// delegate to the wrapped core.
+type redactCore struct {
+	zapcore.Core
+
+	redactor *redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+
+	return ce
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.redactString(entry.Message)
+
+	return c.Core.Write(entry, c.redactor.redactFields(fields))
+}
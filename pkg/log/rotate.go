@@ -0,0 +1,427 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// fileTimeFormat is the format of the time in the name of the log files.
+	// It sorts lexically in chronological order.
+	fileTimeFormat = "20060102T150405.000"
+	compressSuffix = ".gz"
+	megabyte       = 1024 * 1024
+)
+
+// rotatingFile is an io.WriteCloser writing to the files named after path
+// and the time they were created: flora.log is written to
+// flora-20261018T120000.000.log until it rotates to a new file. When
+// RotationOptions.Symlink is set, path is a symlink to the current file so
+// that it can be followed with tail -F.
+//
+// The rotated files are removed when there are more than MaxBackups of them
+// or they were last written more than MaxAge ago, and are gzipped when
+// Compress is set.
+type rotatingFile struct {
+	path   string
+	dir    string
+	prefix string
+	ext    string
+	opts   RotationOptions
+	now    func() time.Time
+
+	lock         sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+
+	// millLock serializes the removal and compression of the rotated files,
+	// which run in the background tracked by milling.
+	millLock sync.Mutex
+	milling  sync.WaitGroup
+}
+
+func newRotatingFile(path string, opts RotationOptions) *rotatingFile {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+
+	return &rotatingFile{
+		path:   path,
+		dir:    filepath.Clean(dir),
+		prefix: strings.TrimSuffix(base, ext) + "-",
+		ext:    ext,
+		opts:   opts,
+		now:    time.Now,
+	}
+}
+
+// Write writes p to the current file, rotating it first when p does not fit
+// in MaxSize or the rotation interval has elapsed.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+
+	if r.file == nil {
+		if err := r.resume(now); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.shouldRotate(now, len(p)) {
+		if err := r.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// Sync commits the current file to stable storage.
+func (r *rotatingFile) Sync() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	return r.file.Sync()
+}
+
+// Close closes the current file and waits for the clean up of the rotated
+// files.
+func (r *rotatingFile) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	defer r.milling.Wait()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	return err
+}
+
+func (r *rotatingFile) shouldRotate(now time.Time, n int) bool {
+	if r.size == 0 {
+		return false
+	}
+
+	if r.opts.MaxSize > 0 && r.size+int64(n) > int64(r.opts.MaxSize)*megabyte {
+		return true
+	}
+
+	return r.opts.Interval > 0 && !now.Before(r.nextRotation)
+}
+
+// resume reopens the newest file when it can still be written to, so that a
+// restart does not rotate the file.
+func (r *rotatingFile) resume(now time.Time) error {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+
+	files, err := r.logFiles()
+	if err != nil {
+		return err
+	}
+
+	if len(files) != 0 && !files[0].compressed {
+		newest := files[0]
+		fits := r.opts.MaxSize == 0 || newest.size < int64(r.opts.MaxSize)*megabyte
+		current := r.opts.Interval == 0 || !now.Before(newest.created) && now.Before(r.rotationAfter(newest.created))
+
+		if fits && current {
+			f, err := os.OpenFile(filepath.Join(r.dir, newest.name), os.O_WRONLY|os.O_APPEND, 0o644)
+			if err == nil {
+				r.file, r.size, r.nextRotation = f, newest.size, r.rotationAfter(newest.created)
+
+				return r.link(newest.name)
+			}
+		}
+	}
+
+	return r.rotate(now)
+}
+
+// rotate closes the current file and opens a new one.
+func (r *rotatingFile) rotate(now time.Time) error {
+	if err := r.keepPlainFile(); err != nil {
+		return err
+	}
+
+	var (
+		f    *os.File
+		name string
+		err  error
+	)
+
+	// Two files created in the same millisecond get distinct names.
+	for t, i := now, 0; i < 100; t, i = t.Add(time.Millisecond), i+1 {
+		name = r.prefix + t.Format(fileTimeFormat) + r.ext
+		f, err = os.OpenFile(filepath.Join(r.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+
+		if !errors.Is(err, fs.ErrExist) {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open new log file: %w", err)
+	}
+
+	if r.file != nil {
+		_ = r.file.Close()
+	}
+
+	r.file, r.size, r.nextRotation = f, 0, r.rotationAfter(now)
+
+	if err := r.link(name); err != nil {
+		return err
+	}
+
+	r.milling.Add(1)
+
+	go func() {
+		defer r.milling.Done()
+		r.mill(name)
+	}()
+
+	return nil
+}
+
+// rotationAfter returns when a file created at t rotates. Rotations happen
+// at multiples of the interval, e.g. every hour on the hour.
+func (r *rotatingFile) rotationAfter(t time.Time) time.Time {
+	if r.opts.Interval <= 0 {
+		return time.Time{}
+	}
+
+	return t.Truncate(r.opts.Interval).Add(r.opts.Interval)
+}
+
+// keepPlainFile renames the file at path, written before rotation was
+// enabled, so that it is not replaced by the symlink.
+func (r *rotatingFile) keepPlainFile() error {
+	if !r.opts.Symlink {
+		return nil
+	}
+
+	info, err := os.Lstat(r.path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+
+	name := r.prefix + info.ModTime().Format(fileTimeFormat) + r.ext
+
+	return os.Rename(r.path, filepath.Join(r.dir, name))
+}
+
+// link points the symlink at path to the file name.
+func (r *rotatingFile) link(name string) error {
+	if !r.opts.Symlink {
+		return nil
+	}
+
+	tmp := r.path + ".tmp"
+	_ = os.Remove(tmp)
+
+	if err := os.Symlink(name, tmp); err != nil {
+		return fmt.Errorf("failed to link log file: %w", err)
+	}
+
+	return os.Rename(tmp, r.path)
+}
+
+// logFile describes a file written by rotatingFile.
+type logFile struct {
+	name       string
+	created    time.Time
+	modified   time.Time
+	size       int64
+	compressed bool
+}
+
+// logFiles returns the files written by r, newest first.
+func (r *rotatingFile) logFiles() ([]logFile, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []logFile
+
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, r.prefix) {
+			continue
+		}
+
+		ts, compressed := strings.CutSuffix(name[len(r.prefix):], compressSuffix)
+
+		ts, ok := strings.CutSuffix(ts, r.ext)
+		if !ok {
+			continue
+		}
+
+		created, err := time.ParseInLocation(fileTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, logFile{
+			name:       name,
+			created:    created,
+			modified:   info.ModTime(),
+			size:       info.Size(),
+			compressed: compressed,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].created.After(files[j].created)
+	})
+
+	return files, nil
+}
+
+// mill removes and compresses the rotated files, leaving current alone.
+func (r *rotatingFile) mill(current string) {
+	r.millLock.Lock()
+	defer r.millLock.Unlock()
+
+	if err := r.millLocked(current); err != nil {
+		fmt.Fprintf(os.Stderr, "log: failed to clean up rotated log files of %s: %v\n", r.path, err)
+	}
+}
+
+func (r *rotatingFile) millLocked(current string) error {
+	files, err := r.logFiles()
+	if err != nil {
+		return err
+	}
+
+	var (
+		backups []logFile
+		remove  []logFile
+	)
+
+	for _, f := range files {
+		if f.name != current {
+			backups = append(backups, f)
+		}
+	}
+
+	if r.opts.MaxBackups > 0 && len(backups) > r.opts.MaxBackups {
+		remove = append(remove, backups[r.opts.MaxBackups:]...)
+		backups = backups[:r.opts.MaxBackups]
+	}
+
+	if r.opts.MaxAge > 0 {
+		cutoff := r.now().Add(-r.opts.MaxAge)
+		kept := backups[:0]
+
+		for _, f := range backups {
+			if f.modified.Before(cutoff) {
+				remove = append(remove, f)
+			} else {
+				kept = append(kept, f)
+			}
+		}
+
+		backups = kept
+	}
+
+	var errs []error
+
+	for _, f := range remove {
+		if err := os.Remove(filepath.Join(r.dir, f.name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if r.opts.Compress {
+		for _, f := range backups {
+			if f.compressed {
+				continue
+			}
+
+			if err := compressFile(filepath.Join(r.dir, f.name)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// compressFile gzips name to name.gz and removes name.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(name + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	// Keep the modification time so that MaxAge still applies.
+	if err = os.Chtimes(name+compressSuffix, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
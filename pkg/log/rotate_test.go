@@ -0,0 +1,192 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func TestRotatingFile(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		opts RotationOptions
+		// writes are done one second apart unless they start with a +.
+		writes []string
+		want   []string
+	}{
+		{
+			name:   "size",
+			opts:   RotationOptions{MaxSize: 1, Symlink: true},
+			writes: []string{strings.Repeat("a", megabyte-1), "b", "c"},
+			want: []string{
+				"flora-20261018T120000.000.log",
+				"flora-20261018T120002.000.log",
+				"flora.log",
+			},
+		},
+		{
+			name:   "interval",
+			opts:   RotationOptions{Interval: time.Hour, Symlink: true},
+			writes: []string{"a", "+1h", "b", "c"},
+			want: []string{
+				"flora-20261018T120000.000.log",
+				"flora-20261018T130001.000.log",
+				"flora.log",
+			},
+		},
+		{
+			name:   "max backups and compress",
+			opts:   RotationOptions{Interval: time.Hour, MaxBackups: 1, Compress: true},
+			writes: []string{"a", "+1h", "b", "+1h", "c"},
+			want: []string{
+				"flora-20261018T130001.000.log.gz",
+				"flora-20261018T140002.000.log",
+			},
+		},
+		{
+			name:   "max age",
+			opts:   RotationOptions{Interval: time.Hour, MaxAge: 90 * time.Minute, Symlink: true},
+			writes: []string{"a", "+1h", "b", "+1h", "c"},
+			want: []string{
+				"flora-20261018T130001.000.log",
+				"flora-20261018T140002.000.log",
+				"flora.log",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			now := start
+
+			r := newRotatingFile(filepath.Join(dir, "flora.log"), tt.opts)
+			r.now = func() time.Time { return now }
+
+			for i, w := range tt.writes {
+				if d, ok := strings.CutPrefix(w, "+"); ok {
+					delay, _ := time.ParseDuration(d)
+					now = now.Add(delay)
+
+					continue
+				}
+
+				if i != 0 {
+					now = now.Add(time.Second)
+				}
+
+				if _, err := r.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+
+				r.milling.Wait()
+				// Date the file as if it was written at now.
+				_ = os.Chtimes(r.file.Name(), now, now)
+			}
+
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := listDir(t, dir)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+
+			if tt.opts.Symlink {
+				target, err := os.Readlink(filepath.Join(dir, "flora.log"))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if target != tt.want[len(tt.want)-2] {
+					t.Errorf("symlink target = %s, want %s", target, tt.want[len(tt.want)-2])
+				}
+			}
+		})
+	}
+}
+
+func TestRotatingFileResume(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flora.log")
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+
+	// A file written before rotation was enabled is kept.
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.Chtimes(path, now.Add(-time.Hour), now.Add(-time.Hour))
+
+	for i := 0; i < 2; i++ {
+		r := newRotatingFile(path, RotationOptions{Interval: time.Hour, Symlink: true})
+		r.now = func() time.Time { return now }
+
+		if _, err := r.Write([]byte("new\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		_ = r.Close()
+	}
+
+	want := []string{"flora-20261018T110000.000.log", "flora-20261018T120000.000.log", "flora.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "new\nnew\n" {
+		t.Errorf("current file = %q, want both writes", data)
+	}
+}
+
+func TestRotationOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RotationOptions
+		errs int
+	}{
+		{"disabled", RotationOptions{Symlink: true}, 0},
+		{"size", RotationOptions{MaxSize: 100, MaxBackups: 3, MaxAge: time.Hour, Compress: true}, 0},
+		{"interval", RotationOptions{Interval: 24 * time.Hour}, 0},
+		{"negative", RotationOptions{MaxSize: -1, MaxBackups: -1}, 2},
+		{"short interval", RotationOptions{Interval: time.Second}, 1},
+		{"retention without rotation", RotationOptions{MaxBackups: 3}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.opts.validate(); len(errs) != tt.errs {
+				t.Errorf("validate() = %v, want %d errors", errs, tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkOptions contains the configuration of a log output with its own level
+// and format.
+type SinkOptions struct {
+	// Path is a file path, stdout, stderr or a URL registered with zap.
+	Path string `json:"path" mapstructure:"path"`
+	// Level is the minimum level written to the sink, on top of the level of
+	// the logger. Empty writes every entry the logger enables.
+	Level string `json:"level,omitempty" mapstructure:"level"`
+	// Format is console or json, Options.Format if empty.
+	Format      string `json:"format,omitempty"       mapstructure:"format"`
+	EnableColor bool   `json:"enable-color,omitempty" mapstructure:"enable-color"`
+}
+
+// ParseSink parses a sink from path=PATH[,level=LEVEL][,format=FORMAT][,color=BOOL].
+// The path= prefix of the first element may be omitted.
+func ParseSink(s string) (SinkOptions, error) {
+	var sink SinkOptions
+
+	for i, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			if i != 0 {
+				return sink, fmt.Errorf("invalid sink %q: expected key=value in %q", s, part)
+			}
+
+			key, value = "path", key
+		}
+
+		switch key {
+		case "path":
+			sink.Path = value
+		case "level":
+			sink.Level = value
+		case "format":
+			sink.Format = value
+		case "color":
+			color, err := strconv.ParseBool(value)
+			if err != nil {
+				return sink, fmt.Errorf("invalid sink %q: %w", s, err)
+			}
+
+			sink.EnableColor = color
+		default:
+			return sink, fmt.Errorf("invalid sink %q: unknown key %q", s, key)
+		}
+	}
+
+	return sink, nil
+}
+
+// String returns the sink in the format read by ParseSink.
+func (o SinkOptions) String() string {
+	parts := []string{"path=" + o.Path}
+	if len(o.Level) != 0 {
+		parts = append(parts, "level="+o.Level)
+	}
+
+	if len(o.Format) != 0 {
+		parts = append(parts, "format="+o.Format)
+	}
+
+	if o.EnableColor {
+		parts = append(parts, "color=true")
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (o *SinkOptions) validate() []error {
+	var errs []error
+
+	if len(o.Path) == 0 {
+		errs = append(errs, fmt.Errorf("--%s %q: the path is required", flagSink, o.String()))
+	}
+
+	if len(o.Level) != 0 {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(o.Level)); err != nil {
+			errs = append(errs, fmt.Errorf("--%s %q: %w", flagSink, o.String(), err))
+		}
+	}
+
+	if f := strings.ToLower(o.Format); len(f) != 0 && f != consoleFormat && f != jsonFormat {
+		errs = append(errs, fmt.Errorf("--%s %q: not a valid log format: %q", flagSink, o.String(), o.Format))
+	}
+
+	return errs
+}
+
+// sinksValue is a pflag.Value appending a sink each time the flag is set.
+type sinksValue struct {
+	sinks   *[]SinkOptions
+	changed bool
+}
+
+func newSinksValue(sinks *[]SinkOptions) *sinksValue {
+	return &sinksValue{sinks: sinks}
+}
+
+func (v *sinksValue) Set(s string) error {
+	sink, err := ParseSink(s)
+	if err != nil {
+		return err
+	}
+
+	// The first value replaces the default.
+	if !v.changed {
+		*v.sinks = nil
+		v.changed = true
+	}
+
+	*v.sinks = append(*v.sinks, sink)
+
+	return nil
+}
+
+func (v *sinksValue) String() string {
+	if v.sinks == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, len(*v.sinks))
+	for _, s := range *v.sinks {
+		parts = append(parts, s.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (v *sinksValue) Type() string {
+	return "sink"
+}
@@ -0,0 +1,168 @@
+package log
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SinkOptions
+		wantErr bool
+	}{
+		{"stderr", SinkOptions{Path: "stderr"}, false},
+		{"path=/var/log/flora.json,format=json,level=debug", SinkOptions{Path: "/var/log/flora.json", Level: "debug", Format: "json"}, false},
+		{"stderr,level=info,color=true", SinkOptions{Path: "stderr", Level: "info", EnableColor: true}, false},
+		{"stderr,color=maybe", SinkOptions{}, true},
+		{"stderr,size=1", SinkOptions{}, true},
+		{"stderr,info", SinkOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSink(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSink(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseSink(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "flora.json")
+	consolePath := filepath.Join(dir, "flora.log")
+
+	opts := NewOptions()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+
+	if err := fs.Parse([]string{
+		"--log.sink", "path=" + jsonPath + ",format=json",
+		"--log.sink", consolePath + ",level=warn,format=console",
+		"--log.sampling=false",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := opts.Validate(); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	l, closeOutputs, err := build(opts, true, newLevels(zapcore.DebugLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeOutputs()
+
+	// Without sampling, the repeated entries are all written.
+	for i := 0; i < 150; i++ {
+		l.Debug("repeated")
+	}
+
+	l.Warn("warning")
+	_ = l.Sync()
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 151 {
+		t.Fatalf("json sink has %d lines, want 151", len(lines))
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[150]), &entry); err != nil || entry["msg"] != "warning" {
+		t.Errorf("json sink last line = %s, want the warning in JSON", lines[150])
+	}
+
+	data, err = os.ReadFile(consolePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := strings.TrimSpace(string(data)); strings.Contains(s, "repeated") || !strings.Contains(s, "warn\twarning") {
+		t.Errorf("console sink = %q, want only the warning", s)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flora.log")
+
+	opts := NewOptions()
+	opts.OutputPaths = []string{path}
+	opts.Sampling.Initial = 2
+	opts.Sampling.Thereafter = 10
+
+	l, closeOutputs, err := build(opts, true, newLevels(zapcore.InfoLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeOutputs()
+
+	for i := 0; i < 30; i++ {
+		l.Info("repeated")
+	}
+
+	_ = l.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first 2, then the 12th and the 22nd.
+	if n := strings.Count(string(data), "repeated"); n != 4 {
+		t.Errorf("wrote %d entries, want 4", n)
+	}
+}
+
+func TestBuildClose(t *testing.T) {
+	openFiles := func() int {
+		fds, err := os.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skip("the open files are listed in /proc")
+		}
+
+		return len(fds)
+	}
+
+	dir := t.TempDir()
+
+	for _, rotate := range []bool{false, true} {
+		opts := NewOptions()
+		opts.OutputPaths = []string{filepath.Join(dir, "flora.log")}
+		opts.ErrorOutputPaths = []string{filepath.Join(dir, "flora-error.log")}
+
+		if rotate {
+			opts.Rotation.MaxSize = 1
+		}
+
+		before := openFiles()
+
+		l, closeOutputs, err := build(opts, true, newLevels(zapcore.InfoLevel))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		l.Info("written")
+		_ = l.Sync()
+		closeOutputs()
+
+		if after := openFiles(); after != before {
+			t.Errorf("rotate %v: %d files open after close, want %d", rotate, after, before)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The slog levels map to the zap levels as follows: Error, Warn and Info map
+// to the levels of the same name, Debug maps to Debug which is V(1), and
+// every level below Debug maps to the next V level, e.g. Debug-1 is V(2).
+
+// zapLevel returns the zap level of the slog level l.
+func zapLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case l >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case l >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	case l >= slog.LevelDebug:
+		return zapcore.DebugLevel
+	}
+
+	return verbosityLevel(int(slog.LevelDebug-l) + 1)
+}
+
+// slogLevel returns the slog level of the zap level l.
+func slogLevel(l zapcore.Level) slog.Level {
+	switch {
+	case l >= zapcore.ErrorLevel:
+		return slog.LevelError
+	case l >= zapcore.WarnLevel:
+		return slog.LevelWarn
+	case l >= zapcore.InfoLevel:
+		return slog.LevelInfo
+	}
+
+	return slog.LevelDebug - slog.Level(zapcore.DebugLevel-l)
+}
+
+// NewSlogHandler returns a slog.Handler writing to l, so that the records
+// logged with log/slog go to the configured outputs of l:
+//
+//	slog.SetDefault(slog.New(log.NewSlogHandler(log.WithName("storage"))))
+//
+// Loggers not created by this package are replaced by the global logger.
+func NewSlogHandler(l Logger) slog.Handler {
+	switch l := l.(type) {
+	case *zapLogger:
+		return &slogHandler{logger: l.zapLogger}
+	case *slogLogger:
+		return l.handler()
+	}
+
+	return &slogHandler{logger: ZapLogger()}
+}
+
+// slogHandler is a slog.Handler writing to a zap logger.
+type slogHandler struct {
+	logger *zap.Logger
+	// groups are the groups opened by WithGroup but still empty: a group is
+	// only written once it has attributes.
+	groups []string
+}
+
+var _ slog.Handler = &slogHandler{}
+
+func (h *slogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return enabled(h.logger, zapLevel(l))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.logger.Check(zapLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+
+	// The caller computed by zap is in log/slog, use the one of the record.
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(r.PC, frame.File, frame.Line, true)
+	}
+
+	// The values of the context are not part of the groups.
+	kvs := contextValues(ctx)
+	fields := make([]zapcore.Field, 0, len(kvs)/2+len(h.groups)+r.NumAttrs())
+
+	for i := 0; i+1 < len(kvs); i += 2 {
+		fields = append(fields, zap.Any(kvs[i].(string), kvs[i+1]))
+	}
+
+	var attrs []zapcore.Field
+
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendAttr(attrs, a)
+		return true
+	})
+
+	if len(attrs) != 0 {
+		fields = append(append(fields, h.openGroups()...), attrs...)
+	}
+
+	ce.Write(fields...)
+
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zapcore.Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+
+	if len(fields) == 0 {
+		return h
+	}
+
+	return &slogHandler{logger: h.logger.With(append(h.openGroups(), fields...)...)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+
+	return &slogHandler{logger: h.logger, groups: append(h.groups[:len(h.groups):len(h.groups)], name)}
+}
+
+// openGroups returns the namespaces of the pending groups.
+func (h *slogHandler) openGroups() []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(h.groups))
+	for _, g := range h.groups {
+		fields = append(fields, zap.Namespace(g))
+	}
+
+	return fields
+}
+
+// appendAttr appends the zap field of a to fields.
+func appendAttr(fields []zapcore.Field, a slog.Attr) []zapcore.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+
+		// A group without key is inlined.
+		if len(a.Key) == 0 {
+			for _, ga := range attrs {
+				fields = appendAttr(fields, ga)
+			}
+
+			return fields
+		}
+
+		return append(fields, zap.Object(a.Key, attrGroup(attrs)))
+	}
+
+	if err, ok := a.Value.Any().(error); ok {
+		return append(fields, zap.NamedError(a.Key, err))
+	}
+
+	return append(fields, zap.Any(a.Key, a.Value.Any()))
+}
+
+// attrGroup marshals the attributes of a group as an object.
+type attrGroup []slog.Attr
+
+func (g attrGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zapcore.Field
+	for _, a := range g {
+		fields = appendAttr(fields, a)
+	}
+
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	return nil
+}
+
+// NewSlogLogger returns a Logger writing to the slog.Handler h, the reverse
+// of NewSlogHandler.
+func NewSlogLogger(h slog.Handler) Logger {
+	return &slogLogger{h: h, level: slog.LevelInfo}
+}
+
+// slogLogger is a Logger writing to a slog.Handler.
+type slogLogger struct {
+	h     slog.Handler
+	name  string
+	level slog.Level
+
+	// ids are the IDs of a context logged by the attributes of the logger.
+	ids contextIDs
+}
+
+var _ Logger = &slogLogger{}
+
+// handler returns the handler of l with its name.
+func (l *slogLogger) handler() slog.Handler {
+	if len(l.name) == 0 {
+		return l.h
+	}
+
+	return l.h.WithAttrs([]slog.Attr{slog.String("logger", l.name)})
+}
+
+// log writes a record, skip is the number of frames between the caller and
+// log.
+func (l *slogLogger) log(level slog.Level, skip int, msg string, keysAndValues ...interface{}) {
+	ctx := context.Background()
+	if !l.h.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if len(l.name) != 0 {
+		r.AddAttrs(slog.String("logger", l.name))
+	}
+
+	r.Add(keysAndValues...)
+	_ = l.h.Handle(ctx, r)
+}
+
+func (l *slogLogger) Enabled() bool {
+	return l.h.Enabled(context.Background(), l.level)
+}
+
+func (l *slogLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(l.level, 1, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.log(l.level, 1, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.log(slog.LevelError, 1, msg, append(keysAndValues, "error", err)...)
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, 1, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) V(level int) InfoLogger {
+	v := &slogLogger{h: l.h, name: l.name, level: slogLevel(verbosityLevel(level))}
+	if !v.Enabled() {
+		return disabledInfoLogger
+	}
+
+	return v
+}
+
+func (l *slogLogger) Write(p []byte) (int, error) {
+	l.log(slog.LevelInfo, 1, string(p))
+
+	return len(p), nil
+}
+
+func (l *slogLogger) WithValues(keysAndValues ...interface{}) Logger {
+	// Let slog.Record turn the key-value pairs into attributes.
+	r := slog.Record{}
+	r.Add(keysAndValues...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return &slogLogger{h: l.h.WithAttrs(attrs), name: l.name, level: l.level, ids: l.ids}
+}
+
+func (l *slogLogger) WithName(name string) Logger {
+	if len(l.name) != 0 {
+		name = l.name + "." + name
+	}
+
+	return &slogLogger{h: l.h, name: name, level: l.level, ids: l.ids}
+}
+
+func (l *slogLogger) WithContext(ctx context.Context) context.Context {
+	return NewContext(ctx, l)
+}
+
+func (l *slogLogger) Flush() {}
@@ -0,0 +1,137 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+)
+
+func TestSlogHandler(t *testing.T) {
+	core, logs := observer.New(allLevels)
+	l := NewLogger(zap.New(&levelFilterCore{Core: core, levels: newLevels(zapcore.DebugLevel)}, zap.AddCaller()))
+	s := slog.New(NewSlogHandler(l.WithName("storage")))
+
+	ctx := requestid.WithRequestID(context.Background(), "req-1")
+
+	s.InfoContext(ctx, "created", "name", "lease-a", "rv", 3, slog.Group("owner", "id", "x"))
+	s.With("backend", "bolt").WithGroup("empty").Warn("slow", "took", 2)
+	s.WithGroup("db").With("path", "f.db").Error("failed", "err", errors.New("boom"))
+	s.Debug("debug")
+	s.Log(context.Background(), slog.LevelDebug-1, "V(2) is disabled")
+	s.Info("session", "token", Sensitive("s3cret"))
+
+	entries := logs.All()
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 5", len(entries))
+	}
+
+	tests := []struct {
+		level  zapcore.Level
+		msg    string
+		fields map[string]interface{}
+	}{
+		{zapcore.InfoLevel, "created", map[string]interface{}{
+			"request_id": "req-1", "name": "lease-a", "rv": int64(3), "owner": map[string]interface{}{"id": "x"},
+		}},
+		{zapcore.WarnLevel, "slow", map[string]interface{}{
+			"backend": "bolt", "empty": map[string]interface{}{"took": int64(2)},
+		}},
+		{zapcore.ErrorLevel, "failed", map[string]interface{}{
+			"db": map[string]interface{}{"path": "f.db", "err": "boom"},
+		}},
+		{zapcore.DebugLevel, "debug", map[string]interface{}{}},
+		{zapcore.InfoLevel, "session", map[string]interface{}{"token": Redacted}},
+	}
+
+	for i, tt := range tests {
+		e := entries[i]
+		if e.Level != tt.level || e.Message != tt.msg || e.LoggerName != "storage" {
+			t.Errorf("entry %d = %v %q %q, want %v %q storage", i, e.Level, e.Message, e.LoggerName, tt.level, tt.msg)
+		}
+
+		got, _ := json.Marshal(e.ContextMap())
+		want, _ := json.Marshal(tt.fields)
+
+		if string(got) != string(want) {
+			t.Errorf("entry %d fields = %s, want %s", i, got, want)
+		}
+
+		if !strings.HasSuffix(e.Caller.File, "slog_test.go") {
+			t.Errorf("entry %d caller = %s, want slog_test.go", i, e.Caller.File)
+		}
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: true})
+	l := NewSlogLogger(h).WithName("storage").WithValues("backend", "bolt")
+
+	l.Info("created", "name", "lease-a")
+	l.V(1).Infof("debug %d", 1)
+	l.V(2).Info("V(2) is disabled")
+	l.Error(errors.New("boom"), "failed")
+
+	if l.V(2).Enabled() || !l.V(1).Enabled() {
+		t.Errorf("V(1) and V(2) enabled = %v, %v, want true, false", l.V(1).Enabled(), l.V(2).Enabled())
+	}
+
+	var records []map[string]interface{}
+
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]interface{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+
+		records = append(records, r)
+	}
+
+	want := []struct {
+		level string
+		msg   string
+	}{{"INFO", "created"}, {"DEBUG", "debug 1"}, {"ERROR", "failed"}}
+
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+
+	for i, w := range want {
+		r := records[i]
+		if r["level"] != w.level || r["msg"] != w.msg || r["logger"] != "storage" || r["backend"] != "bolt" {
+			t.Errorf("record %d = %v, want %s %q", i, r, w.level, w.msg)
+		}
+
+		if src, _ := r["source"].(map[string]interface{}); !strings.HasSuffix(src["file"].(string), "slog_test.go") {
+			t.Errorf("record %d source = %v, want slog_test.go", i, r["source"])
+		}
+	}
+
+	if records[2]["error"] != "boom" {
+		t.Errorf("error = %v, want boom", records[2]["error"])
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	for _, l := range []slog.Level{slog.LevelError, slog.LevelWarn, slog.LevelInfo, slog.LevelDebug, slog.LevelDebug - 3} {
+		if got := slogLevel(zapLevel(l)); got != l {
+			t.Errorf("slogLevel(zapLevel(%v)) = %v", l, got)
+		}
+	}
+
+	if zapLevel(slog.LevelDebug-1) != verbosityLevel(2) {
+		t.Errorf("zapLevel(Debug-1) = %v, want V(2)", zapLevel(slog.LevelDebug-1))
+	}
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MaxVerbosity is the highest verbosity level, V(n) above it is V(MaxVerbosity).
+const MaxVerbosity = -math.MinInt8 - 1
+
+// verbosityLevel returns the zap level of the verbosity level v: V(0) is info,
+// V(1) is debug and higher levels go below debug.
+func verbosityLevel(v int) zapcore.Level {
+	switch {
+	case v < 0:
+		v = 0
+	case v > MaxVerbosity:
+		v = MaxVerbosity
+	}
+
+	return zapcore.Level(-v)
+}
+
+// levelEncoder encodes the levels below debug, written by V(2) and higher,
+// as debug.
+func levelEncoder(encode zapcore.LevelEncoder) zapcore.LevelEncoder {
+	return func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		if l < zapcore.DebugLevel {
+			l = zapcore.DebugLevel
+		}
+
+		encode(l, enc)
+	}
+}
+
+// vmoduleSpec sets the verbosity of the files matching pattern.
+type vmoduleSpec struct {
+	pattern string
+	// path is set when pattern has a slash and matches the path of the
+	// file instead of its name.
+	path  bool
+	level int
+}
+
+// vmodule holds the per file verbosity set by Options.VModule, like
+// klog's -vmodule: "server=2,storage/*=4" sets the verbosity of server.go
+// to 2 and of the files in the storage directories to 4.
+type vmodule struct {
+	specs []vmoduleSpec
+	// levels caches the verbosity of the callers of V by program counter.
+	levels sync.Map
+}
+
+// parseVModule parses a comma separated list of pattern=N.
+func parseVModule(s string) (*vmodule, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil, nil
+	}
+
+	vm := &vmodule{}
+
+	for _, spec := range strings.Split(s, ",") {
+		pattern, level, ok := strings.Cut(strings.TrimSpace(spec), "=")
+		if !ok || len(pattern) == 0 {
+			return nil, fmt.Errorf("invalid vmodule %q: expected pattern=N", spec)
+		}
+
+		v, err := strconv.Atoi(level)
+		if err != nil || v < 0 || v > MaxVerbosity {
+			return nil, fmt.Errorf("invalid vmodule %q: the level must be between 0 and %d", spec, MaxVerbosity)
+		}
+
+		pattern = strings.TrimSuffix(pattern, ".go")
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid vmodule %q: %w", spec, err)
+		}
+
+		vm.specs = append(vm.specs, vmoduleSpec{pattern: pattern, path: strings.Contains(pattern, "/"), level: v})
+	}
+
+	return vm, nil
+}
+
+// levelAt returns the verbosity of the caller at pc, or -1 when no pattern
+// matches its file.
+func (vm *vmodule) levelAt(pc uintptr) int {
+	if v, ok := vm.levels.Load(pc); ok {
+		return v.(int)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	file := strings.TrimSuffix(frame.File, ".go")
+
+	level := -1
+
+	for _, spec := range vm.specs {
+		name := filepath.Base(file)
+		if spec.path {
+			// Match the pattern against as many trailing elements of the path
+			// as it has.
+			parts := strings.Split(file, "/")
+			if n := strings.Count(spec.pattern, "/") + 1; n <= len(parts) {
+				name = strings.Join(parts[len(parts)-n:], "/")
+			}
+		}
+
+		if ok, _ := filepath.Match(spec.pattern, name); ok {
+			level = spec.level
+
+			break
+		}
+	}
+
+	vm.levels.Store(pc, level)
+
+	return level
+}
+
+// callerVerbosity returns the vmodule verbosity of the caller skip frames
+// above the function calling it, or -1.
+func (l *levels) callerVerbosity(skip int) int {
+	vm := l.vmodule.Load()
+	if vm == nil {
+		return -1
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return -1
+	}
+
+	return vm.levelAt(pcs[0])
+}
+
+// v returns the InfoLogger of the verbosity level, skip is the number of
+// frames between the caller of V and this function.
+func (z *zapLogger) v(level int, skip int) InfoLogger {
+	lvl := verbosityLevel(level)
+
+	if enabled(z.zapLogger, lvl) {
+		return &infoLogger{log: z.zapLogger, level: lvl}
+	}
+
+	filter, ok := z.zapLogger.Core().(*levelFilterCore)
+	if !ok || filter.levels.callerVerbosity(skip+1) < level {
+		return disabledInfoLogger
+	}
+
+	// vmodule enabled the caller: write past the level of the logger.
+	log := z.zapLogger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		if f, ok := c.(*levelFilterCore); ok {
+			return f.Core
+		}
+
+		return c
+	}))
+
+	return &infoLogger{log: log, level: lvl}
+}
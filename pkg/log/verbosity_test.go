@@ -0,0 +1,125 @@
+package log
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestV(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		vmodule   string
+		enabled   []int
+		disabled  []int
+	}{
+		{"default", 0, "", []int{0}, []int{1, 2, 4}},
+		{"verbosity", 2, "", []int{0, 1, 2}, []int{3, 10}},
+		{"file", 0, "server=1,verbosity_test=5", []int{0, 1, 5}, []int{6}},
+		{"file glob", 0, "verbosity_*=3", []int{3}, []int{4}},
+		{"path", 1, "log/verbosity_test=4", []int{1, 4}, []int{5}},
+		{"path glob", 0, "pkg/*/verbosity_test.go=2", []int{2}, []int{3}},
+		{"other file", 1, "server=4", []int{1}, []int{2, 4}},
+		{"huge level", MaxVerbosity, "", []int{MaxVerbosity, 1000}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm, err := parseVModule(tt.vmodule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lv := newLevels(verbosityLevel(tt.verbosity))
+			lv.vmodule.Store(vm)
+
+			core, logs := observer.New(allLevels)
+			l := NewLogger(zap.New(&levelFilterCore{Core: core, levels: lv}))
+
+			for _, v := range tt.enabled {
+				if !l.V(v).Enabled() {
+					t.Errorf("V(%d) is disabled, want enabled", v)
+				}
+
+				l.V(v).Infof("V(%d)", v)
+			}
+
+			for _, v := range tt.disabled {
+				if l.V(v).Enabled() {
+					t.Errorf("V(%d) is enabled, want disabled", v)
+				}
+
+				l.V(v).Info("disabled")
+			}
+
+			entries := logs.All()
+			if len(entries) != len(tt.enabled) {
+				t.Fatalf("got %d entries, want %d", len(entries), len(tt.enabled))
+			}
+
+			for i, v := range tt.enabled {
+				if want := verbosityLevel(v); entries[i].Level != want {
+					t.Errorf("V(%d) logged at %v, want %v", v, entries[i].Level, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckIntLevel(t *testing.T) {
+	saved := logger
+	defer func() { logger = saved }()
+
+	lv := newLevels(verbosityLevel(3))
+	logger = NewLogger(zap.New(&levelFilterCore{Core: zapcore.NewNopCore(), levels: lv})).(*zapLogger)
+
+	for level, want := range map[int32]bool{0: true, 3: true, 4: false} {
+		if got := CheckIntLevel(level); got != want {
+			t.Errorf("CheckIntLevel(%d) = %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestVLoggerOverride(t *testing.T) {
+	saved := logger
+	defer func() { logger = saved }()
+
+	lv := newLevels(zapcore.InfoLevel)
+	lv.setOverride("storage", verbosityLevel(4))
+	logger = NewLogger(zap.New(&levelFilterCore{Core: zapcore.NewNopCore(), levels: lv})).(*zapLogger)
+
+	// The override of the storage loggers does not enable the other ones.
+	for _, tt := range []struct {
+		name string
+		l    Logger
+		want bool
+	}{
+		{"storage", WithName("storage"), true},
+		{"storage.bolt", WithName("storage").WithName("bolt"), true},
+		{"server", WithName("server"), false},
+		{"global", logger, false},
+	} {
+		if got := tt.l.V(4).Enabled(); got != tt.want {
+			t.Errorf("%s: V(4).Enabled() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if CheckIntLevel(4) {
+		t.Errorf("CheckIntLevel(4) = true, want false")
+	}
+
+	if WithName("storage").V(5).Enabled() {
+		t.Errorf("storage: V(5).Enabled() = true, want false")
+	}
+}
+
+func TestParseVModule(t *testing.T) {
+	for _, s := range []string{"server", "=1", "server=x", "server=-1", "server=1000", "[=1"} {
+		if _, err := parseVModule(s); err == nil {
+			t.Errorf("parseVModule(%q) succeeded, want an error", s)
+		}
+	}
+}
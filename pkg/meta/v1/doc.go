@@ -0,0 +1,2 @@
+// Package v1 contains the API types shared by every flora resource.
+package v1
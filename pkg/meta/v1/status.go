@@ -0,0 +1,77 @@
+package v1
+
+// Values of Status.Status.
+const (
+	StatusSuccess = "Success"
+	StatusFailure = "Failure"
+)
+
+// StatusReason is an enumeration of possible failure causes. Each StatusReason
+// must map to a single HTTP status code, but multiple reasons may map
+// to the same HTTP status code.
+type StatusReason string
+
+const (
+	// StatusReasonUnknown means the server has declined to indicate a specific reason.
+	StatusReasonUnknown StatusReason = ""
+
+	// StatusReasonUnauthorized means the server can be reached and understood the request, but requires
+	// the user to present appropriate authorization credentials.
+	StatusReasonUnauthorized StatusReason = "Unauthorized"
+
+	// StatusReasonForbidden means the server can be reached and understood the request, but refuses
+	// to take any further action.
+	StatusReasonForbidden StatusReason = "Forbidden"
+
+	// StatusReasonNotFound means one or more resources required for this operation
+	// could not be found.
+	StatusReasonNotFound StatusReason = "NotFound"
+
+	// StatusReasonAlreadyExists means the resource you are creating already exists.
+	StatusReasonAlreadyExists StatusReason = "AlreadyExists"
+
+	// StatusReasonConflict means the requested operation cannot be completed
+	// due to a conflict in the operation, usually a stale resourceVersion.
+	StatusReasonConflict StatusReason = "Conflict"
+
+	// StatusReasonExpired indicates that the request is invalid because the content you are requesting
+	// has expired and is no longer available, e.g. a watch from a compacted resource version.
+	StatusReasonExpired StatusReason = "Expired"
+
+	// StatusReasonInvalid means the requested create or update operation cannot be
+	// completed due to invalid data provided as part of the request.
+	StatusReasonInvalid StatusReason = "Invalid"
+
+	// StatusReasonBadRequest means that the request itself was invalid.
+	StatusReasonBadRequest StatusReason = "BadRequest"
+
+	// StatusReasonMethodNotAllowed means that the action the client attempted to perform on the
+	// resource was not supported by the code.
+	StatusReasonMethodNotAllowed StatusReason = "MethodNotAllowed"
+
+	// StatusReasonInternalError indicates that an internal error occurred, it is unexpected
+	// and the outcome of the call is unknown.
+	StatusReasonInternalError StatusReason = "InternalError"
+
+	// StatusReasonServiceUnavailable means that the request itself was valid,
+	// but the requested service is unavailable at this time.
+	StatusReasonServiceUnavailable StatusReason = "ServiceUnavailable"
+)
+
+// Status is a return value for calls that don't return other objects.
+type Status struct {
+	TypeMeta `json:",inline"`
+
+	// Status of the operation. One of: "Success" or "Failure".
+	Status string `json:"status,omitempty"`
+
+	// A human-readable description of the status of this operation.
+	Message string `json:"message,omitempty"`
+
+	// A machine-readable description of why this operation is in the
+	// "Failure" status.
+	Reason StatusReason `json:"reason,omitempty"`
+
+	// Suggested HTTP return code for this status, 0 if not set.
+	Code int32 `json:"code,omitempty"`
+}
@@ -0,0 +1,53 @@
+package v1
+
+import "encoding/json"
+
+// TableContentType is the media type parameter that asks the server to
+// return a Table instead of the objects: "application/json;as=Table".
+const TableContentType = "application/json;as=Table"
+
+// Table is a tabular representation of a set of objects, the server decides
+// which columns are worth showing so clients can render any resource.
+type Table struct {
+	TypeMeta `json:",inline"`
+	ListMeta `json:"metadata,omitempty"`
+
+	// ColumnDefinitions describes each column of the rows.
+	ColumnDefinitions []TableColumnDefinition `json:"columnDefinitions"`
+
+	// Rows is the list of items in the table.
+	Rows []TableRow `json:"rows"`
+}
+
+// TableColumnDefinition contains information about a column returned in
+// the Table.
+type TableColumnDefinition struct {
+	// Name is a human readable name for the column.
+	Name string `json:"name"`
+
+	// Type is an OpenAPI type definition for this column, e.g. string,
+	// integer, number or boolean.
+	Type string `json:"type"`
+
+	// Format is an optional OpenAPI type modifier for this column, e.g.
+	// name or date-time.
+	Format string `json:"format,omitempty"`
+
+	// Description is a human readable description of this column.
+	Description string `json:"description,omitempty"`
+
+	// Priority is an integer defining the relative importance of this column
+	// compared to others. Lower numbers are considered higher priority;
+	// clients only show the columns with priority 0 unless asked for a wide
+	// output.
+	Priority int32 `json:"priority"`
+}
+
+// TableRow is a single row of a table.
+type TableRow struct {
+	// Cells holds one value per column definition, in the same order.
+	Cells []interface{} `json:"cells"`
+
+	// Object is the full object the row was built from.
+	Object json.RawMessage `json:"object,omitempty"`
+}
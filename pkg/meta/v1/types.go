@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// TypeMeta describes an individual object in an API response or request
+// with strings representing the type of the object and its API schema version.
+type TypeMeta struct {
+	// Kind is a string value representing the REST resource this object represents.
+	Kind string `json:"kind,omitempty"`
+
+	// APIVersion defines the versioned schema of this representation of an object.
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+var _ scheme.ObjectKind = &TypeMeta{}
+
+// SetGroupVersionKind implements scheme.ObjectKind.
+func (t *TypeMeta) SetGroupVersionKind(gvk scheme.GroupVersionKind) {
+	t.APIVersion, t.Kind = gvk.ToAPIVersionAndKind()
+}
+
+// GroupVersionKind implements scheme.ObjectKind.
+func (t *TypeMeta) GroupVersionKind() scheme.GroupVersionKind {
+	return scheme.FromAPIVersionAndKind(t.APIVersion, t.Kind)
+}
+
+// GetObjectKind returns the type information of the object.
+func (t *TypeMeta) GetObjectKind() scheme.ObjectKind { return t }
+
+// ObjectMeta is metadata that all persisted resources must have.
+type ObjectMeta struct {
+	// Name must be unique within a resource. It is required when creating resources.
+	Name string `json:"name,omitempty"`
+
+	// ResourceVersion is an opaque value that represents the internal version of this object.
+	// Clients must send it back unmodified on updates so the server can detect concurrent
+	// modifications.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// Labels are key value pairs that may be used to organize and select objects.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are unstructured key value pairs that tools may use to store metadata.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// CreatedAt is the time the object was created. It is set by the server.
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is the time the object was last updated. It is set by the server.
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// GetObjectMeta returns the metadata of the object.
+func (m *ObjectMeta) GetObjectMeta() *ObjectMeta { return m }
+
+// ListMeta describes metadata that synthetic resources must have, including lists.
+type ListMeta struct {
+	// ResourceVersion identifies the version of the store the list was read from.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// Continue is set if the list was truncated by a limit. Pass it back in
+	// ListOptions.Continue to retrieve the next page.
+	Continue string `json:"continue,omitempty"`
+}
+
+// Object is implemented by every API type that embeds TypeMeta and ObjectMeta.
+type Object interface {
+	GetObjectKind() scheme.ObjectKind
+	GetObjectMeta() *ObjectMeta
+}
+
+// ListOptions is the query options to a standard REST list call.
+type ListOptions struct {
+	// Limit is the maximum number of items to return for a list call. If more
+	// items exist, the server sets the continue field of the list metadata.
+	Limit int64 `json:"limit,omitempty"`
+
+	// Continue should be set when retrieving more results from the server,
+	// with the value returned by the previous page.
+	Continue string `json:"continue,omitempty"`
+
+	// LabelSelector restricts the list of returned objects by their labels,
+	// e.g. "env=prod,tier in (web,api)". Defaults to everything.
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// Watch for changes to the described resources and return them as a stream of
+	// add, update, and remove notifications.
+	Watch bool `json:"watch,omitempty"`
+
+	// ResourceVersion is the version after which a watch starts sending events.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// APIResource describes a resource served by the apiserver.
+type APIResource struct {
+	// Name is the plural name of the resource, used in the URL.
+	Name string `json:"name"`
+
+	// SingularName is the singular name of the resource.
+	SingularName string `json:"singularName"`
+
+	// Kind is the kind of the objects of the resource.
+	Kind string `json:"kind"`
+
+	// ShortNames is a list of suggested short names of the resource.
+	ShortNames []string `json:"shortNames,omitempty"`
+
+	// Verbs is a list of supported verbs (get, list, watch, create, update, delete).
+	Verbs []string `json:"verbs"`
+}
+
+// APIResourceList is the list of the resources served by a group version.
+type APIResourceList struct {
+	TypeMeta `json:",inline"`
+
+	// GroupVersion is the group and version of the resources.
+	GroupVersion string `json:"groupVersion"`
+
+	// APIResources contains the name of the resources.
+	APIResources []APIResource `json:"resources"`
+}
+
+// DryRunAll is the value of DryRun that runs every stage of a request
+// without persisting the result.
+const DryRunAll = "All"
+
+// CreateOptions may be provided when creating an object.
+type CreateOptions struct {
+	// DryRun, when set to All, validates the object and reports the result
+	// without persisting it.
+	DryRun []string `json:"dryRun,omitempty"`
+}
+
+// UpdateOptions may be provided when updating an object.
+type UpdateOptions struct {
+	// DryRun, when set to All, validates the object and reports the result
+	// without persisting it.
+	DryRun []string `json:"dryRun,omitempty"`
+}
+
+// DeleteOptions may be provided when deleting an object.
+type DeleteOptions struct {
+	// DryRun, when set to All, reports the object that would be deleted
+	// without deleting it.
+	DryRun []string `json:"dryRun,omitempty"`
+}
@@ -0,0 +1,22 @@
+package v1
+
+import "encoding/json"
+
+// Event types of a WatchEvent.
+const (
+	Added    = "ADDED"
+	Modified = "MODIFIED"
+	Deleted  = "DELETED"
+	Error    = "ERROR"
+)
+
+// WatchEvent is the wire format of a single watch notification. A watch
+// response is a stream of newline separated WatchEvents.
+type WatchEvent struct {
+	// Type is one of ADDED, MODIFIED, DELETED or ERROR.
+	Type string `json:"type"`
+
+	// Object is the object that changed. For DELETED it is the last state
+	// of the object, for ERROR a Status.
+	Object json.RawMessage `json:"object"`
+}
@@ -0,0 +1,65 @@
+// Package requestid carries the ID correlating a request across the client,
+// the apiserver and their logs.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the HTTP header carrying the request ID.
+const Header = "X-Request-ID"
+
+// maxLength is the longest request ID accepted from a client.
+const maxLength = 128
+
+type key int
+
+const requestIDKey key = iota
+
+// New returns a random request ID formatted as a UUID.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b[:])
+
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// IsValid returns true if id can be used as a request ID: it is not empty,
+// not too long and only contains printable ASCII characters, so that it is
+// safe to echo in headers and logs.
+func IsValid(id string) bool {
+	if len(id) == 0 || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithRequestID returns a copy of parent carrying id.
+func WithRequestID(parent context.Context, id string) context.Context {
+	return context.WithValue(parent, requestIDKey, id)
+}
+
+// FromContext returns the request ID carried by ctx.
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	id, ok := ctx.Value(requestIDKey).(string)
+
+	return id, ok && len(id) != 0
+}
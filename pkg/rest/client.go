@@ -5,12 +5,15 @@
 package rest
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/hanzhuoxian/flora/pkg/runtime"
 	"github.com/hanzhuoxian/flora/pkg/scheme"
-	"github.com/parnurzeal/gorequest"
 )
 
 // Interface captures the set of operations for generically interacting with IAM REST apis.
@@ -31,10 +34,15 @@ type ClientContentConfig struct {
 
 	SecretID  string
 	SecretKey string
-	// Server requires Bearer authentication. This client will not attempt to use
-	// refresh tokens for an OAuth2 flow.
-	// TODO: demonstrate an OAuth2 compatible client.
+	// Server requires Bearer authentication.
 	BearerToken string
+	// RefreshToken, if set, gets a new BearerToken from the apiserver when
+	// the token expires at TokenExpiry or is rejected.
+	RefreshToken string
+	TokenExpiry  time.Time
+	// ExecProvider, if set, runs a credential plugin to get the token or
+	// the secret id and key.
+	ExecProvider *ExecProvider
 
 	// Path to a file containing a BearerToken.
 	// If set, the contents are periodically read.
@@ -54,7 +62,34 @@ type ClientContentConfig struct {
 	Negotiator   runtime.ClientNegotiator
 }
 
-// HasBasicAuth returns whether the configuration has basic authentication or not.
+type sanitizedClientContentConfig *ClientContentConfig
+
+// GoString implements fmt.GoStringer and sanitizes sensitive fields of
+// ClientContentConfig to prevent accidental leaking via logs.
+func (c *ClientContentConfig) GoString() string {
+	return c.String()
+}
+
+// String implements fmt.Stringer and sanitizes sensitive fields of
+// ClientContentConfig to prevent accidental leaking via logs.
+func (c *ClientContentConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	copied := *c
+	cc := sanitizedClientContentConfig(&copied)
+	// Explicitly mark non-empty credential fields as redacted.
+	cc.Password = redact(cc.Password)
+	cc.BearerToken = redact(cc.BearerToken)
+	cc.RefreshToken = redact(cc.RefreshToken)
+	cc.SecretKey = redact(cc.SecretKey)
+
+	return fmt.Sprintf("%#v", cc)
+}
+
+// HasBasicAuth returns whether the configuration has a username, which is
+// exchanged with the password for a bearer token.
 func (c *ClientContentConfig) HasBasicAuth() bool {
 	return len(c.Username) != 0
 }
@@ -69,6 +104,12 @@ func (c *ClientContentConfig) HasKeyAuth() bool {
 	return len(c.SecretID) != 0 && len(c.SecretKey) != 0
 }
 
+// HasExecAuth returns whether the configuration gets its credentials from a
+// credential plugin.
+func (c *ClientContentConfig) HasExecAuth() bool {
+	return c.ExecProvider != nil
+}
+
 // TLSConfig holds the information needed to set up a TLS transport.
 type TLSConfig struct {
 	CAFile         string // Path of the PEM-encoded server trusted root certificates.
@@ -100,13 +141,36 @@ type RESTClient struct {
 	versionedAPIPath string
 	// content describes how a RESTClient encodes and decodes responses.
 	content ClientContentConfig
-	Client  *gorequest.SuperAgent
+	// maxRetries is the number of times a failed request is retried, see
+	// Request.retryAfter. retryInterval is the wait after a server side
+	// error.
+	maxRetries    int
+	retryInterval time.Duration
+	// rateLimiter, if set, throttles every attempt of a request.
+	rateLimiter RateLimiter
+	// auth, if set, supplies the credentials obtained by logging in with the
+	// username and password, by refreshing the bearer token or by running a
+	// credential plugin.
+	auth authenticator
+
+	Client *http.Client
+}
+
+// authenticator supplies the Authorization header of the requests of a
+// client, obtaining and renewing the credentials it needs.
+type authenticator interface {
+	// authorization returns the value of the Authorization header.
+	authorization(ctx context.Context) (string, error)
+	// invalidate drops the credentials sent in the Authorization header
+	// after the server rejected them. It returns whether new ones may be
+	// obtained.
+	invalidate(authorization string) bool
 }
 
 // NewRESTClient creates a new RESTClient. This client performs generic REST functions
 // such as Get, Put, Post, and Delete on specified paths.
 func NewRESTClient(baseURL *url.URL, versionedAPIPath string,
-	config ClientContentConfig, client *gorequest.SuperAgent) (*RESTClient, error) {
+	config ClientContentConfig, client *http.Client) (*RESTClient, error) {
 	if len(config.ContentType) == 0 {
 		config.ContentType = "application/json"
 	}
@@ -119,13 +183,26 @@ func NewRESTClient(baseURL *url.URL, versionedAPIPath string,
 	base.RawQuery = ""
 	base.Fragment = ""
 
-	return &RESTClient{
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := &RESTClient{
 		base:             &base,
 		group:            config.GroupVersion.Group,
 		versionedAPIPath: versionedAPIPath,
 		content:          config,
 		Client:           client,
-	}, nil
+	}
+
+	switch {
+	case config.HasExecAuth():
+		c.auth = newExecAuthenticator(c)
+	case config.HasBasicAuth() || len(config.RefreshToken) != 0:
+		c.auth = newTokenSource(c)
+	}
+
+	return c, nil
 }
 
 // Verb begins a Verb request.
@@ -5,6 +5,7 @@
 package rest
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -17,10 +18,13 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/time/rate"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/log"
 	"github.com/hanzhuoxian/flora/pkg/runtime"
 	"github.com/hanzhuoxian/flora/pkg/scheme"
 	"github.com/hanzhuoxian/flora/pkg/version"
-	"github.com/parnurzeal/gorequest"
 )
 
 // Config holds the common attributes that can be passed to a IAM client on
@@ -30,17 +34,26 @@ type Config struct {
 	APIPath string
 	ContentConfig
 
-	// Server requires Basic authentication
+	// Username and Password are exchanged for a bearer token by logging in
+	// to the apiserver.
 	Username string
 	Password string
 
 	SecretID  string
 	SecretKey string
 
-	// Server requires Bearer authentication. This client will not attempt to use
-	// refresh tokens for an OAuth2 flow.
-	// TODO: demonstrate an OAuth2 compatible client.
+	// Server requires Bearer authentication.
 	BearerToken string
+	// RefreshToken, if set, gets a new BearerToken from the apiserver when
+	// the token expires at TokenExpiry or is rejected.
+	RefreshToken string
+	TokenExpiry  time.Time
+	// TokenPersister, if set, is called with the tokens refreshed with
+	// RefreshToken, e.g. to save them in the client config file.
+	TokenPersister func(token *v1.Token) error
+	// ExecProvider, if set, runs a credential plugin to get the token or
+	// the secret id and key of the client.
+	ExecProvider *ExecProvider
 
 	// Path to a file containing a BearerToken.
 	// If set, the contents are periodically read.
@@ -56,6 +69,21 @@ type Config struct {
 	Timeout       time.Duration
 	MaxRetries    int
 	RetryInterval time.Duration
+
+	// QPS indicates the maximum QPS to the server from this client.
+	// If it's zero, requests are not rate limited.
+	QPS float32
+	// Maximum burst for throttle.
+	Burst int
+	// RateLimiter throttles the requests of the client. It takes precedence
+	// over QPS and Burst.
+	RateLimiter RateLimiter
+}
+
+// RateLimiter throttles the requests of a client.
+type RateLimiter interface {
+	// Wait blocks until the request is allowed to proceed or ctx is done.
+	Wait(ctx context.Context) error
 }
 
 // ContentConfig defines config for content.
@@ -84,19 +112,21 @@ func (c *Config) String() string {
 
 	cc := sanitizedConfig(CopyConfig(c))
 	// Explicitly mark non-empty credential fields as redacted.
-	if cc.Password != "" {
-		cc.Password = "--- REDACTED ---"
-	}
+	cc.Password = redact(cc.Password)
+	cc.BearerToken = redact(cc.BearerToken)
+	cc.RefreshToken = redact(cc.RefreshToken)
+	cc.SecretKey = redact(cc.SecretKey)
 
-	if cc.BearerToken != "" {
-		cc.BearerToken = "--- REDACTED ---"
-	}
+	return fmt.Sprintf("%#v", cc)
+}
 
-	if cc.SecretKey != "" {
-		cc.SecretKey = "--- REDACTED ---"
+// redact returns log.Redacted in place of a non-empty credential.
+func redact(s string) string {
+	if len(s) == 0 {
+		return s
 	}
 
-	return fmt.Sprintf("%#v", cc)
+	return log.Redacted
 }
 
 // TLSClientConfig contains settings to enable transport layer security.
@@ -167,7 +197,7 @@ func (c TLSClientConfig) String() string {
 	}
 
 	if len(cc.KeyData) != 0 {
-		cc.KeyData = []byte("--- REDACTED ---")
+		cc.KeyData = []byte(log.Redacted)
 	}
 
 	return fmt.Sprintf("%#v", cc)
@@ -207,11 +237,13 @@ func RESTClientFor(config *Config) (*RESTClient, error) {
 		return nil, err
 	}
 
-	// Only retry when get a server side error.
-	client := gorequest.New().TLSClientConfig(tlsConfig).Timeout(config.Timeout).
-		Retry(config.MaxRetries, config.RetryInterval, http.StatusInternalServerError)
-	// NOTICE: must set DoNotClearSuperAgent to true, or the client will clean header befor http.Do
-	client.DoNotClearSuperAgent = true
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: config.Timeout,
+	}
 
 	var gv scheme.GroupVersion
 	if config.GroupVersion != nil {
@@ -225,6 +257,9 @@ func RESTClientFor(config *Config) (*RESTClient, error) {
 		SecretKey:          config.SecretKey,
 		BearerToken:        config.BearerToken,
 		BearerTokenFile:    config.BearerTokenFile,
+		RefreshToken:       config.RefreshToken,
+		TokenExpiry:        config.TokenExpiry,
+		ExecProvider:       config.ExecProvider,
 		TLSClientConfig:    config.TLSClientConfig,
 		AcceptContentTypes: config.AcceptContentTypes,
 		ContentType:        config.ContentType,
@@ -232,7 +267,31 @@ func RESTClientFor(config *Config) (*RESTClient, error) {
 		Negotiator:         config.Negotiator,
 	}
 
-	return NewRESTClient(baseURL, versionedAPIPath, clientContent, client)
+	restClient, err := NewRESTClient(baseURL, versionedAPIPath, clientContent, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only retry when get a server side error.
+	restClient.maxRetries = config.MaxRetries
+	restClient.retryInterval = config.RetryInterval
+
+	restClient.rateLimiter = config.RateLimiter
+
+	if tokens, ok := restClient.auth.(*tokenSource); ok {
+		tokens.persist = config.TokenPersister
+	}
+
+	if restClient.rateLimiter == nil && config.QPS > 0 {
+		burst := config.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+
+		restClient.rateLimiter = rate.NewLimiter(rate.Limit(config.QPS), burst)
+	}
+
+	return restClient, nil
 }
 
 // TLSConfigFor returns a tls.Config that will provide the transport level security defined
@@ -440,6 +499,10 @@ func CopyConfig(config *Config) *Config {
 		SecretKey:       config.SecretKey,
 		BearerToken:     config.BearerToken,
 		BearerTokenFile: config.BearerTokenFile,
+		RefreshToken:    config.RefreshToken,
+		TokenExpiry:     config.TokenExpiry,
+		TokenPersister:  config.TokenPersister,
+		ExecProvider:    config.ExecProvider,
 		TLSClientConfig: TLSClientConfig{
 			Insecure:   config.TLSClientConfig.Insecure,
 			ServerName: config.TLSClientConfig.ServerName,
@@ -451,7 +514,34 @@ func CopyConfig(config *Config) *Config {
 			CAData:     config.TLSClientConfig.CAData,
 			NextProtos: config.TLSClientConfig.NextProtos,
 		},
-		UserAgent: config.UserAgent,
-		Timeout:   config.Timeout,
+		UserAgent:     config.UserAgent,
+		Timeout:       config.Timeout,
+		MaxRetries:    config.MaxRetries,
+		RetryInterval: config.RetryInterval,
+		QPS:           config.QPS,
+		Burst:         config.Burst,
+		RateLimiter:   config.RateLimiter,
 	}
 }
+
+// AnonymousClientConfig returns a copy of the given config with the
+// credentials removed, e.g. to log in.
+func AnonymousClientConfig(config *Config) *Config {
+	c := CopyConfig(config)
+	c.Username = ""
+	c.Password = ""
+	c.SecretID = ""
+	c.SecretKey = ""
+	c.BearerToken = ""
+	c.BearerTokenFile = ""
+	c.RefreshToken = ""
+	c.TokenExpiry = time.Time{}
+	c.TokenPersister = nil
+	c.ExecProvider = nil
+	c.CertFile = ""
+	c.KeyFile = ""
+	c.CertData = nil
+	c.KeyData = nil
+
+	return c
+}
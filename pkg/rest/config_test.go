@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConfigString(t *testing.T) {
+	c := &Config{
+		Host:            "https://flora.example.com",
+		Username:        "admin",
+		Password:        "password-value",
+		SecretID:        "secret-id",
+		SecretKey:       "secret-key-value",
+		BearerToken:     "bearer-token-value",
+		RefreshToken:    "refresh-token-value",
+		TLSClientConfig: TLSClientConfig{KeyData: []byte("key-data-value")},
+	}
+	content := ClientContentConfig{
+		Password:        c.Password,
+		SecretKey:       c.SecretKey,
+		BearerToken:     c.BearerToken,
+		RefreshToken:    c.RefreshToken,
+		TLSClientConfig: c.TLSClientConfig,
+	}
+
+	for _, format := range []string{"%s", "%v", "%+v", "%#v"} {
+		for _, v := range []interface{}{c, &content} {
+			s := fmt.Sprintf(format, v)
+			for _, secret := range []string{
+				"password-value", "secret-key-value", "bearer-token-value", "refresh-token-value", "key-data-value",
+			} {
+				if strings.Contains(s, secret) {
+					t.Errorf("Sprintf(%q, %T) leaks %s: %s", format, v, secret, s)
+				}
+			}
+		}
+	}
+
+	if !strings.Contains(c.String(), "secret-id") {
+		t.Errorf("String() = %s, want non-sensitive fields kept", c.String())
+	}
+
+	if c.Password != "password-value" {
+		t.Errorf("String() modified the config")
+	}
+}
@@ -3,5 +3,5 @@
 // license that can be found in the LICENSE file.
 
 // Package rest can used to deal with restful request.
-// Use gorequest as the http engine.
+// Use net/http as the http engine.
 package rest
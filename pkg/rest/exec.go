@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+)
+
+// ExecInfoEnv is the environment variable holding the v1.ExecCredential
+// passed to the credential plugins.
+const ExecInfoEnv = "FLORA_EXEC_INFO"
+
+// execCredentialKind is the kind of the v1.ExecCredential exchanged with the
+// credential plugins.
+const execCredentialKind = "ExecCredential"
+
+// ExecProvider runs an external command, a credential plugin, to get the
+// credentials of a client, keeping the secrets out of the config files. The
+// plugin prints a v1.ExecCredential with its status set on its standard
+// output, its standard error is the one of the client, e.g. to prompt the
+// user.
+type ExecProvider struct {
+	// Command is the path of the plugin, or its name looked up in the PATH.
+	Command string
+	Args    []string
+	// Env holds NAME=value variables added to the environment of the
+	// plugin.
+	Env []string
+}
+
+// execAuthenticator runs the credential plugin of a client and caches the
+// credentials it returns until they expire or are rejected.
+type execAuthenticator struct {
+	provider ExecProvider
+	// server is passed to the plugin, group signs the secret id and key.
+	server string
+	group  string
+	stderr io.Writer
+
+	lock       sync.Mutex
+	credential *v1.ExecCredentialStatus
+}
+
+// newExecAuthenticator returns the execAuthenticator of c.
+func newExecAuthenticator(c *RESTClient) *execAuthenticator {
+	return &execAuthenticator{
+		provider: *c.content.ExecProvider,
+		server:   c.base.String(),
+		group:    c.group,
+		stderr:   os.Stderr,
+	}
+}
+
+// authorization returns the Authorization header of the credentials,
+// running the plugin when there are none or they are about to expire.
+func (a *execAuthenticator) authorization(ctx context.Context) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.credential == nil || a.credential.ExpirationTimestamp != nil &&
+		time.Until(*a.credential.ExpirationTimestamp) <= tokenExpiryDelta {
+		credential, err := a.run(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		a.credential = credential
+	}
+
+	if len(a.credential.Token) != 0 {
+		return "Bearer " + a.credential.Token, nil
+	}
+
+	return "Bearer " + signKey(a.group, a.credential.SecretID, a.credential.SecretKey), nil
+}
+
+// invalidate drops the credentials the server rejected, the plugin is run
+// again for the next request.
+func (a *execAuthenticator) invalidate(authorization string) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	// A new token may already replace the rejected one, the signed secret
+	// changes with every request.
+	if a.credential != nil && (len(a.credential.Token) == 0 || "Bearer "+a.credential.Token == authorization) {
+		a.credential = nil
+	}
+
+	return true
+}
+
+// run runs the plugin and returns the credentials it printed.
+func (a *execAuthenticator) run(ctx context.Context) (*v1.ExecCredentialStatus, error) {
+	info, err := json.Marshal(v1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{Kind: execCredentialKind, APIVersion: v1.SchemeGroupVersion.String()},
+		Spec:     v1.ExecCredentialSpec{Server: a.server},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, a.provider.Command, a.provider.Args...)
+	cmd.Env = append(append(os.Environ(), a.provider.Env...), ExecInfoEnv+"="+string(info))
+	cmd.Stdout = &stdout
+	cmd.Stderr = a.stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("credential plugin %q not found, install it or fix the exec command of the user",
+				a.provider.Command)
+		}
+
+		return nil, fmt.Errorf("credential plugin %q failed: %w", a.provider.Command, err)
+	}
+
+	var credential v1.ExecCredential
+	if err := json.Unmarshal(stdout.Bytes(), &credential); err != nil {
+		return nil, fmt.Errorf("unable to decode the output of the credential plugin %q: %w", a.provider.Command, err)
+	}
+
+	status := credential.Status
+
+	switch {
+	case credential.Kind != execCredentialKind:
+		err = fmt.Errorf("kind %q, want %s", credential.Kind, execCredentialKind)
+	case status == nil:
+		err = errors.New("no status")
+	case len(status.Token) != 0 && len(status.SecretID) != 0:
+		err = errors.New("both a token and a secret id")
+	case len(status.Token) == 0 && (len(status.SecretID) == 0 || len(status.SecretKey) == 0):
+		err = errors.New("neither a token nor a secret id and key")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("credential plugin %q returned %w", a.provider.Command, err)
+	}
+
+	return status, nil
+}
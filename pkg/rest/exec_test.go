@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	gruntime "runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hanzhuoxian/flora/pkg/runtime"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// execPlugin prints the token token-N on its Nth run, followed by the
+// STATUS variable, and saves its FLORA_EXEC_INFO variable.
+const execPlugin = `#!/bin/sh
+n=$(($(cat "$DIR/runs" 2>/dev/null || echo 0) + 1))
+echo $n > "$DIR/runs"
+echo "$FLORA_EXEC_INFO" > "$DIR/info"
+printf '{"kind": "ExecCredential", "apiVersion": "apiserver/v1", "status": {"token": "token-%d"%s}}' $n "$STATUS"
+`
+
+func TestExecProvider(t *testing.T) {
+	if gruntime.GOOS == "windows" {
+		t.Skip("the credential plugin is a shell script")
+	}
+
+	soon := time.Now().Add(time.Second).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		status  string
+		accept  string
+		runs    string
+		wantErr bool
+	}{
+		{name: "cached", accept: "Bearer token-1", runs: "1"},
+		{name: "expiring", status: `, "expirationTimestamp": "` + soon + `"`, accept: "Bearer token-", runs: "2"},
+		{name: "rejected", accept: "Bearer token-2", runs: "2"},
+		{
+			name:   "secret key",
+			status: `, "token": "", "secretID": "id", "secretKey": "key"`,
+			accept: "Bearer ey",
+			runs:   "1",
+		},
+		{name: "no credentials", status: `, "token": ""`, runs: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+
+		plugin := filepath.Join(dir, "plugin")
+		if err := os.WriteFile(plugin, []byte(execPlugin), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(tt.accept) == 0 || !strings.HasPrefix(r.Header.Get("Authorization"), tt.accept) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{}`))
+		}))
+
+		c, err := RESTClientFor(&Config{
+			Host:    ts.URL,
+			APIPath: "/",
+			ContentConfig: ContentConfig{
+				GroupVersion: &scheme.GroupVersion{Version: "v1"},
+				Negotiator:   runtime.NewSimpleClientNegotiator(),
+			},
+			ExecProvider: &ExecProvider{Command: plugin, Env: []string{"DIR=" + dir, "STATUS=" + tt.status}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := c.Get().Resource("leases").Do(context.Background()).Error(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: request %d error = %v, want error %v", tt.name, i, err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				break
+			}
+		}
+
+		ts.Close()
+
+		if runs, _ := os.ReadFile(filepath.Join(dir, "runs")); strings.TrimSpace(string(runs)) != tt.runs {
+			t.Errorf("%s: plugin runs = %q, want %s", tt.name, runs, tt.runs)
+		}
+
+		if info, _ := os.ReadFile(filepath.Join(dir, "info")); !strings.Contains(string(info), `"server":"`+ts.URL) {
+			t.Errorf("%s: %s = %s, want the server %s", tt.name, ExecInfoEnv, info, ts.URL)
+		}
+	}
+}
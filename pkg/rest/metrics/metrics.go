@@ -0,0 +1,83 @@
+// Package metrics provides the hooks pkg/rest reports client side metrics
+// through. They do nothing until an implementation is registered, e.g. the
+// one in the prometheus sub package.
+package metrics
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LatencyMetric observes the latency of a request.
+type LatencyMetric interface {
+	Observe(ctx context.Context, verb string, u url.URL, latency time.Duration)
+}
+
+// ResultMetric counts the responses by status code.
+type ResultMetric interface {
+	Increment(ctx context.Context, code string, method string, host string)
+}
+
+// RetryMetric counts the retried requests by the status code that caused
+// the retry.
+type RetryMetric interface {
+	IncrementRetry(ctx context.Context, code string, method string, host string)
+}
+
+var (
+	// RequestLatency is the latency metric the rest client reports to.
+	RequestLatency LatencyMetric = noopLatency{}
+	// RateLimiterLatency is the time a request waited for the client side
+	// rate limiter.
+	RateLimiterLatency LatencyMetric = noopLatency{}
+	// RequestResult is the result metric the rest client reports to.
+	RequestResult ResultMetric = noopResult{}
+	// RequestRetry is the retry metric the rest client reports to.
+	RequestRetry RetryMetric = noopRetry{}
+
+	registerOnce sync.Once
+)
+
+// RegisterOpts contains the metrics to register. Nil fields are left unset.
+type RegisterOpts struct {
+	RequestLatency     LatencyMetric
+	RateLimiterLatency LatencyMetric
+	RequestResult      ResultMetric
+	RequestRetry       RetryMetric
+}
+
+// Register registers the metrics of the rest client. Only the first call has
+// an effect.
+func Register(opts RegisterOpts) {
+	registerOnce.Do(func() {
+		if opts.RequestLatency != nil {
+			RequestLatency = opts.RequestLatency
+		}
+
+		if opts.RateLimiterLatency != nil {
+			RateLimiterLatency = opts.RateLimiterLatency
+		}
+
+		if opts.RequestResult != nil {
+			RequestResult = opts.RequestResult
+		}
+
+		if opts.RequestRetry != nil {
+			RequestRetry = opts.RequestRetry
+		}
+	})
+}
+
+type noopLatency struct{}
+
+func (noopLatency) Observe(context.Context, string, url.URL, time.Duration) {}
+
+type noopResult struct{}
+
+func (noopResult) Increment(context.Context, string, string, string) {}
+
+type noopRetry struct{}
+
+func (noopRetry) IncrementRetry(context.Context, string, string, string) {}
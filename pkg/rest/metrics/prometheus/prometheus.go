@@ -0,0 +1,93 @@
+// Package prometheus exports the metrics of pkg/rest clients to Prometheus.
+//
+//	prometheus.Register(registry)
+package prometheus
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/hanzhuoxian/flora/pkg/rest/metrics"
+)
+
+var (
+	requestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rest_client_request_duration_seconds",
+			Help:    "Request latency in seconds. Broken down by verb and host.",
+			Buckets: []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1.0, 2.0, 4.0, 8.0, 15.0, 30.0, 60.0},
+		},
+		[]string{"verb", "host"},
+	)
+
+	rateLimiterLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rest_client_rate_limiter_duration_seconds",
+			Help:    "Client side rate limiter latency in seconds. Broken down by verb and host.",
+			Buckets: []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1.0, 2.0, 4.0, 8.0, 15.0, 30.0, 60.0},
+		},
+		[]string{"verb", "host"},
+	)
+
+	requestResult = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rest_client_requests_total",
+			Help: "Number of HTTP requests, partitioned by status code, method, and host.",
+		},
+		[]string{"code", "method", "host"},
+	)
+
+	requestRetry = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rest_client_request_retries_total",
+			Help: "Number of request retries, partitioned by status code, method, and host.",
+		},
+		[]string{"code", "method", "host"},
+	)
+)
+
+// Register registers the rest client metrics in registerer and makes the
+// rest clients report to them.
+func Register(registerer prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{requestLatency, rateLimiterLatency, requestResult, requestRetry} {
+		if err := registerer.Register(c); err != nil {
+			return err
+		}
+	}
+
+	metrics.Register(metrics.RegisterOpts{
+		RequestLatency:     &latencyAdapter{m: requestLatency},
+		RateLimiterLatency: &latencyAdapter{m: rateLimiterLatency},
+		RequestResult:      &resultAdapter{m: requestResult},
+		RequestRetry:       &retryAdapter{m: requestRetry},
+	})
+
+	return nil
+}
+
+type latencyAdapter struct {
+	m *prometheus.HistogramVec
+}
+
+func (l *latencyAdapter) Observe(ctx context.Context, verb string, u url.URL, latency time.Duration) {
+	l.m.WithLabelValues(verb, u.Host).Observe(latency.Seconds())
+}
+
+type resultAdapter struct {
+	m *prometheus.CounterVec
+}
+
+func (r *resultAdapter) Increment(ctx context.Context, code, method, host string) {
+	r.m.WithLabelValues(code, method, host).Inc()
+}
+
+type retryAdapter struct {
+	m *prometheus.CounterVec
+}
+
+func (r *retryAdapter) IncrementRetry(ctx context.Context, code, method, host string) {
+	r.m.WithLabelValues(code, method, host).Inc()
+}
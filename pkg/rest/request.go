@@ -5,20 +5,25 @@
 package rest
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
 	"github.com/hanzhuoxian/flora/pkg/auth"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+	"github.com/hanzhuoxian/flora/pkg/rest/metrics"
 	"github.com/hanzhuoxian/flora/pkg/runtime"
-	"github.com/parnurzeal/gorequest"
+	"github.com/hanzhuoxian/flora/pkg/trace"
 )
 
 // Request allows for building up a request to a server in a chained fashion.
@@ -64,7 +69,9 @@ func NewRequest(c *RESTClient) *Request {
 
 	authMethod := 0
 
-	for _, fn := range []func() bool{c.content.HasBasicAuth, c.content.HasTokenAuth, c.content.HasKeyAuth} {
+	for _, fn := range []func() bool{
+		c.content.HasBasicAuth, c.content.HasTokenAuth, c.content.HasKeyAuth, c.content.HasExecAuth,
+	} {
 		if fn() {
 			authMethod++
 		}
@@ -72,21 +79,20 @@ func NewRequest(c *RESTClient) *Request {
 
 	if authMethod > 1 {
 		r.err = fmt.Errorf(
-			"username/password or bearer token or secretID/secretKey may be set, but should use only one of them",
+			"username/password or bearer token or secretID/secretKey or exec provider may be set, but should use only one of them",
 		)
 
 		return r
 	}
 
 	switch {
+	case c.auth != nil:
+		// The credentials are set when the request is sent, they may have
+		// to be obtained or renewed first.
 	case c.content.HasTokenAuth():
 		r.SetHeader("Authorization", fmt.Sprintf("Bearer %s", c.content.BearerToken))
 	case c.content.HasKeyAuth():
-		tokenString := auth.Sign(c.content.SecretID, c.content.SecretKey, "marmotedu-sdk-go", c.group+".marmotedu.com")
-		r.SetHeader("Authorization", fmt.Sprintf("Bearer %s", tokenString))
-	case c.content.HasBasicAuth():
-		// TODO: get token and set header
-		r.SetHeader("Authorization", "Basic "+basicAuth(c.content.Username, c.content.Password))
+		r.SetHeader("Authorization", fmt.Sprintf("Bearer %s", signKey(c.group, c.content.SecretID, c.content.SecretKey)))
 	}
 
 	// set accept content
@@ -100,14 +106,9 @@ func NewRequest(c *RESTClient) *Request {
 	return r
 }
 
-func basicAuth(username, password string) string {
-	auth := username + ":" + password
-	return base64.StdEncoding.EncodeToString([]byte(auth))
-}
-
 // NewRequestWithClient creates a Request with an embedded RESTClient for use in test scenarios.
 func NewRequestWithClient(base *url.URL, versionedAPIPath string,
-	content ClientContentConfig, client *gorequest.SuperAgent) *Request {
+	content ClientContentConfig, client *http.Client) *Request {
 	return NewRequest(&RESTClient{
 		base:             base,
 		versionedAPIPath: versionedAPIPath,
@@ -284,11 +285,62 @@ func (r *Request) VersionedParams(v interface{}) *Request {
 		return r
 	}
 
-	r.c.Client.Query(v)
+	params, err := queryParams(v)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	for k, values := range params {
+		for _, value := range values {
+			r.setParam(k, value)
+		}
+	}
 
 	return r
 }
 
+// queryParams converts a query string, url.Values or a struct, whose json
+// field names become the parameter names, into url.Values.
+func queryParams(v interface{}) (url.Values, error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case url.Values:
+		return t, nil
+	case string:
+		return url.ParseQuery(t)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("query parameters must be a struct or a map: %w", err)
+	}
+
+	params := url.Values{}
+
+	for k, value := range fields {
+		switch t := value.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range t {
+				params.Add(k, fmt.Sprint(item))
+			}
+		case float64:
+			params.Add(k, strconv.FormatFloat(t, 'f', -1, 64))
+		default:
+			params.Add(k, fmt.Sprint(t))
+		}
+	}
+
+	return params, nil
+}
+
 func (r *Request) setParam(paramName, value string) *Request {
 	if r.params == nil {
 		r.params = make(url.Values)
@@ -376,8 +428,9 @@ func (r *Request) Body(obj interface{}) *Request {
 
 // Do formats and executes the request. Returns a Result object for easy response processing.
 func (r *Request) Do(ctx context.Context) Result {
-	client := r.c.Client
-	client.Header = r.headers
+	if r.err != nil {
+		return Result{err: r.err}
+	}
 
 	if r.timeout > 0 {
 		var cancel context.CancelFunc
@@ -386,40 +439,246 @@ func (r *Request) Do(ctx context.Context) Result {
 		defer cancel()
 	}
 
-	client.WithContext(ctx)
+	data, err := r.encodeBody()
+	if err != nil {
+		return Result{err: err}
+	}
+
+	if _, ok := requestid.FromContext(ctx); !ok {
+		ctx = requestid.WithRequestID(ctx, requestid.New())
+	}
+
+	ctx, span := trace.Start(ctx, "HTTP "+r.verb, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(trace.String("http.method", r.verb), trace.String("http.url", r.URL().String())))
+	defer span.End()
+
+	result := r.do(ctx, data)
+	span.SetAttributes(trace.Int("http.status_code", result.statusCode))
+	span.RecordError(result.err)
+
+	return result
+}
+
+// do sends the request, retrying it when retryAfter allows it. A request
+// whose token is rejected is sent once more with a new token.
+func (r *Request) do(ctx context.Context, data []byte) Result {
+	reauthenticated := false
+
+	for retries := 0; ; retries++ {
+		if err := r.tryThrottle(ctx); err != nil {
+			return Result{err: err}
+		}
+
+		req, err := r.newHTTPRequest(ctx, data)
+		if err != nil {
+			return Result{err: err}
+		}
+
+		start := time.Now()
+		resp, err := r.c.Client.Do(req)
+		metrics.RequestLatency.Observe(ctx, r.verb, *req.URL, time.Since(start))
+
+		if err != nil {
+			metrics.RequestResult.Increment(ctx, "<error>", r.verb, req.URL.Host)
+
+			return Result{err: err}
+		}
+
+		code := strconv.Itoa(resp.StatusCode)
+		metrics.RequestResult.Increment(ctx, code, r.verb, req.URL.Host)
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return Result{statusCode: resp.StatusCode, err: err}
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && r.c.auth != nil && !reauthenticated &&
+			r.c.auth.invalidate(req.Header.Get("Authorization")) {
+			reauthenticated = true
+			retries--
+
+			continue
+		}
+
+		if wait, ok := r.retryAfter(resp); ok && retries < r.c.maxRetries {
+			metrics.RequestRetry.IncrementRetry(ctx, code, r.verb, req.URL.Host)
+
+			if err := sleepWithContext(ctx, wait); err != nil {
+				return Result{err: err}
+			}
+
+			continue
+		}
+
+		return r.transformResponse(resp, body)
+	}
+}
+
+// idempotentVerbs are the verbs whose requests may be sent again after a
+// server side error: a POST may have created its resource before failing.
+var idempotentVerbs = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// retryAfter returns whether the request may be retried after resp, and how
+// long to wait first. The requests of any verb are retried when the server
+// asks for it with the Retry-After header of a 429 or a 503, it did not
+// process them. Only the idempotent ones are retried after a 500.
+func (r *Request) retryAfter(resp *http.Response) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	case http.StatusInternalServerError:
+		if idempotentVerbs[r.verb] {
+			return r.c.retryInterval, true
+		}
+	}
+
+	return 0, false
+}
+
+// newHTTPRequest returns the HTTP request to send with data as body.
+func (r *Request) newHTTPRequest(ctx context.Context, data []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, r.verb, r.URL().String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = r.headers.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	if r.c.auth != nil {
+		authorization, err := r.c.auth.authorization(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", authorization)
+	}
+
+	trace.Inject(ctx, req.Header)
+
+	if id, ok := requestid.FromContext(ctx); ok && len(req.Header.Get(requestid.Header)) == 0 {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	if len(data) > 0 && len(req.Header.Get("Content-Type")) == 0 {
+		req.Header.Set("Content-Type", r.c.content.ContentType)
+	}
+
+	return req, nil
+}
+
+// signKey returns the bearer token signed with the secret id and key of a
+// client of group.
+func signKey(group, secretID, secretKey string) string {
+	return auth.Sign(secretID, secretKey, "marmotedu-sdk-go", group+".marmotedu.com")
+}
+
+// tryThrottle waits for the rate limiter of the client, if any.
+func (r *Request) tryThrottle(ctx context.Context) error {
+	if r.c.rateLimiter == nil {
+		return nil
+	}
+
+	start := time.Now()
+	err := r.c.rateLimiter.Wait(ctx)
+	metrics.RateLimiterLatency.Observe(ctx, r.verb, *r.URL(), time.Since(start))
+
+	return err
+}
+
+// encodeBody serializes the request body. Strings and byte slices are sent
+// unmodified, everything else is encoded with the negotiated encoder.
+func (r *Request) encodeBody() ([]byte, error) {
+	switch t := r.body.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return t, nil
+	case string:
+		return []byte(t), nil
+	case io.Reader:
+		return io.ReadAll(t)
+	}
+
+	if r.c.content.Negotiator == nil {
+		return json.Marshal(r.body)
+	}
+
+	encoder, err := r.c.content.Negotiator.Encoder()
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Encode(r.body)
+}
+
+func (r *Request) transformResponse(resp *http.Response, body []byte) Result {
+	if resp.StatusCode < http.StatusOK || resp.StatusCode > http.StatusPartialContent {
+		err := apierrors.FromResponse(resp.StatusCode, body)
+
+		err.RequestID = resp.Header.Get(requestid.Header)
+		if len(err.RequestID) == 0 {
+			err.RequestID = resp.Request.Header.Get(requestid.Header)
+		}
 
-	resp, body, errs := client.CustomMethod(r.verb, r.URL().String()).Send(r.body).EndBytes()
-	if err := combineErr(resp, body, errs); err != nil {
 		return Result{
-			response: &resp,
-			err:      err,
-			body:     body,
+			statusCode: resp.StatusCode,
+			err:        err,
+			body:       body,
 		}
 	}
 
+	if r.c.content.Negotiator == nil {
+		return Result{statusCode: resp.StatusCode, body: body}
+	}
+
 	decoder, err := r.c.content.Negotiator.Decoder()
 	if err != nil {
 		return Result{
-			response: &resp,
-			err:      err,
-			body:     body,
-			decoder:  decoder,
+			statusCode: resp.StatusCode,
+			err:        err,
+			body:       body,
+			decoder:    decoder,
 		}
 	}
 
 	return Result{
-		response: &resp,
-		body:     body,
-		decoder:  decoder,
+		statusCode: resp.StatusCode,
+		body:       body,
+		decoder:    decoder,
+	}
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
 }
 
 // Result contains the result of calling Request.Do().
 type Result struct {
-	response *gorequest.Response
-	err      error
-	body     []byte
-	decoder  runtime.Decoder
+	statusCode int
+	err        error
+	body       []byte
+	decoder    runtime.Decoder
 }
 
 // Raw returns the raw result.
@@ -427,17 +686,26 @@ func (r Result) Raw() ([]byte, error) {
 	return r.body, r.err
 }
 
+// StatusCode returns the HTTP status code of the response, or 0 if no response was received.
+func (r Result) StatusCode() int {
+	return r.statusCode
+}
+
 // Into stores the result into obj, if possible. If obj is nil it is ignored.
 func (r Result) Into(v interface{}) error {
 	if r.err != nil {
 		return r.Error()
 	}
 
+	if v == nil {
+		return nil
+	}
+
 	if r.decoder == nil {
 		return fmt.Errorf("serializer doesn't exist")
 	}
 
-	if err := r.decoder.Decode(r.body, &v); err != nil {
+	if err := r.decoder.Decode(r.body, v); err != nil {
 		return err
 	}
 
@@ -449,25 +717,6 @@ func (r Result) Error() error {
 	return r.err
 }
 
-func combineErr(resp gorequest.Response, body []byte, errs []error) error {
-	var e, sep string
-
-	if len(errs) > 0 {
-		for _, err := range errs {
-			e = sep + err.Error()
-			sep = "\n"
-		}
-
-		return errors.New(e)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return errors.New(string(body))
-	}
-
-	return nil
-}
-
 // NameMayNotBe specifies strings that cannot be used as names specified as
 // path segments (like the REST API or etcd store).
 var NameMayNotBe = []string{".", ".."}
@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+	"github.com/hanzhuoxian/flora/pkg/rest/metrics"
+)
+
+type fakeResultMetric struct {
+	lock    sync.Mutex
+	results []string
+	retries []string
+}
+
+func (m *fakeResultMetric) Increment(ctx context.Context, code, method, host string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.results = append(m.results, method+" "+code)
+}
+
+func (m *fakeResultMetric) IncrementRetry(ctx context.Context, code, method, host string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.retries = append(m.retries, method+" "+code)
+}
+
+type fakeRateLimiter struct {
+	waits int
+}
+
+func (l *fakeRateLimiter) Wait(ctx context.Context) error {
+	l.waits++
+
+	return nil
+}
+
+func TestRequestRetriesAndMetrics(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	m := &fakeResultMetric{}
+	metrics.Register(metrics.RegisterOpts{RequestResult: m, RequestRetry: m})
+
+	base, _ := url.Parse(server.URL)
+
+	c, err := NewRESTClient(base, "v1", ClientContentConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limiter := &fakeRateLimiter{}
+	c.maxRetries = 1
+	c.retryInterval = time.Millisecond
+	c.rateLimiter = limiter
+
+	result := c.Get().Resource("leases").Do(context.Background())
+	if err := result.Error(); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("server received %d requests, want 2", calls)
+	}
+
+	if limiter.waits != 2 {
+		t.Errorf("rate limiter waited %d times, want 2", limiter.waits)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if want := []string{"GET 500", "GET 200"}; len(m.results) != 2 || m.results[0] != want[0] || m.results[1] != want[1] {
+		t.Errorf("results = %v, want %v", m.results, want)
+	}
+
+	if len(m.retries) != 1 || m.retries[0] != "GET 500" {
+		t.Errorf("retries = %v, want [GET 500]", m.retries)
+	}
+}
+
+func TestRequestRetryVerbs(t *testing.T) {
+	tests := []struct {
+		name       string
+		verb       string
+		code       int
+		retryAfter string
+		want       int
+	}{
+		{name: "GET after a server error", verb: http.MethodGet, code: http.StatusInternalServerError, want: 2},
+		{name: "DELETE after a server error", verb: http.MethodDelete, code: http.StatusInternalServerError, want: 2},
+		{name: "POST after a server error", verb: http.MethodPost, code: http.StatusInternalServerError, want: 1},
+		{name: "POST unavailable", verb: http.MethodPost, code: http.StatusServiceUnavailable, retryAfter: "0", want: 2},
+		{name: "POST throttled", verb: http.MethodPost, code: http.StatusTooManyRequests, retryAfter: "0", want: 2},
+		{name: "GET unavailable without Retry-After", verb: http.MethodGet, code: http.StatusServiceUnavailable, want: 1},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				if len(tt.retryAfter) != 0 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+
+				w.WriteHeader(tt.code)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{}`))
+		}))
+
+		base, _ := url.Parse(server.URL)
+
+		c, err := NewRESTClient(base, "v1", ClientContentConfig{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.maxRetries = 1
+		c.retryInterval = time.Millisecond
+
+		_ = c.Verb(tt.verb).Resource("leases").Do(context.Background())
+
+		server.Close()
+
+		if calls != tt.want {
+			t.Errorf("%s: server received %d requests, want %d", tt.name, calls, tt.want)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var received []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(requestid.Header))
+		w.Header().Set(requestid.Header, r.Header.Get(requestid.Header))
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":"Failure","message":"leases \"a\" not found","reason":"NotFound","code":404}`))
+	}))
+	defer server.Close()
+
+	base, _ := url.Parse(server.URL)
+
+	c, err := NewRESTClient(base, "v1", ClientContentConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Get().Resource("leases").Name("a").Do(context.Background()).Error()
+	if len(received) != 1 || !requestid.IsValid(received[0]) {
+		t.Fatalf("server received request IDs %q, want one valid ID", received)
+	}
+
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.RequestID != received[0] {
+		t.Fatalf("Do() error = %#v, want a StatusError with request ID %q", err, received[0])
+	}
+
+	if !strings.Contains(err.Error(), received[0]) {
+		t.Errorf("error %q does not mention the request ID", err)
+	}
+
+	ctx := requestid.WithRequestID(context.Background(), "caller-id")
+	_ = c.Get().Resource("leases").Name("a").Do(ctx)
+
+	if received[1] != "caller-id" {
+		t.Errorf("request ID = %q, want the one of the context", received[1])
+	}
+}
@@ -0,0 +1,170 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	"github.com/hanzhuoxian/flora/pkg/log"
+)
+
+// Paths of the apiserver endpoints issuing tokens.
+const (
+	loginPath   = "/login"
+	refreshPath = "/login/refresh"
+)
+
+// tokenExpiryDelta is how long before its expiry a token is renewed, so
+// that it does not expire in flight.
+const tokenExpiryDelta = 10 * time.Second
+
+// Login exchanges the username and the password of a user for a token. The
+// client must not carry credentials, see AnonymousClientConfig.
+func Login(ctx context.Context, c *RESTClient, username, password string) (*v1.Token, error) {
+	return requestToken(ctx, c, loginPath, v1.LoginRequest{Username: username, Password: password})
+}
+
+// RefreshToken exchanges a refresh token for a new token. The client must
+// not carry credentials, see AnonymousClientConfig.
+func RefreshToken(ctx context.Context, c *RESTClient, refreshToken string) (*v1.Token, error) {
+	return requestToken(ctx, c, refreshPath, v1.RefreshRequest{RefreshToken: refreshToken})
+}
+
+func requestToken(ctx context.Context, c *RESTClient, path string, body interface{}) (*v1.Token, error) {
+	data, err := c.Post().AbsPath(path).Body(body).Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &v1.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("unable to decode the token: %w", err)
+	}
+
+	if len(token.AccessToken) == 0 {
+		return nil, fmt.Errorf("the server returned no token")
+	}
+
+	return token, nil
+}
+
+// tokenSource supplies the bearer tokens of a client. It logs in with the
+// username and the password of the client, or refreshes its bearer token,
+// and renews the token when it expires.
+type tokenSource struct {
+	// client sends the login and refresh requests, without credentials.
+	client *RESTClient
+
+	username string
+	password string
+
+	// persist, if set, saves the tokens obtained with a refresh token.
+	persist func(token *v1.Token) error
+
+	lock  sync.Mutex
+	token v1.Token
+}
+
+// newTokenSource returns the tokenSource of c, starting with its bearer
+// token and refresh token, if any.
+func newTokenSource(c *RESTClient) *tokenSource {
+	content := c.content
+	content.Username = ""
+	content.Password = ""
+	content.BearerToken = ""
+	content.RefreshToken = ""
+
+	return &tokenSource{
+		client: &RESTClient{
+			base:             c.base,
+			group:            c.group,
+			versionedAPIPath: c.versionedAPIPath,
+			content:          content,
+			Client:           c.Client,
+		},
+		username: c.content.Username,
+		password: c.content.Password,
+		token: v1.Token{
+			AccessToken:  c.content.BearerToken,
+			ExpiresAt:    c.content.TokenExpiry,
+			RefreshToken: c.content.RefreshToken,
+		},
+	}
+}
+
+// Token returns a valid token, renewing the current one when it is about to
+// expire.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.token.AccessToken) != 0 &&
+		(s.token.ExpiresAt.IsZero() || time.Until(s.token.ExpiresAt) > tokenExpiryDelta) {
+		return s.token.AccessToken, nil
+	}
+
+	return s.renew(ctx)
+}
+
+// authorization implements authenticator.
+func (s *tokenSource) authorization(ctx context.Context) (string, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return "Bearer " + token, nil
+}
+
+// invalidate implements authenticator, it drops the token after the server
+// rejected it.
+func (s *tokenSource) invalidate(authorization string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if "Bearer "+s.token.AccessToken == authorization {
+		s.token.AccessToken = ""
+	}
+
+	return len(s.token.RefreshToken) != 0 || len(s.username) != 0
+}
+
+// renew gets a new token with the refresh token, or by logging in when
+// there is no refresh token or it was rejected.
+func (s *tokenSource) renew(ctx context.Context) (string, error) {
+	var (
+		token *v1.Token
+		err   error
+	)
+
+	if len(s.token.RefreshToken) != 0 {
+		token, err = RefreshToken(ctx, s.client, s.token.RefreshToken)
+
+		switch {
+		case err == nil:
+			if s.persist != nil {
+				if err := s.persist(token); err != nil {
+					log.Warnf("Unable to save the refreshed token: %v", err)
+				}
+			}
+		case apierrors.IsUnauthorized(err) && len(s.username) == 0:
+			return "", fmt.Errorf("the token has expired and can't be refreshed, log in again: %w", err)
+		case !apierrors.IsUnauthorized(err):
+			return "", fmt.Errorf("unable to refresh the token: %w", err)
+		}
+	}
+
+	if token == nil {
+		if token, err = Login(ctx, s.client, s.username, s.password); err != nil {
+			return "", fmt.Errorf("unable to log in as %q: %w", s.username, err)
+		}
+	}
+
+	s.token = *token
+
+	return token.AccessToken, nil
+}
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/hanzhuoxian/flora/pkg/api/apiserver/v1"
+	"github.com/hanzhuoxian/flora/pkg/runtime"
+	"github.com/hanzhuoxian/flora/pkg/scheme"
+)
+
+// fakeTokenServer issues the tokens token-1, token-2... and accepts the
+// last one only.
+type fakeTokenServer struct {
+	lock     sync.Mutex
+	issued   int
+	expiry   time.Duration
+	requests []string
+}
+
+func (s *fakeTokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests = append(s.requests, r.URL.Path)
+
+	switch r.URL.Path {
+	case "/login":
+		var req v1.LoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Username != "admin" ||
+			req.Password != "secret" || len(r.Header.Get("Authorization")) != 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	case "/login/refresh":
+		var req v1.RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken != "refresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	default:
+		if r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", s.issued) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+
+		return
+	}
+
+	s.issued++
+	_ = json.NewEncoder(w).Encode(v1.Token{
+		AccessToken:  fmt.Sprintf("token-%d", s.issued),
+		ExpiresAt:    time.Now().Add(s.expiry),
+		RefreshToken: "refresh",
+	})
+}
+
+func TestTokenSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		expiry  time.Duration
+		revoke  bool
+		want    []string
+		persist int
+	}{
+		{
+			name:   "login",
+			config: Config{Username: "admin", Password: "secret"},
+			expiry: time.Hour,
+			want:   []string{"/login", "/v1/leases", "/v1/leases"},
+		},
+		{
+			name:    "refresh once expired",
+			config:  Config{Username: "admin", Password: "secret"},
+			expiry:  time.Second,
+			want:    []string{"/login", "/v1/leases", "/login/refresh", "/v1/leases"},
+			persist: 1,
+		},
+		{
+			name:    "refresh",
+			config:  Config{BearerToken: "token-0", RefreshToken: "refresh", TokenExpiry: time.Now().Add(-time.Minute)},
+			expiry:  time.Hour,
+			want:    []string{"/login/refresh", "/v1/leases", "/v1/leases"},
+			persist: 1,
+		},
+		{
+			name:    "refresh once rejected",
+			config:  Config{BearerToken: "token-0", RefreshToken: "refresh", TokenExpiry: time.Now().Add(time.Hour)},
+			expiry:  time.Hour,
+			revoke:  true,
+			want:    []string{"/v1/leases", "/v1/leases", "/login/refresh", "/v1/leases"},
+			persist: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		server := &fakeTokenServer{expiry: tt.expiry}
+		ts := httptest.NewServer(server)
+
+		var persisted []*v1.Token
+
+		config := tt.config
+		config.Host = ts.URL
+		config.GroupVersion = &scheme.GroupVersion{Version: "v1"}
+		config.Negotiator = runtime.NewSimpleClientNegotiator()
+		config.TokenPersister = func(token *v1.Token) error {
+			persisted = append(persisted, token)
+			return nil
+		}
+
+		c, err := RESTClientFor(&config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if tt.revoke && i == 1 {
+				// The server no longer accepts token-0.
+				server.lock.Lock()
+				server.issued = 1
+				server.lock.Unlock()
+			}
+
+			if err := c.Get().Resource("leases").Do(context.Background()).Error(); err != nil {
+				t.Errorf("%s: request %d error = %v", tt.name, i, err)
+			}
+		}
+
+		ts.Close()
+
+		if fmt.Sprint(server.requests) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: requests = %v, want %v", tt.name, server.requests, tt.want)
+		}
+
+		if len(persisted) != tt.persist {
+			t.Errorf("%s: %d tokens persisted, want %d", tt.name, len(persisted), tt.persist)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	apierrors "github.com/hanzhuoxian/flora/pkg/api/errors"
+	metav1 "github.com/hanzhuoxian/flora/pkg/meta/v1"
+	"github.com/hanzhuoxian/flora/pkg/requestid"
+	"github.com/hanzhuoxian/flora/pkg/rest/metrics"
+)
+
+// Watch sets the watch parameter, sends the request and returns a watcher
+// decoding the stream of events of the response. The stream is not subject
+// to the timeout of the client, it ends when ctx is done, the server closes
+// it or the watcher is stopped.
+func (r *Request) Watch(ctx context.Context) (*StreamWatcher, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	r.Param("watch", "true")
+
+	if err := r.tryThrottle(ctx); err != nil {
+		return nil, err
+	}
+
+	if _, ok := requestid.FromContext(ctx); !ok {
+		ctx = requestid.WithRequestID(ctx, requestid.New())
+	}
+
+	req, err := r.newHTTPRequest(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := http.DefaultClient
+	if r.c.Client != nil {
+		streamClient := *r.c.Client
+		streamClient.Timeout = 0
+		client = &streamClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		metrics.RequestResult.Increment(ctx, "<error>", r.verb, req.URL.Host)
+
+		return nil, err
+	}
+
+	metrics.RequestResult.Increment(ctx, strconv.Itoa(resp.StatusCode), r.verb, req.URL.Host)
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		statusErr := apierrors.FromResponse(resp.StatusCode, body)
+		statusErr.RequestID = resp.Header.Get(requestid.Header)
+
+		return nil, statusErr
+	}
+
+	return &StreamWatcher{body: resp.Body, decoder: json.NewDecoder(resp.Body)}, nil
+}
+
+// StreamWatcher decodes the metav1.WatchEvent objects of a watch response.
+type StreamWatcher struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+}
+
+// Next blocks until the next event is received. It returns io.EOF once the
+// server ends the stream, and the error of an ERROR event as an API error.
+func (w *StreamWatcher) Next() (metav1.WatchEvent, error) {
+	var event metav1.WatchEvent
+	if err := w.decoder.Decode(&event); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+
+		return event, err
+	}
+
+	if event.Type == metav1.Error {
+		return event, apierrors.FromResponse(http.StatusInternalServerError, event.Object)
+	}
+
+	return event, nil
+}
+
+// Stop closes the stream.
+func (w *StreamWatcher) Stop() error {
+	return w.body.Close()
+}
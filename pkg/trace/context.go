@@ -0,0 +1,50 @@
+package trace
+
+import "context"
+
+type key int
+
+const (
+	spanKey key = iota
+	remoteSpanContextKey
+)
+
+// ContextWithSpan returns a copy of parent in which span is the current span.
+func ContextWithSpan(parent context.Context, span *Span) context.Context {
+	return context.WithValue(parent, spanKey, span)
+}
+
+// SpanFromContext returns the current span of ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+
+	span, _ := ctx.Value(spanKey).(*Span)
+
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of parent carrying a span
+// context received from another process, the parent of the next span.
+func ContextWithRemoteSpanContext(parent context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+
+	return context.WithValue(parent, remoteSpanContextKey, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span of
+// ctx, or the remote span context if no span was started yet.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+
+	if ctx == nil {
+		return SpanContext{}
+	}
+
+	sc, _ := ctx.Value(remoteSpanContextKey).(SpanContext)
+
+	return sc
+}
@@ -0,0 +1,224 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NewFileExporter returns an Exporter appending the spans to the file at
+// path, one OTLP JSON document per batch and line.
+func NewFileExporter(path, serviceName string) (Exporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileExporter{file: f, serviceName: serviceName}, nil
+}
+
+type fileExporter struct {
+	lock        sync.Mutex
+	file        *os.File
+	serviceName string
+}
+
+func (e *fileExporter) ExportSpans(_ context.Context, spans []*SpanData) error {
+	data, err := json.Marshal(toOTLP(e.serviceName, spans))
+	if err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	_, err = e.file.Write(append(data, '\n'))
+
+	return err
+}
+
+func (e *fileExporter) Shutdown(context.Context) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.file.Close()
+}
+
+// NewOTLPExporter returns an Exporter sending the spans to an OpenTelemetry
+// collector using OTLP/HTTP with the JSON encoding. endpoint is the base URL
+// of the collector, e.g. http://localhost:4318; spans are posted to
+// <endpoint>/v1/traces.
+func NewOTLPExporter(endpoint, serviceName string, client *http.Client) Exporter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &otlpExporter{
+		url:         strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      client,
+	}
+}
+
+type otlpExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []*SpanData) error {
+	data, err := json.Marshal(toOTLP(e.serviceName, spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(context.Context) error { return nil }
+
+// The OTLP JSON encoding, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              SpanKind       `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// otlpStatusError is the OTLP status code of failed spans.
+const otlpStatusError = 2
+
+func toOTLP(serviceName string, spans []*SpanData) *otlpTraces {
+	out := make([]otlpSpan, 0, len(spans))
+
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        toOTLPAttributes(s.Attributes),
+		}
+
+		if s.ParentSpanID.IsValid() {
+			span.ParentSpanID = s.ParentSpanID.String()
+		}
+
+		if len(s.Error) != 0 {
+			span.Status = &otlpStatus{Code: otlpStatusError, Message: s.Error}
+		}
+
+		out = append(out, span)
+	}
+
+	return &otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: toOTLPAttributes([]Attribute{String("service.name", serviceName)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/hanzhuoxian/flora/pkg/trace"}, Spans: out}},
+	}}}
+}
+
+func toOTLPAttributes(attrs []Attribute) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	out := make([]otlpKeyValue, 0, len(attrs))
+
+	for _, a := range attrs {
+		var v otlpValue
+
+		switch t := a.Value.(type) {
+		case string:
+			v.StringValue = &t
+		case bool:
+			v.BoolValue = &t
+		case int64:
+			s := strconv.FormatInt(t, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &t
+		default:
+			s := fmt.Sprint(t)
+			v.StringValue = &s
+		}
+
+		out = append(out, otlpKeyValue{Key: a.Key, Value: v})
+	}
+
+	return out
+}
@@ -0,0 +1,94 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// HeaderTraceparent is the W3C trace context header.
+const HeaderTraceparent = "traceparent"
+
+const (
+	traceparentVersion = "00"
+	flagSampled        = 0x01
+)
+
+var errInvalidTraceparent = errors.New("invalid traceparent")
+
+// FormatTraceparent returns the traceparent header value of sc.
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return traceparentVersion + "-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a traceparent header value.
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	// Version 00 has exactly four fields, later versions may append more.
+	if parts[0] == traceparentVersion && len(parts) != 4 {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	var sc SpanContext
+
+	if err := decodeHex(parts[1], sc.TraceID[:]); err != nil || !sc.TraceID.IsValid() {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	if err := decodeHex(parts[2], sc.SpanID[:]); err != nil || !sc.SpanID.IsValid() {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	var flags [1]byte
+	if err := decodeHex(parts[3], flags[:]); err != nil {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	sc.Sampled = flags[0]&flagSampled != 0
+
+	return sc, nil
+}
+
+func decodeHex(s string, dst []byte) error {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return errInvalidTraceparent
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+
+	return err
+}
+
+// Inject sets the traceparent header of the current span of ctx, if any.
+func Inject(ctx context.Context, header http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		header.Set(HeaderTraceparent, FormatTraceparent(sc))
+	}
+}
+
+// Extract returns a copy of ctx carrying the span context of the traceparent
+// header. Invalid headers are ignored.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	value := header.Get(HeaderTraceparent)
+	if len(value) == 0 {
+		return ctx
+	}
+
+	sc, err := ParseTraceparent(value)
+	if err != nil {
+		return ctx
+	}
+
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
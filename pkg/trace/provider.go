@@ -0,0 +1,241 @@
+package trace
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exporter sends finished spans to a backend.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []*SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// ProviderConfig configures a Provider.
+type ProviderConfig struct {
+	// ServiceName identifies the process in the exported spans.
+	ServiceName string
+	// SamplingRatio is the fraction of new traces that are recorded, between
+	// 0 and 1. Spans with a parent follow the sampling decision of the parent.
+	SamplingRatio float64
+	// QueueSize is the number of finished spans buffered before export.
+	// Spans are dropped when it is full.
+	QueueSize int
+	// MaxBatchSize is the maximum number of spans exported at once.
+	MaxBatchSize int
+	// BatchTimeout is the maximum time a span waits for its batch to fill.
+	BatchTimeout time.Duration
+}
+
+// Provider creates recording spans and exports them in batches.
+type Provider struct {
+	config   ProviderConfig
+	exporter Exporter
+
+	lock     sync.RWMutex
+	closed   bool
+	queue    chan *SpanData
+	finished chan struct{}
+}
+
+// NewProvider returns a Provider exporting to exporter. Zero values of
+// config are replaced by defaults.
+func NewProvider(exporter Exporter, config ProviderConfig) *Provider {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 2048
+	}
+
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 512
+	}
+
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = 5 * time.Second
+	}
+
+	p := &Provider{
+		config:   config,
+		exporter: exporter,
+		queue:    make(chan *SpanData, config.QueueSize),
+		finished: make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// Shutdown exports the pending spans and shuts the exporter down.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	p.lock.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.lock.Unlock()
+
+	select {
+	case <-p.finished:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return p.exporter.Shutdown(ctx)
+}
+
+func (p *Provider) enqueue(span *SpanData) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return
+	}
+
+	select {
+	case p.queue <- span:
+	default:
+		handleError(errors.New("span queue is full, dropping span " + span.Name))
+	}
+}
+
+func (p *Provider) run() {
+	defer close(p.finished)
+
+	for {
+		span, ok := <-p.queue
+		if !ok {
+			return
+		}
+
+		batch := []*SpanData{span}
+		open := true
+		timer := time.NewTimer(p.config.BatchTimeout)
+
+	collect:
+		for len(batch) < p.config.MaxBatchSize {
+			select {
+			case span, ok := <-p.queue:
+				if !ok {
+					open = false
+					break collect
+				}
+
+				batch = append(batch, span)
+			case <-timer.C:
+				break collect
+			}
+		}
+
+		timer.Stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := p.exporter.ExportSpans(ctx, batch); err != nil {
+			handleError(fmt.Errorf("export %d spans: %w", len(batch), err))
+		}
+		cancel()
+
+		if !open {
+			return
+		}
+	}
+}
+
+// shouldSample decides whether a new trace is recorded. The decision is
+// derived from the trace ID so that it is stable for a given trace.
+func (p *Provider) shouldSample(id TraceID) bool {
+	switch {
+	case p.config.SamplingRatio >= 1:
+		return true
+	case p.config.SamplingRatio <= 0:
+		return false
+	}
+
+	bound := uint64(p.config.SamplingRatio * (1 << 63))
+
+	return binary.BigEndian.Uint64(id[8:])>>1 < bound
+}
+
+var (
+	globalProvider atomic.Pointer[Provider]
+
+	errorHandlerLock sync.RWMutex
+	errorHandler     = func(err error) { fmt.Fprintf(os.Stderr, "trace: %v\n", err) }
+)
+
+// SetProvider sets the global Provider used by Start. A nil provider
+// disables recording, span contexts are still propagated.
+func SetProvider(p *Provider) {
+	globalProvider.Store(p)
+}
+
+// SetErrorHandler sets the function called with the errors of the
+// exporters. By default errors are written to standard error.
+func SetErrorHandler(handler func(err error)) {
+	errorHandlerLock.Lock()
+	defer errorHandlerLock.Unlock()
+
+	errorHandler = handler
+}
+
+func handleError(err error) {
+	errorHandlerLock.RLock()
+	defer errorHandlerLock.RUnlock()
+
+	errorHandler(err)
+}
+
+// SpanOption configures a span created by Start.
+type SpanOption func(*SpanData)
+
+// WithSpanKind sets the kind of the span. The default is SpanKindInternal.
+func WithSpanKind(kind SpanKind) SpanOption {
+	return func(d *SpanData) { d.Kind = kind }
+}
+
+// WithAttributes adds attributes to the span.
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(d *SpanData) { d.Attributes = append(d.Attributes, attrs...) }
+}
+
+// Start creates a span as a child of the current span of ctx and returns a
+// context in which it is the current span. Without a global Provider the
+// span does not record, but children of a valid parent still carry its
+// trace ID so that it is propagated.
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	provider := globalProvider.Load()
+
+	span := &Span{
+		provider: provider,
+		data: SpanData{
+			Name:      name,
+			Kind:      SpanKindInternal,
+			StartTime: time.Now(),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&span.data)
+	}
+
+	switch {
+	case parent.IsValid():
+		span.data.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+		span.data.ParentSpanID = parent.SpanID
+	case provider != nil:
+		id := newTraceID()
+		span.data.SpanContext = SpanContext{TraceID: id, SpanID: newSpanID(), Sampled: provider.shouldSample(id)}
+	}
+
+	if !span.IsRecording() {
+		span.data.Attributes = nil
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
@@ -0,0 +1,179 @@
+// Package trace implements a small OpenTelemetry style tracer: spans are
+// started from a context, propagated between processes with the W3C
+// traceparent header and exported in batches to a file or an OTLP/HTTP
+// collector.
+//
+//	ctx, span := trace.Start(ctx, "storage.Get", trace.WithAttributes(trace.String("key", key)))
+//	defer span.End()
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid returns true if the ID is not all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// String returns the hex encoding of the ID.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid returns true if the ID is not all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// String returns the hex encoding of the ID.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+
+	return id
+}
+
+// SpanContext is the part of a span propagated to child spans and to other
+// processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	// Remote is true if the span context was received from another process.
+	Remote bool
+}
+
+// IsValid returns true if both IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind describes the relationship between a span and its parent.
+type SpanKind int
+
+// Span kinds, numbered as in OTLP.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// Attribute is a key/value pair describing a span. Value is a string, bool,
+// int64 or float64.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Int64 returns an integer attribute.
+func Int64(key string, value int64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Float64 returns a floating point attribute.
+func Float64(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is the immutable state of a finished span handed to exporters.
+type SpanData struct {
+	Name         string
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	Kind         SpanKind
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   []Attribute
+	// Error is set if the operation failed.
+	Error string
+}
+
+// Span is an operation in a trace. Spans that are not recording, because no
+// Provider is set or they were not sampled, only carry their SpanContext.
+type Span struct {
+	provider *Provider
+
+	lock  sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context of the span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.data.SpanContext
+}
+
+// IsRecording returns true if the span will be exported when it ends.
+func (s *Span) IsRecording() bool {
+	return s != nil && s.provider != nil && s.data.SpanContext.Sampled
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil || !s.IsRecording() {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data.Error = err.Error()
+}
+
+// End finishes the span. Only the first call has an effect.
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.lock.Unlock()
+
+	s.provider.enqueue(&data)
+}
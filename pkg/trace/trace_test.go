@@ -0,0 +1,137 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+		sampled bool
+	}{
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sampled: true},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", sampled: true},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+		{value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", wantErr: true},
+		{value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{value: "garbage", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		sc, err := ParseTraceparent(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTraceparent(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if sc.Sampled != tt.sampled {
+			t.Errorf("ParseTraceparent(%q) sampled = %v, want %v", tt.value, sc.Sampled, tt.sampled)
+		}
+
+		if tt.value[:2] == traceparentVersion && FormatTraceparent(sc) != tt.value {
+			t.Errorf("FormatTraceparent() = %q, want %q", FormatTraceparent(sc), tt.value)
+		}
+	}
+}
+
+func TestStartWithoutProvider(t *testing.T) {
+	SetProvider(nil)
+
+	_, span := Start(context.Background(), "root")
+	if span.SpanContext().IsValid() || span.IsRecording() {
+		t.Errorf("span without parent and provider should be invalid, got %+v", span.SpanContext())
+	}
+
+	header := http.Header{}
+	header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, child := Start(Extract(context.Background(), header), "child")
+	sc := child.SpanContext()
+
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || !sc.Sampled {
+		t.Errorf("child span context = %+v, want the remote trace", sc)
+	}
+
+	if sc.SpanID.String() == "00f067aa0ba902b7" {
+		t.Errorf("child span reused the parent span ID")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan otlpTraces, 10)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+
+		data, _ := io.ReadAll(r.Body)
+
+		var traces otlpTraces
+		if err := json.Unmarshal(data, &traces); err != nil {
+			t.Errorf("invalid OTLP payload: %v", err)
+		}
+
+		received <- traces
+	}))
+	defer collector.Close()
+
+	p := NewProvider(NewOTLPExporter(collector.URL, "test", nil), ProviderConfig{
+		ServiceName:   "test",
+		SamplingRatio: 1,
+		BatchTimeout:  time.Millisecond,
+	})
+	SetProvider(p)
+
+	defer SetProvider(nil)
+
+	ctx, parent := Start(context.Background(), "parent", WithSpanKind(SpanKindServer))
+	_, child := Start(ctx, "child", WithAttributes(String("key", "value"), Int("count", 3)))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	parent.End()
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	spans := map[string]otlpSpan{}
+
+	for len(spans) < 2 {
+		select {
+		case traces := <-received:
+			for _, s := range traces.ResourceSpans[0].ScopeSpans[0].Spans {
+				spans[s.Name] = s
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("collector received %d spans, want 2", len(spans))
+		}
+	}
+
+	if spans["child"].TraceID != spans["parent"].TraceID || spans["child"].ParentSpanID != spans["parent"].SpanID {
+		t.Errorf("child %+v is not a child of parent %+v", spans["child"], spans["parent"])
+	}
+
+	if spans["child"].Status == nil || spans["child"].Status.Message != "boom" {
+		t.Errorf("child status = %+v, want error boom", spans["child"].Status)
+	}
+
+	if spans["parent"].Kind != SpanKindServer || len(spans["child"].Attributes) != 2 {
+		t.Errorf("unexpected spans %+v", spans)
+	}
+}
@@ -0,0 +1,5 @@
+// Package workqueue provides the queues used by controllers to process keys.
+// Items are de-duplicated while they wait, an item is never processed by more
+// than one worker at a time, failed items can be retried with a per-item
+// backoff and the queue can be drained gracefully on shutdown.
+package workqueue
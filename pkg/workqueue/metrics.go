@@ -0,0 +1,247 @@
+package workqueue
+
+import (
+	"sync"
+	"time"
+)
+
+// GaugeMetric represents a single numerical value that can arbitrarily go up
+// and down.
+type GaugeMetric interface {
+	Inc()
+	Dec()
+}
+
+// SettableGaugeMetric represents a single numerical value that can arbitrarily go up
+// and down. (Separate from GaugeMetric to preserve backwards compatibility.)
+type SettableGaugeMetric interface {
+	Set(float64)
+}
+
+// CounterMetric represents a single numerical value that only ever
+// goes up.
+type CounterMetric interface {
+	Inc()
+}
+
+// HistogramMetric counts individual observations.
+type HistogramMetric interface {
+	Observe(float64)
+}
+
+// MetricsProvider generates various metrics used by the queue.
+type MetricsProvider interface {
+	NewDepthMetric(name string) GaugeMetric
+	NewAddsMetric(name string) CounterMetric
+	NewLatencyMetric(name string) HistogramMetric
+	NewWorkDurationMetric(name string) HistogramMetric
+	NewUnfinishedWorkSecondsMetric(name string) SettableGaugeMetric
+	NewLongestRunningProcessorSecondsMetric(name string) SettableGaugeMetric
+	NewRetriesMetric(name string) CounterMetric
+	NewDroppedMetric(name string) CounterMetric
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Dec()            {}
+func (noopMetric) Set(float64)     {}
+func (noopMetric) Observe(float64) {}
+
+type noopMetricsProvider struct{}
+
+func (noopMetricsProvider) NewDepthMetric(name string) GaugeMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewAddsMetric(name string) CounterMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewLatencyMetric(name string) HistogramMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewWorkDurationMetric(name string) HistogramMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewRetriesMetric(name string) CounterMetric {
+	return noopMetric{}
+}
+
+func (noopMetricsProvider) NewDroppedMetric(name string) CounterMetric {
+	return noopMetric{}
+}
+
+// queueMetrics expects the caller to lock before setting any metrics.
+type queueMetrics interface {
+	add(item interface{})
+	get(item interface{})
+	done(item interface{})
+	updateUnfinishedWork()
+}
+
+// retryMetrics is reported by the delaying queue.
+type retryMetrics interface {
+	retry()
+}
+
+// defaultQueueMetrics expects the caller to lock before setting any metrics.
+type defaultQueueMetrics struct {
+	// current depth of a workqueue
+	depth GaugeMetric
+	// total number of adds handled by a workqueue
+	adds CounterMetric
+	// how long an item stays in a workqueue
+	latency HistogramMetric
+	// how long processing an item from a workqueue takes
+	workDuration         HistogramMetric
+	addTimes             map[interface{}]time.Time
+	processingStartTimes map[interface{}]time.Time
+
+	// how long have current threads been working?
+	unfinishedWorkSeconds   SettableGaugeMetric
+	longestRunningProcessor SettableGaugeMetric
+}
+
+func (m *defaultQueueMetrics) add(item interface{}) {
+	m.adds.Inc()
+	m.depth.Inc()
+
+	if _, exists := m.addTimes[item]; !exists {
+		m.addTimes[item] = time.Now()
+	}
+}
+
+func (m *defaultQueueMetrics) get(item interface{}) {
+	m.depth.Dec()
+	m.processingStartTimes[item] = time.Now()
+
+	if startTime, exists := m.addTimes[item]; exists {
+		m.latency.Observe(time.Since(startTime).Seconds())
+		delete(m.addTimes, item)
+	}
+}
+
+func (m *defaultQueueMetrics) done(item interface{}) {
+	if startTime, exists := m.processingStartTimes[item]; exists {
+		m.workDuration.Observe(time.Since(startTime).Seconds())
+		delete(m.processingStartTimes, item)
+	}
+}
+
+func (m *defaultQueueMetrics) updateUnfinishedWork() {
+	// Note that a summary metric would be better for this, but prometheus
+	// doesn't seem to have non-hacky ways to reset the summary metrics.
+	var total float64
+
+	var oldest float64
+
+	for _, t := range m.processingStartTimes {
+		age := time.Since(t).Seconds()
+		total += age
+
+		if age > oldest {
+			oldest = age
+		}
+	}
+
+	m.unfinishedWorkSeconds.Set(total)
+	m.longestRunningProcessor.Set(oldest)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) add(item interface{})  {}
+func (noMetrics) get(item interface{})  {}
+func (noMetrics) done(item interface{}) {}
+func (noMetrics) updateUnfinishedWork() {}
+
+type defaultRetryMetrics struct {
+	retries CounterMetric
+}
+
+func (m *defaultRetryMetrics) retry() {
+	if m == nil {
+		return
+	}
+
+	m.retries.Inc()
+}
+
+type queueMetricsFactory struct {
+	metricsProvider MetricsProvider
+
+	onlyOnce sync.Once
+}
+
+func (f *queueMetricsFactory) setProvider(mp MetricsProvider) {
+	f.onlyOnce.Do(func() {
+		f.metricsProvider = mp
+	})
+}
+
+func (f *queueMetricsFactory) provider(mp MetricsProvider) MetricsProvider {
+	if mp != nil {
+		return mp
+	}
+
+	return f.metricsProvider
+}
+
+func (f *queueMetricsFactory) newQueueMetrics(name string, mp MetricsProvider) queueMetrics {
+	mp = f.provider(mp)
+	if len(name) == 0 || mp == (noopMetricsProvider{}) {
+		return noMetrics{}
+	}
+
+	return &defaultQueueMetrics{
+		depth:                   mp.NewDepthMetric(name),
+		adds:                    mp.NewAddsMetric(name),
+		latency:                 mp.NewLatencyMetric(name),
+		workDuration:            mp.NewWorkDurationMetric(name),
+		unfinishedWorkSeconds:   mp.NewUnfinishedWorkSecondsMetric(name),
+		longestRunningProcessor: mp.NewLongestRunningProcessorSecondsMetric(name),
+		addTimes:                map[interface{}]time.Time{},
+		processingStartTimes:    map[interface{}]time.Time{},
+	}
+}
+
+func (f *queueMetricsFactory) newRetryMetrics(name string, mp MetricsProvider) retryMetrics {
+	mp = f.provider(mp)
+	if len(name) == 0 || mp == (noopMetricsProvider{}) {
+		return (*defaultRetryMetrics)(nil)
+	}
+
+	return &defaultRetryMetrics{
+		retries: mp.NewRetriesMetric(name),
+	}
+}
+
+func (f *queueMetricsFactory) newDroppedMetric(name string, mp MetricsProvider) CounterMetric {
+	mp = f.provider(mp)
+	if len(name) == 0 {
+		return noopMetric{}
+	}
+
+	return mp.NewDroppedMetric(name)
+}
+
+var globalMetricsFactory = queueMetricsFactory{
+	metricsProvider: noopMetricsProvider{},
+}
+
+// SetProvider sets the metrics provider for all subsequently created work
+// queues. Only the first call has an effect.
+func SetProvider(metricsProvider MetricsProvider) {
+	globalMetricsFactory.setProvider(metricsProvider)
+}
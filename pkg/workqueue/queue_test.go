@@ -0,0 +1,222 @@
+package workqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeduplication(t *testing.T) {
+	q := New()
+
+	q.Add("foo")
+	q.Add("foo")
+	q.Add("bar")
+
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", q.Len())
+	}
+
+	item, _ := q.Get()
+	if item != "foo" {
+		t.Fatalf("Get() = %v, want foo", item)
+	}
+
+	// Adding an item while it is processed must not hand it to a second worker.
+	q.Add("foo")
+	if q.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", q.Len())
+	}
+
+	q.Done("foo")
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d after Done, want 2", q.Len())
+	}
+}
+
+func TestShutDownWithDrain(t *testing.T) {
+	q := New()
+
+	q.Add("foo")
+	q.Add("bar")
+
+	item, _ := q.Get()
+
+	done := make(chan struct{})
+	go func() {
+		q.ShutDownWithDrain()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("ShutDownWithDrain returned while an item was still processing")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.Add("baz")
+	q.Done(item)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ShutDownWithDrain did not return after the in-flight item was done")
+	}
+
+	if !q.ShuttingDown() {
+		t.Error("ShuttingDown() = false, want true")
+	}
+
+	// Items queued before the shutdown are still handed out.
+	if item, shutdown := q.Get(); shutdown || item != "bar" {
+		t.Errorf("Get() = %v, %v, want bar, false", item, shutdown)
+	}
+
+	if _, shutdown := q.Get(); !shutdown {
+		t.Error("Get() on a drained queue should report shutdown")
+	}
+}
+
+func TestConcurrentWorkers(t *testing.T) {
+	q := New()
+
+	const items = 50
+	for i := 0; i < items; i++ {
+		q.Add(i)
+	}
+
+	var (
+		mu        sync.Mutex
+		processed = map[interface{}]int{}
+		wg        sync.WaitGroup
+	)
+
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				item, shutdown := q.Get()
+				if shutdown {
+					return
+				}
+
+				mu.Lock()
+				processed[item]++
+				mu.Unlock()
+
+				q.Done(item)
+			}
+		}()
+	}
+
+	for q.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	q.ShutDownWithDrain()
+	wg.Wait()
+
+	if len(processed) != items {
+		t.Fatalf("processed %d distinct items, want %d", len(processed), items)
+	}
+
+	for item, n := range processed {
+		if n != 1 {
+			t.Errorf("item %v processed %d times, want 1", item, n)
+		}
+	}
+}
+
+func TestAddAfter(t *testing.T) {
+	q := NewDelayingQueue()
+	defer q.ShutDown()
+
+	q.AddAfter("foo", 50*time.Millisecond)
+	q.AddAfter("bar", 0)
+
+	if q.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", q.Len())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for q.Len() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("delayed item was never added")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDelayingShutDownAbortsDrain(t *testing.T) {
+	q := NewDelayingQueue()
+
+	q.Add("foo")
+
+	// The item is never marked as done.
+	if _, shutdown := q.Get(); shutdown {
+		t.Fatal("Get() reported shutdown")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.ShutDownWithDrain()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("ShutDownWithDrain returned while an item was still processing")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.ShutDown()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ShutDown did not abort ShutDownWithDrain")
+	}
+}
+
+func TestRateLimitingQueue(t *testing.T) {
+	limiter := NewItemExponentialFailureRateLimiter(time.Millisecond, time.Second)
+	q := NewRateLimitingQueueWithConfig(limiter, RateLimitingQueueConfig{MaxRetries: 2})
+	defer q.ShutDown()
+
+	if !q.AddRateLimited("foo") || !q.AddRateLimited("foo") {
+		t.Fatal("AddRateLimited() = false before MaxRetries was reached")
+	}
+
+	if got := q.NumRequeues("foo"); got != 2 {
+		t.Fatalf("NumRequeues() = %d, want 2", got)
+	}
+
+	if q.AddRateLimited("foo") {
+		t.Fatal("AddRateLimited() = true after MaxRetries was reached")
+	}
+
+	if got := q.NumRequeues("foo"); got != 0 {
+		t.Errorf("NumRequeues() = %d after drop, want 0", got)
+	}
+}
+
+func TestItemExponentialFailureRateLimiter(t *testing.T) {
+	limiter := NewItemExponentialFailureRateLimiter(time.Millisecond, 4*time.Millisecond)
+
+	want := []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond}
+	for i, w := range want {
+		if got := limiter.When("one"); got != w {
+			t.Errorf("attempt %d: When() = %v, want %v", i, got, w)
+		}
+	}
+
+	limiter.Forget("one")
+
+	if got := limiter.When("one"); got != time.Millisecond {
+		t.Errorf("When() after Forget = %v, want %v", got, time.Millisecond)
+	}
+}
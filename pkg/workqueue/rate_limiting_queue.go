@@ -0,0 +1,98 @@
+package workqueue
+
+// RateLimitingInterface is an interface that rate limits items being added to the queue.
+type RateLimitingInterface interface {
+	DelayingInterface
+
+	// AddRateLimited adds an item to the workqueue after the rate limiter says it's ok.
+	// It returns false when the item has exhausted its retries and was dropped instead.
+	AddRateLimited(item interface{}) bool
+
+	// Forget indicates that an item is finished being retried.  Doesn't matter whether it's for perm failing
+	// or for success, we'll stop the rate limiter from tracking it.  This only clears the `rateLimiter`, you
+	// still have to call `Done` on the queue.
+	Forget(item interface{})
+
+	// NumRequeues returns back how many times the item was requeued
+	NumRequeues(item interface{}) int
+}
+
+// RateLimitingQueueConfig specifies optional configurations to customize a RateLimitingInterface.
+type RateLimitingQueueConfig struct {
+	// Name for the queue. If unnamed, the metrics will not be registered.
+	Name string
+
+	// MetricsProvider optionally allows specifying a metrics provider to use for the queue.
+	// If it's nil, the globally registered provider is used.
+	MetricsProvider MetricsProvider
+
+	// MaxRetries is the number of times an item may be requeued through
+	// AddRateLimited before it is dropped. Zero means retry forever.
+	MaxRetries int
+
+	// DelayingQueue optionally allows injecting custom delaying queue DelayingInterface instead of the default one.
+	DelayingQueue DelayingInterface
+}
+
+// NewRateLimitingQueue constructs a new workqueue with rateLimited queuing ability
+// Remember to call Forget!  If you don't, you may end up tracking failures forever.
+func NewRateLimitingQueue(rateLimiter RateLimiter) RateLimitingInterface {
+	return NewRateLimitingQueueWithConfig(rateLimiter, RateLimitingQueueConfig{})
+}
+
+// NewNamedRateLimitingQueue constructs a new named workqueue with rateLimited queuing ability.
+func NewNamedRateLimitingQueue(rateLimiter RateLimiter, name string) RateLimitingInterface {
+	return NewRateLimitingQueueWithConfig(rateLimiter, RateLimitingQueueConfig{Name: name})
+}
+
+// NewRateLimitingQueueWithConfig constructs a new workqueue with rateLimited queuing ability
+// with options to customize different properties.
+// Remember to call Forget!  If you don't, you may end up tracking failures forever.
+func NewRateLimitingQueueWithConfig(rateLimiter RateLimiter, config RateLimitingQueueConfig) RateLimitingInterface {
+	if config.DelayingQueue == nil {
+		config.DelayingQueue = NewDelayingQueueWithConfig(DelayingQueueConfig{
+			Name:            config.Name,
+			MetricsProvider: config.MetricsProvider,
+		})
+	}
+
+	return &rateLimitingType{
+		DelayingInterface: config.DelayingQueue,
+		rateLimiter:       rateLimiter,
+		maxRetries:        config.MaxRetries,
+		dropped:           globalMetricsFactory.newDroppedMetric(config.Name, config.MetricsProvider),
+	}
+}
+
+// rateLimitingType wraps an Interface and provides rateLimited re-enquing.
+type rateLimitingType struct {
+	DelayingInterface
+
+	rateLimiter RateLimiter
+	maxRetries  int
+	dropped     CounterMetric
+}
+
+// AddRateLimited AddAfter's the item based on the time when the rate limiter says it's ok.
+func (q *rateLimitingType) AddRateLimited(item interface{}) bool {
+	if q.maxRetries > 0 && q.rateLimiter.NumRequeues(item) >= q.maxRetries {
+		q.rateLimiter.Forget(item)
+		q.dropped.Inc()
+
+		return false
+	}
+
+	q.DelayingInterface.AddAfter(item, q.rateLimiter.When(item))
+
+	return true
+}
+
+// NumRequeues returns how many times item was requeued through AddRateLimited.
+func (q *rateLimitingType) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// Forget stops tracking the failures of item.
+func (q *rateLimitingType) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}